@@ -3,13 +3,18 @@ package backend
 import (
 	"context"
 	"encoding/hex"
+	"fmt"
 	"sync"
+	"time"
 
+	vault "github.com/bloxapp/eth2-key-manager"
+	"github.com/bloxapp/eth2-key-manager/core"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
+	"github.com/bloxapp/key-vault/backend/store"
 	"github.com/bloxapp/key-vault/utils/encoder"
 )
 
@@ -29,19 +34,33 @@ func newBackend(version string, logger *logrus.Logger) *backend {
 	b := &backend{
 		logger:      logger,
 		Version:     version,
-		signMapLock: &sync.Mutex{},
-		signLock:    make(map[string]*sync.Mutex),
+		signLocks:   newLockTable(),
 		encoder:     encoder.New(),
+		jsonEncoder: encoder.NewJSON(),
+		walletCache: &walletCache{},
+		now:         time.Now,
 	}
 	b.Backend = &framework.Backend{
 		Help: "",
 		Paths: framework.PathAppend(
 			versionPaths(b),
+			locksPaths(b),
 			storagePaths(b),
+			storageKeystoresPaths(b),
 			storageSlashingDataPaths(b),
+			storageSlashingInterchangePaths(b),
+			storageSlashingWatermarkPaths(b),
 			accountsPaths(b),
+			accountsDeletePaths(b),
+			accountsQuarantinePaths(b),
+			accountsStatusPaths(b),
+			depositDataPaths(b),
 			signsPaths(b),
+			signsBatchPaths(b),
 			signsVoluntaryExitPath(b),
+			signsBLSToExecutionChangePaths(b),
+			exitsPaths(b),
+			web3SignerPaths(b),
 			configPaths(b),
 		),
 		PathsSpecial: &logical.Paths{
@@ -49,8 +68,10 @@ func newBackend(version string, logger *logrus.Logger) *backend {
 				"wallet/",
 			},
 		},
-		Secrets:     []*framework.Secret{},
-		BackendType: logical.TypeLogical,
+		Secrets:        []*framework.Secret{},
+		BackendType:    logical.TypeLogical,
+		Invalidate:     b.invalidate,
+		InitializeFunc: b.initialize,
 	}
 	return b
 }
@@ -60,9 +81,32 @@ type backend struct {
 	*framework.Backend
 	logger      *logrus.Logger
 	Version     string
-	signMapLock *sync.Mutex
-	signLock    map[string]*sync.Mutex
+	signLocks   *lockTable
 	encoder     encoder.IEncoder
+	jsonEncoder encoder.IEncoder
+	walletCache *walletCache
+
+	// storageLock serializes storage updates.
+	storageLock sync.Mutex
+
+	// now returns the current wall-clock time.
+	now func() time.Time
+}
+
+// initialize completes a storage update interrupted by a restart of the plugin.
+func (b *backend) initialize(ctx context.Context, req *logical.InitializationRequest) error {
+	b.storageLock.Lock()
+	defer b.storageLock.Unlock()
+
+	recovered, err := store.RecoverStorageUpdate(ctx, req.Storage)
+	if err != nil {
+		return errors.Wrap(err, "failed to recover storage update")
+	}
+	if recovered {
+		b.walletCache.reset()
+		b.logger.Info("Recovered interrupted storage update")
+	}
+	return nil
 }
 
 // pathExistenceCheck checks if the given path exists
@@ -80,3 +124,53 @@ func (b *backend) pathExistenceCheck(ctx context.Context, req *logical.Request,
 
 	return out != nil, nil
 }
+
+// openWallet brings up the KeyVault of the given storage and returns its wallet.
+// The wallet is served from the wallet cache while the wallet generation is unchanged, it must not be modified.
+func (b *backend) openWallet(ctx context.Context, s logical.Storage, config *Config) (*store.HashicorpVaultStore, core.Wallet, error) {
+	storage := store.NewHashicorpVaultStore(ctx, s, config.Network)
+
+	generation, err := storage.WalletGeneration()
+	if err != nil {
+		return nil, nil, err
+	}
+	if wallet := b.walletCache.get(config.Network, generation); wallet != nil {
+		return storage, wallet, nil
+	}
+
+	wallet, err := openStoredWallet(storage)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Wallets stored before the wallet generation was introduced are cached after their next update.
+	if generation == "" {
+		return storage, wallet, nil
+	}
+	cached := newCachedWallet(wallet)
+	b.walletCache.put(config.Network, generation, cached)
+	return storage, cached, nil
+}
+
+// openStoredWallet brings up the KeyVault of the given storage and returns its wallet, bypassing the wallet cache.
+// Unlike the wallet returned by openWallet, it may be modified.
+func openStoredWallet(storage *store.HashicorpVaultStore) (core.Wallet, error) {
+	options := vault.KeyVaultOptions{}
+	options.SetStorage(storage)
+
+	kv, err := vault.OpenKeyVault(&options)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open key vault")
+	}
+
+	wallet, err := kv.Wallet()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve wallet")
+	}
+	return wallet, nil
+}
+
+// pubKeyRegex returns a path pattern that matches a hex encoded BLS public key.
+func pubKeyRegex(name string) string {
+	return fmt.Sprintf("(?P<%s>(0x)?[0-9a-fA-F]{96})", name)
+}
@@ -0,0 +1,165 @@
+package backend
+
+import (
+	"bytes"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+
+	"github.com/bloxapp/key-vault/keymanager/models"
+)
+
+// Domain types as defined in the consensus specs.
+var (
+	DomainBeaconProposer              = phase0.DomainType{0x00, 0x00, 0x00, 0x00}
+	DomainBeaconAttester              = phase0.DomainType{0x01, 0x00, 0x00, 0x00}
+	DomainRandao                      = phase0.DomainType{0x02, 0x00, 0x00, 0x00}
+	DomainDeposit                     = phase0.DomainType{0x03, 0x00, 0x00, 0x00}
+	DomainVoluntaryExit               = phase0.DomainType{0x04, 0x00, 0x00, 0x00}
+	DomainSelectionProof              = phase0.DomainType{0x05, 0x00, 0x00, 0x00}
+	DomainAggregateAndProof           = phase0.DomainType{0x06, 0x00, 0x00, 0x00}
+	DomainSyncCommittee               = phase0.DomainType{0x07, 0x00, 0x00, 0x00}
+	DomainSyncCommitteeSelectionProof = phase0.DomainType{0x08, 0x00, 0x00, 0x00}
+	DomainContributionAndProof        = phase0.DomainType{0x09, 0x00, 0x00, 0x00}
+	DomainBLSToExecutionChange        = phase0.DomainType{0x0a, 0x00, 0x00, 0x00}
+	DomainApplicationBuilder          = phase0.DomainType{0x00, 0x00, 0x00, 0x01}
+)
+
+// computeDomain returns the signature domain for the given domain type, fork version and genesis validators root.
+// https://github.com/ethereum/consensus-specs/blob/dev/specs/phase0/beacon-chain.md#compute_domain
+func computeDomain(domainType phase0.DomainType, forkVersion phase0.Version, genesisValidatorsRoot phase0.Root) (phase0.Domain, error) {
+	forkData := phase0.ForkData{
+		CurrentVersion:        forkVersion,
+		GenesisValidatorsRoot: genesisValidatorsRoot,
+	}
+	forkDataRoot, err := forkData.HashTreeRoot()
+	if err != nil {
+		return phase0.Domain{}, errors.Wrap(err, "failed to compute fork data root")
+	}
+
+	var domain phase0.Domain
+	copy(domain[:4], domainType[:])
+	copy(domain[4:], forkDataRoot[:28])
+	return domain, nil
+}
+
+// forkVersionAtEpoch returns the fork version of the given fork which is active at the given epoch.
+func forkVersionAtEpoch(fork *phase0.Fork, epoch phase0.Epoch) phase0.Version {
+	if epoch < fork.Epoch {
+		return fork.PreviousVersion
+	}
+	return fork.CurrentVersion
+}
+
+// ErrInvalidSignatureDomain is returned when the signature domain does not match the signed object or the fork schedule.
+var ErrInvalidSignatureDomain = errors.New("invalid signature domain")
+
+// validateSignatureDomain validates the domain type and fork version of the given request against the configured fork schedule.
+// It is a no-op when no fork schedule is configured: the validation is opt-in.
+func validateSignatureDomain(config *Config, signReq *models.SignRequest) error {
+	if len(config.ForkSchedule) == 0 {
+		return nil
+	}
+
+	domainType, epoch, hasEpoch, err := signRequestDomainType(config, signReq)
+	if err != nil {
+		return err
+	}
+
+	domain := signReq.GetSignatureDomain()
+	if !bytes.Equal(domain[:4], domainType[:]) {
+		return errors.Wrapf(ErrInvalidSignatureDomain, "domain type %#x does not match %T, expected %#x", domain[:4], signReq.GetObject(), domainType[:])
+	}
+
+	// Candidate fork versions and the genesis validators root the domain may be computed with.
+	genesisValidatorsRoot := config.GenesisValidatorsRoot
+	var versions []phase0.Version
+	switch signReq.GetObject().(type) {
+	case *models.SignRequestRegistration:
+		// Builder registrations are always signed with the genesis fork version and an empty genesis validators root.
+		versions = []phase0.Version{config.ForkSchedule[0].Version}
+		genesisValidatorsRoot = phase0.Root{}
+	case *models.SignRequestBLSToExecutionChange:
+		// BLS to execution changes are always signed with the genesis fork version.
+		versions = []phase0.Version{config.ForkSchedule[0].Version}
+	case *models.SignRequestVoluntaryExit:
+		// Since EIP-7044, voluntary exits are signed with the Capella fork version,
+		// so any fork version activated up to the exit epoch is valid.
+		for _, fork := range config.ForkSchedule {
+			if fork.Epoch <= epoch {
+				versions = append(versions, fork.Version)
+			}
+		}
+	default:
+		if !hasEpoch {
+			// The object does not carry its slot, any scheduled fork version is valid.
+			for _, fork := range config.ForkSchedule {
+				versions = append(versions, fork.Version)
+			}
+			break
+		}
+		if version, ok := config.ForkSchedule.VersionAt(epoch); ok {
+			versions = append(versions, version)
+		}
+	}
+
+	for _, version := range versions {
+		expected, err := computeDomain(domainType, version, genesisValidatorsRoot)
+		if err != nil {
+			return err
+		}
+		if expected == domain {
+			return nil
+		}
+	}
+	if hasEpoch {
+		return errors.Wrapf(ErrInvalidSignatureDomain, "fork version is not valid at epoch %d", epoch)
+	}
+	return errors.Wrap(ErrInvalidSignatureDomain, "fork version is not in the fork schedule")
+}
+
+// signRequestDomainType returns the domain type of the given request object and its epoch, if it has one.
+func signRequestDomainType(config *Config, signReq *models.SignRequest) (phase0.DomainType, phase0.Epoch, bool, error) {
+	switch t := signReq.GetObject().(type) {
+	case *models.SignRequestBlock:
+		slot, err := t.VersionedBeaconBlock.Slot()
+		if err != nil {
+			return phase0.DomainType{}, 0, false, errors.Wrap(err, "failed to get block slot")
+		}
+		return DomainBeaconProposer, config.epochAtSlot(slot), true, nil
+	case *models.SignRequestBlindedBlock:
+		slot, err := t.VersionedBlindedBeaconBlock.Slot()
+		if err != nil {
+			return phase0.DomainType{}, 0, false, errors.Wrap(err, "failed to get blinded block slot")
+		}
+		return DomainBeaconProposer, config.epochAtSlot(slot), true, nil
+	case *models.SignRequestBlockHeader:
+		return DomainBeaconProposer, config.epochAtSlot(t.BeaconBlockHeader.Slot), true, nil
+	case *models.SignRequestAttestationData:
+		return DomainBeaconAttester, t.AttestationData.Target.Epoch, true, nil
+	case *models.SignRequestSlot:
+		return DomainSelectionProof, config.epochAtSlot(t.Slot), true, nil
+	case *models.SignRequestEpoch:
+		return DomainRandao, t.Epoch, true, nil
+	case *models.SignRequestAggregateAttestationAndProof:
+		data, err := t.AttestationData()
+		if err != nil {
+			return phase0.DomainType{}, 0, false, err
+		}
+		return DomainAggregateAndProof, config.epochAtSlot(data.Slot), true, nil
+	case *models.SignRequestSyncCommitteeMessage:
+		return DomainSyncCommittee, 0, false, nil
+	case *models.SignRequestSyncAggregatorSelectionData:
+		return DomainSyncCommitteeSelectionProof, config.epochAtSlot(t.SyncAggregatorSelectionData.Slot), true, nil
+	case *models.SignRequestContributionAndProof:
+		return DomainContributionAndProof, config.epochAtSlot(t.ContributionAndProof.Contribution.Slot), true, nil
+	case *models.SignRequestRegistration:
+		return DomainApplicationBuilder, 0, false, nil
+	case *models.SignRequestVoluntaryExit:
+		return DomainVoluntaryExit, t.VoluntaryExit.Epoch, true, nil
+	case *models.SignRequestBLSToExecutionChange:
+		return DomainBLSToExecutionChange, 0, false, nil
+	default:
+		return phase0.DomainType{}, 0, false, errors.New("sign request: not supported")
+	}
+}
@@ -0,0 +1,185 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bloxapp/key-vault/keymanager/models"
+)
+
+func praterForkSchedule(t *testing.T) ForkSchedule {
+	forkSchedule, err := ParseForkSchedule(map[string]interface{}{
+		"0x00001020": 0,
+		"0x01001020": 36660,
+		"0x02001020": 112260,
+		"0x03001020": 162304,
+		"0x04001020": 231680,
+	})
+	require.NoError(t, err)
+	return forkSchedule
+}
+
+func withPraterForkSchedule(t *testing.T) func(*Config) {
+	return func(c *Config) {
+		c.ForkSchedule = praterForkSchedule(t)
+		c.GenesisValidatorsRoot = _byteArray32("043db0d9a83813551ee2f33450d23797757d430911a9320530ad8a0eabc43efb")
+	}
+}
+
+func TestParseForkSchedule(t *testing.T) {
+	forkSchedule := praterForkSchedule(t)
+	require.Len(t, forkSchedule, 5)
+
+	version, ok := forkSchedule.VersionAt(36659)
+	require.True(t, ok)
+	require.Equal(t, phase0.Version{0x00, 0x00, 0x10, 0x20}, version)
+	version, ok = forkSchedule.VersionAt(36660)
+	require.True(t, ok)
+	require.Equal(t, phase0.Version{0x01, 0x00, 0x10, 0x20}, version)
+
+	_, err := ParseForkSchedule(map[string]interface{}{"0x01001020": 10})
+	require.EqualError(t, err, "invalid fork_schedule provided: missing genesis fork")
+	_, err = ParseForkSchedule(map[string]interface{}{"0x0100": 0})
+	require.EqualError(t, err, "invalid fork_schedule provided: invalid fork version \"0x0100\"")
+}
+
+func TestValidateSignatureDomain(t *testing.T) {
+	config := &Config{}
+	withPraterForkSchedule(t)(config)
+
+	domain := func(domainType phase0.DomainType, version phase0.Version) phase0.Domain {
+		d, err := computeDomain(domainType, version, config.GenesisValidatorsRoot)
+		require.NoError(t, err)
+		return d
+	}
+	genesis := phase0.Version{0x00, 0x00, 0x10, 0x20}
+	altair := phase0.Version{0x01, 0x00, 0x10, 0x20}
+	capella := phase0.Version{0x03, 0x00, 0x10, 0x20}
+
+	tests := []struct {
+		name        string
+		domain      phase0.Domain
+		object      models.ISignObject
+		expectedErr string
+	}{
+		{
+			name:   "randao at genesis fork",
+			domain: domain(DomainRandao, genesis),
+			object: &models.SignRequestEpoch{Epoch: 10},
+		},
+		{
+			name:   "randao at altair fork",
+			domain: domain(DomainRandao, altair),
+			object: &models.SignRequestEpoch{Epoch: 36660},
+		},
+		{
+			name:        "randao with previous fork version",
+			domain:      domain(DomainRandao, genesis),
+			object:      &models.SignRequestEpoch{Epoch: 36660},
+			expectedErr: "fork version is not valid at epoch 36660: invalid signature domain",
+		},
+		{
+			name:        "randao with proposer domain type",
+			domain:      domain(DomainBeaconProposer, genesis),
+			object:      &models.SignRequestEpoch{Epoch: 10},
+			expectedErr: "domain type 0x00000000 does not match *models.SignRequestEpoch, expected 0x02000000: invalid signature domain",
+		},
+		{
+			name:   "selection proof",
+			domain: domain(DomainSelectionProof, altair),
+			object: &models.SignRequestSlot{Slot: 36660 * 32},
+		},
+		{
+			name:   "sync committee message at any scheduled fork",
+			domain: domain(DomainSyncCommittee, altair),
+			object: &models.SignRequestSyncCommitteeMessage{Root: make([]byte, 32)},
+		},
+		{
+			name:        "sync committee message of another network",
+			domain:      domain(DomainSyncCommittee, phase0.Version{0x01, 0x00, 0x00, 0x00}),
+			object:      &models.SignRequestSyncCommitteeMessage{Root: make([]byte, 32)},
+			expectedErr: "fork version is not in the fork schedule: invalid signature domain",
+		},
+		{
+			name:   "voluntary exit with capella fork version",
+			domain: domain(DomainVoluntaryExit, capella),
+			object: &models.SignRequestVoluntaryExit{VoluntaryExit: &phase0.VoluntaryExit{Epoch: 231680}},
+		},
+		{
+			name:        "voluntary exit with future fork version",
+			domain:      domain(DomainVoluntaryExit, capella),
+			object:      &models.SignRequestVoluntaryExit{VoluntaryExit: &phase0.VoluntaryExit{Epoch: 112260}},
+			expectedErr: "fork version is not valid at epoch 112260: invalid signature domain",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateSignatureDomain(config, &models.SignRequest{
+				SignatureDomain: test.domain,
+				Object:          test.object,
+			})
+			if test.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, test.expectedErr)
+			require.ErrorIs(t, err, ErrInvalidSignatureDomain)
+		})
+	}
+
+	t.Run("configured slots per epoch", func(t *testing.T) {
+		signReq := &models.SignRequest{
+			SignatureDomain: domain(DomainBeaconProposer, altair),
+			Object:          &models.SignRequestBlockHeader{BeaconBlockHeader: &phase0.BeaconBlockHeader{Slot: 36660 * 8}},
+		}
+		require.ErrorIs(t, validateSignatureDomain(config, signReq), ErrInvalidSignatureDomain)
+
+		minimalConfig := *config
+		minimalConfig.SlotsPerEpoch = 8
+		require.NoError(t, validateSignatureDomain(&minimalConfig, signReq))
+	})
+
+	t.Run("no fork schedule", func(t *testing.T) {
+		require.NoError(t, validateSignatureDomain(&Config{}, &models.SignRequest{
+			SignatureDomain: domain(DomainBeaconProposer, genesis),
+			Object:          &models.SignRequestEpoch{Epoch: 10},
+		}))
+	})
+}
+
+func TestSignValidatesSignatureDomain(t *testing.T) {
+	b, _ := getBackend(t)
+
+	req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+	setupBaseStorage(t, req, withPraterForkSchedule(t))
+	require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+	gvr := _byteArray32("043db0d9a83813551ee2f33450d23797757d430911a9320530ad8a0eabc43efb")
+	attesterDomain, err := computeDomain(DomainBeaconAttester, phase0.Version{0x00, 0x00, 0x10, 0x20}, gvr)
+	require.NoError(t, err)
+	proposerDomain, err := computeDomain(DomainBeaconProposer, phase0.Version{0x00, 0x00, 0x10, 0x20}, gvr)
+	require.NoError(t, err)
+	pubKey := _byteArray("95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcf")
+	att := func(blockRoot byte) *phase0.AttestationData {
+		return &phase0.AttestationData{
+			Slot:            2500,
+			BeaconBlockRoot: phase0.Root{blockRoot},
+			Source:          &phase0.Checkpoint{Epoch: 77},
+			Target:          &phase0.Checkpoint{Epoch: 78},
+		}
+	}
+
+	// Attestation under the proposer domain is refused
+	req.Data = reqObject(att(1), proposerDomain, pubKey)
+	_, err = b.HandleRequest(context.Background(), req)
+	require.ErrorIs(t, err, ErrInvalidSignatureDomain)
+
+	// The refused request must not touch the slashing protection data
+	req.Data = reqObject(att(2), attesterDomain, pubKey)
+	_, err = b.HandleRequest(context.Background(), req)
+	require.NoError(t, err)
+}
@@ -0,0 +1,118 @@
+package backend
+
+import (
+	"encoding/json"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+)
+
+// InterchangeFormatVersion is the supported EIP-3076 interchange format version.
+const InterchangeFormatVersion = "5"
+
+// Interchange is an EIP-3076 slashing protection interchange, in the complete or the minimal format.
+// https://eips.ethereum.org/EIPS/eip-3076
+type Interchange struct {
+	Metadata InterchangeMetadata `json:"metadata"`
+	Data     []*InterchangeData  `json:"data"`
+}
+
+// InterchangeMetadata is the metadata of an interchange.
+type InterchangeMetadata struct {
+	InterchangeFormatVersion string      `json:"interchange_format_version"`
+	GenesisValidatorsRoot    phase0.Root `json:"genesis_validators_root"`
+}
+
+// InterchangeData contains the signed blocks and attestations of a single public key.
+type InterchangeData struct {
+	PublicKey          phase0.BLSPubKey          `json:"pubkey"`
+	SignedBlocks       []*InterchangeBlock       `json:"signed_blocks"`
+	SignedAttestations []*InterchangeAttestation `json:"signed_attestations"`
+}
+
+// InterchangeBlock is a signed block of an interchange.
+type InterchangeBlock struct {
+	Slot        phase0.Slot  `json:"slot,string"`
+	SigningRoot *phase0.Root `json:"signing_root,omitempty"`
+}
+
+// InterchangeAttestation is a signed attestation of an interchange.
+type InterchangeAttestation struct {
+	SourceEpoch phase0.Epoch `json:"source_epoch,string"`
+	TargetEpoch phase0.Epoch `json:"target_epoch,string"`
+	SigningRoot *phase0.Root `json:"signing_root,omitempty"`
+}
+
+// interchangeMaxima contains the highest signed slot and epochs of a public key in an interchange.
+type interchangeMaxima struct {
+	Slot        phase0.Slot
+	SourceEpoch phase0.Epoch
+	TargetEpoch phase0.Epoch
+	HasAtts     bool
+}
+
+// ErrInterchangeGenesisValidatorsRoot is returned when an interchange belongs to another chain.
+var ErrInterchangeGenesisValidatorsRoot = errors.New("interchange genesis validators root does not match the network")
+
+// parseInterchange decodes the given interchange and validates it against the given genesis validators root.
+func parseInterchange(data []byte, genesisValidatorsRoot phase0.Root) (*Interchange, error) {
+	var interchange Interchange
+	if err := json.Unmarshal(data, &interchange); err != nil {
+		return nil, errors.Wrap(err, "failed to decode interchange")
+	}
+
+	if interchange.Metadata.InterchangeFormatVersion != InterchangeFormatVersion {
+		return nil, errors.Errorf("unsupported interchange format version %q", interchange.Metadata.InterchangeFormatVersion)
+	}
+	if interchange.Metadata.GenesisValidatorsRoot != genesisValidatorsRoot {
+		return nil, errors.Wrapf(ErrInterchangeGenesisValidatorsRoot, "got %#x, expected %#x", interchange.Metadata.GenesisValidatorsRoot, genesisValidatorsRoot)
+	}
+
+	for _, d := range interchange.Data {
+		if d == nil {
+			return nil, errors.New("invalid interchange: empty data entry")
+		}
+		for _, block := range d.SignedBlocks {
+			if block == nil {
+				return nil, errors.Errorf("invalid interchange: empty signed block of %#x", d.PublicKey)
+			}
+		}
+		for _, att := range d.SignedAttestations {
+			if att == nil {
+				return nil, errors.Errorf("invalid interchange: empty signed attestation of %#x", d.PublicKey)
+			}
+			if att.SourceEpoch > att.TargetEpoch {
+				return nil, errors.Errorf("invalid interchange: attestation of %#x has source epoch %d after target epoch %d", d.PublicKey, att.SourceEpoch, att.TargetEpoch)
+			}
+		}
+	}
+	return &interchange, nil
+}
+
+// maxima returns the highest signed slot and epochs of each public key in the interchange.
+// A public key may appear in several data entries, they are merged.
+func (i *Interchange) maxima() map[phase0.BLSPubKey]*interchangeMaxima {
+	maxima := make(map[phase0.BLSPubKey]*interchangeMaxima, len(i.Data))
+	for _, d := range i.Data {
+		m, ok := maxima[d.PublicKey]
+		if !ok {
+			m = &interchangeMaxima{}
+			maxima[d.PublicKey] = m
+		}
+		for _, block := range d.SignedBlocks {
+			if block.Slot > m.Slot {
+				m.Slot = block.Slot
+			}
+		}
+		for _, att := range d.SignedAttestations {
+			if att.SourceEpoch > m.SourceEpoch {
+				m.SourceEpoch = att.SourceEpoch
+			}
+			if att.TargetEpoch > m.TargetEpoch {
+				m.TargetEpoch = att.TargetEpoch
+			}
+			m.HasAtts = true
+		}
+	}
+	return maxima
+}
@@ -0,0 +1,140 @@
+package backend
+
+import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// lockTableShards is the number of shards of the lock table, lookups of keys in different shards never contend.
+const lockTableShards = 64
+
+// lockTable is a sharded table of per key locks.
+// Entries are reference counted and removed once no caller holds or waits for them, so the table only
+// grows with the number of keys in use at the same time.
+type lockTable struct {
+	shards [lockTableShards]lockTableShard
+	stats  lockStats
+}
+
+type lockTableShard struct {
+	lock    sync.Mutex
+	entries map[string]*lockEntry
+}
+
+type lockEntry struct {
+	lock sync.Mutex
+	refs int
+}
+
+// lockStats holds the lock table counters, durations are in nanoseconds.
+type lockStats struct {
+	acquisitions uint64
+	contended    uint64
+	waitTotal    int64
+	waitMax      int64
+}
+
+// LockStats is a snapshot of the lock table counters.
+type LockStats struct {
+	Active       int
+	Acquisitions uint64
+	Contended    uint64
+	WaitTotal    time.Duration
+	WaitMax      time.Duration
+}
+
+// Map returns a map representation of the stats.
+func (s LockStats) Map() map[string]interface{} {
+	var waitAvg time.Duration
+	if s.Contended > 0 {
+		waitAvg = s.WaitTotal / time.Duration(s.Contended)
+	}
+	return map[string]interface{}{
+		"active":       s.Active,
+		"acquisitions": s.Acquisitions,
+		"contended":    s.Contended,
+		"wait_total":   s.WaitTotal.String(),
+		"wait_avg":     waitAvg.String(),
+		"wait_max":     s.WaitMax.String(),
+	}
+}
+
+// newLockTable returns an empty lock table.
+func newLockTable() *lockTable {
+	t := &lockTable{}
+	for i := range t.shards {
+		t.shards[i].entries = make(map[string]*lockEntry)
+	}
+	return t
+}
+
+// lock acquires the lock of the given key and returns the function releasing it.
+func (t *lockTable) lock(key string) func() {
+	shard := t.shard(key)
+
+	shard.lock.Lock()
+	entry, ok := shard.entries[key]
+	if !ok {
+		entry = &lockEntry{}
+		shard.entries[key] = entry
+	}
+	entry.refs++
+	shard.lock.Unlock()
+
+	atomic.AddUint64(&t.stats.acquisitions, 1)
+	if !entry.lock.TryLock() {
+		start := time.Now()
+		entry.lock.Lock()
+		t.recordWait(time.Since(start))
+	}
+
+	return func() {
+		entry.lock.Unlock()
+
+		shard.lock.Lock()
+		entry.refs--
+		if entry.refs == 0 {
+			delete(shard.entries, key)
+		}
+		shard.lock.Unlock()
+	}
+}
+
+// recordWait records the time a caller waited for a held lock.
+func (t *lockTable) recordWait(wait time.Duration) {
+	atomic.AddUint64(&t.stats.contended, 1)
+	atomic.AddInt64(&t.stats.waitTotal, int64(wait))
+	for {
+		max := atomic.LoadInt64(&t.stats.waitMax)
+		if int64(wait) <= max || atomic.CompareAndSwapInt64(&t.stats.waitMax, max, int64(wait)) {
+			return
+		}
+	}
+}
+
+// Stats returns a snapshot of the lock table counters.
+func (t *lockTable) Stats() LockStats {
+	active := 0
+	for i := range t.shards {
+		shard := &t.shards[i]
+		shard.lock.Lock()
+		active += len(shard.entries)
+		shard.lock.Unlock()
+	}
+
+	return LockStats{
+		Active:       active,
+		Acquisitions: atomic.LoadUint64(&t.stats.acquisitions),
+		Contended:    atomic.LoadUint64(&t.stats.contended),
+		WaitTotal:    time.Duration(atomic.LoadInt64(&t.stats.waitTotal)),
+		WaitMax:      time.Duration(atomic.LoadInt64(&t.stats.waitMax)),
+	}
+}
+
+func (t *lockTable) shard(key string) *lockTableShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return &t.shards[h.Sum32()%lockTableShards]
+}
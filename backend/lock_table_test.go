@@ -0,0 +1,77 @@
+package backend
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockTable(t *testing.T) {
+	t.Run("Remove idle locks", func(t *testing.T) {
+		table := newLockTable()
+
+		unlockA := table.lock("a")
+		unlockB := table.lock("b")
+		require.Equal(t, 2, table.Stats().Active)
+
+		unlockA()
+		unlockB()
+		stats := table.Stats()
+		require.Equal(t, 0, stats.Active)
+		require.EqualValues(t, 2, stats.Acquisitions)
+		require.EqualValues(t, 0, stats.Contended)
+	})
+
+	t.Run("Serialize callers of the same key", func(t *testing.T) {
+		table := newLockTable()
+
+		var (
+			wg      sync.WaitGroup
+			counter int
+		)
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				unlock := table.lock("a")
+				defer unlock()
+				counter++
+			}()
+		}
+		wg.Wait()
+
+		require.Equal(t, 100, counter)
+		require.Equal(t, 0, table.Stats().Active)
+	})
+
+	t.Run("Record wait time", func(t *testing.T) {
+		table := newLockTable()
+
+		unlock := table.lock("a")
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			table.lock("a")()
+		}()
+
+		// Wait until the second caller references the lock
+		require.Eventually(t, func() bool {
+			shard := table.shard("a")
+			shard.lock.Lock()
+			defer shard.lock.Unlock()
+			return shard.entries["a"].refs == 2
+		}, time.Second, time.Millisecond)
+		time.Sleep(10 * time.Millisecond)
+		unlock()
+		<-done
+
+		stats := table.Stats()
+		require.Equal(t, 0, stats.Active)
+		require.EqualValues(t, 2, stats.Acquisitions)
+		require.EqualValues(t, 1, stats.Contended)
+		require.GreaterOrEqual(t, stats.WaitMax, 10*time.Millisecond)
+		require.Equal(t, stats.WaitMax, stats.WaitTotal)
+	})
+}
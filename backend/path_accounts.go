@@ -1,15 +1,21 @@
 package backend
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
 
-	vault "github.com/bloxapp/eth2-key-manager"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/bloxapp/eth2-key-manager/wallets/hd"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/pkg/errors"
-
-	"github.com/bloxapp/key-vault/backend/store"
 )
 
 // Endpoints patterns
@@ -18,58 +24,232 @@ const (
 	AccountsPattern = "accounts/"
 )
 
+// MaxAccountsListLimit is the maximum number of accounts listed in a page.
+const MaxAccountsListLimit = 1000
+
 func accountsPaths(b *backend) []*framework.Path {
 	return []*framework.Path{
 		{
 			Pattern:         AccountsPattern,
 			HelpSynopsis:    "List wallet accounts",
-			HelpDescription: ``,
-			Fields:          map[string]*framework.FieldSchema{},
-			ExistenceCheck:  b.pathExistenceCheck,
+			HelpDescription: `List wallet accounts ordered by public key, optionally filtered and paginated`,
+			Fields: map[string]*framework.FieldSchema{
+				"after": {
+					Type:        framework.TypeString,
+					Description: "Cursor returned by the previous page, lists the accounts after it",
+				},
+				"limit": {
+					Type:        framework.TypeInt,
+					Description: fmt.Sprintf("Maximum number of accounts to list, all of them when not set (max %d)", MaxAccountsListLimit),
+				},
+				"public_key_prefix": {
+					Type:        framework.TypeString,
+					Description: "Hex encoded prefix of the public keys to list",
+				},
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Glob pattern of the account names to list, e.g. account-1*",
+				},
+				"status": {
+					Type:        framework.TypeString,
+					Description: "Status of the accounts to list: enabled or disabled",
+				},
+			},
+			ExistenceCheck: b.pathExistenceCheck,
 			Operations: map[logical.Operation]framework.OperationHandler{
 				logical.ListOperation: &framework.PathOperation{
 					Callback: b.pathWalletAccountsList,
 				},
 			},
 		},
+		{
+			Pattern:         AccountsPattern + pubKeyRegex("public_key"),
+			HelpSynopsis:    "Read wallet account",
+			HelpDescription: `Read an account, its status and its slashing protection`,
+			Fields: map[string]*framework.FieldSchema{
+				"public_key": {
+					Type:        framework.TypeString,
+					Description: "Hex encoded public key of the account",
+				},
+			},
+			ExistenceCheck: b.pathExistenceCheck,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathWalletAccountRead,
+				},
+			},
+		},
 	}
 }
 
 func (b *backend) pathWalletAccountsList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	after := strings.ToLower(strings.TrimPrefix(data.Get("after").(string), "0x"))
+	limit := data.Get("limit").(int)
+	if limit < 0 || limit > MaxAccountsListLimit {
+		return nil, errors.Errorf("invalid limit %d provided, must be at most %d", limit, MaxAccountsListLimit)
+	}
+	prefix := strings.ToLower(strings.TrimPrefix(data.Get("public_key_prefix").(string), "0x"))
+	if _, err := hex.DecodeString(prefix + strings.Repeat("0", len(prefix)%2)); err != nil {
+		return nil, errors.Errorf("invalid public key prefix %q provided", data.Get("public_key_prefix"))
+	}
+	namePattern := data.Get("name").(string)
+	if _, err := path.Match(namePattern, ""); err != nil {
+		return nil, errors.Errorf("invalid name pattern %q provided", namePattern)
+	}
+	statusFilter := data.Get("status").(string)
+	if statusFilter != "" && statusFilter != AccountStatusEnabled && statusFilter != AccountStatusDisabled {
+		return nil, errors.Errorf("invalid status %q provided", statusFilter)
+	}
+
 	// Load config
 	config, err := b.readConfig(ctx, req.Storage)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get config")
 	}
 
-	storage := store.NewHashicorpVaultStore(ctx, req.Storage, config.Network)
-	options := vault.KeyVaultOptions{}
-	options.SetStorage(storage)
-
-	portfolio, err := vault.OpenKeyVault(&options)
+	storage, wallet, err := b.openWallet(ctx, req.Storage, config)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to open key vault")
+		return nil, err
 	}
 
-	wallet, err := portfolio.Wallet()
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to retrieve wallet by name")
-	}
+	// Accounts are ordered by public key, which is also the cursor, so pages are stable across imports and deletions.
+	walletAccounts := wallet.Accounts()
+	sort.Slice(walletAccounts, func(i, j int) bool {
+		return bytes.Compare(walletAccounts[i].ValidatorPublicKey(), walletAccounts[j].ValidatorPublicKey()) < 0
+	})
 
 	var accounts []map[string]string
-	for _, a := range wallet.Accounts() {
+	var nextCursor string
+	for _, a := range walletAccounts {
+		pubKey := hex.EncodeToString(a.ValidatorPublicKey())
+		if pubKey <= after || !strings.HasPrefix(pubKey, prefix) {
+			continue
+		}
+		if namePattern != "" {
+			if matched, _ := path.Match(namePattern, a.Name()); !matched {
+				continue
+			}
+		}
+		status, _, err := storage.RetrieveAccountStatus(a.ValidatorPublicKey())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to retrieve account status")
+		}
+		if statusFilter != "" && accountStatusName(status) != statusFilter {
+			continue
+		}
+
+		// Another matching account means there is a next page
+		if limit > 0 && len(accounts) == limit {
+			nextCursor = accounts[len(accounts)-1]["validationPubKey"]
+			break
+		}
 		accObj := map[string]string{
 			"id":               a.ID().String(),
 			"name":             a.Name(),
-			"validationPubKey": hex.EncodeToString(a.ValidatorPublicKey()),
+			"validationPubKey": pubKey,
 			"withdrawalPubKey": hex.EncodeToString(a.WithdrawalPublicKey()),
+			"status":           accountStatusName(status),
 		}
 		accounts = append(accounts, accObj)
 	}
 
+	res := map[string]interface{}{
+		"accounts": accounts,
+	}
+	if nextCursor != "" {
+		res["next_cursor"] = nextCursor
+	}
 	return &logical.Response{
-		Data: map[string]interface{}{
-			"accounts": accounts,
-		},
+		Data: res,
+	}, nil
+}
+
+func (b *backend) pathWalletAccountRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	pubKey, err := hexutil.Decode(ensureHexPrefix(data.Get("public_key").(string)))
+	if err != nil || len(pubKey) != BLSPubkeyLength {
+		return nil, errors.New("invalid public key provided")
+	}
+
+	// Load config
+	config, err := b.readConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get config")
+	}
+
+	storage, wallet, err := b.openWallet(ctx, req.Storage, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var res map[string]interface{}
+	err = b.lock(wallet, pubKey, func() error {
+		account, err := wallet.AccountByPublicKey(hex.EncodeToString(pubKey))
+		if err != nil {
+			return err
+		}
+		index, err := strconv.Atoi(strings.TrimPrefix(account.BasePath(), "/"))
+		if err != nil {
+			return errors.Wrapf(err, "invalid account base path %q", account.BasePath())
+		}
+
+		status, _, err := storage.RetrieveAccountStatus(pubKey)
+		if err != nil {
+			return errors.Wrap(err, "failed to retrieve account status")
+		}
+
+		res = map[string]interface{}{
+			"id":                    account.ID().String(),
+			"name":                  account.Name(),
+			"index":                 index,
+			"validation_path":       config.Network.FullPath(fmt.Sprintf(hd.ValidatorKeyPath, index)),
+			"public_key":            hexutil.Encode(account.ValidatorPublicKey()),
+			"withdrawal_public_key": hexutil.Encode(account.WithdrawalPublicKey()),
+			"status":                accountStatusName(status),
+			"fee_recipient":         nil,
+			"highest_attestation":   nil,
+			"highest_proposal":      nil,
+		}
+		if feeRecipient, ok := config.FeeRecipients.Get(pubKey); ok {
+			res["fee_recipient"] = feeRecipient.Hex()
+		}
+
+		att, attRoot, found, err := storage.RetrieveHighestAttestationWithSigningRoot(pubKey)
+		if err != nil {
+			return errors.Wrap(err, "failed to retrieve highest attestation")
+		}
+		if found && att != nil {
+			res["highest_attestation"] = map[string]interface{}{
+				"source_epoch": att.Source.Epoch,
+				"target_epoch": att.Target.Epoch,
+				"signing_root": signingRootString(attRoot),
+			}
+		}
+
+		slot, proposalRoot, found, err := storage.RetrieveHighestProposalWithSigningRoot(pubKey)
+		if err != nil {
+			return errors.Wrap(err, "failed to retrieve highest proposal")
+		}
+		if found {
+			res["highest_proposal"] = map[string]interface{}{
+				"slot":         slot,
+				"signing_root": signingRootString(proposalRoot),
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: res,
 	}, nil
 }
+
+// signingRootString returns the hex encoding of the given signing root, or an empty string when there is none.
+func signingRootString(root *phase0.Root) string {
+	if root == nil {
+		return ""
+	}
+	return hexutil.Encode(root[:])
+}
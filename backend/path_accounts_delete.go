@@ -0,0 +1,151 @@
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/bloxapp/key-vault/backend/store"
+)
+
+// Endpoints patterns
+const (
+	// AccountsDeletePattern is the path pattern for delete accounts endpoint
+	AccountsDeletePattern = "accounts/delete"
+)
+
+func accountsDeletePaths(b *backend) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         AccountsDeletePattern,
+			HelpSynopsis:    "Delete accounts",
+			HelpDescription: `Delete accounts by their public keys. Their slashing protection records are kept, so they can't be imported again with lower ones`,
+			Fields: map[string]*framework.FieldSchema{
+				"public_keys": {
+					Type:        framework.TypeCommaStringSlice,
+					Description: "Hex encoded public keys of the accounts to delete",
+				},
+				"export": {
+					Type:        framework.TypeBool,
+					Description: "Return the slashing protection of the deleted accounts as an EIP-3076 interchange",
+				},
+			},
+			ExistenceCheck: b.pathExistenceCheck,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.CreateOperation: &framework.PathOperation{
+					Callback: b.pathAccountsDelete,
+				},
+			},
+		},
+	}
+}
+
+func (b *backend) pathAccountsDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	identity, err := requesterIdentity(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var pubKeys [][]byte
+	seen := make(map[string]bool)
+	for _, pubKeyHex := range data.Get("public_keys").([]string) {
+		pubKey, err := hexutil.Decode(ensureHexPrefix(pubKeyHex))
+		if err != nil || len(pubKey) != BLSPubkeyLength {
+			return nil, errors.Errorf("invalid public key %q provided", pubKeyHex)
+		}
+		if !seen[hex.EncodeToString(pubKey)] {
+			seen[hex.EncodeToString(pubKey)] = true
+			pubKeys = append(pubKeys, pubKey)
+		}
+	}
+	if len(pubKeys) == 0 {
+		return nil, errors.New("public_keys is required")
+	}
+
+	// Load config
+	config, err := b.readConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get config")
+	}
+
+	// The accounts are deleted at once or not at all, like storage updates, see store.UpdateTransactionally.
+	b.storageLock.Lock()
+	defer b.storageLock.Unlock()
+	defer b.walletCache.reset()
+
+	interchange := &Interchange{
+		Metadata: InterchangeMetadata{
+			InterchangeFormatVersion: InterchangeFormatVersion,
+			GenesisValidatorsRoot:    config.genesisValidatorsRoot(),
+		},
+		Data: make([]*InterchangeData, 0, len(pubKeys)),
+	}
+	err = store.UpdateTransactionally(ctx, req.Storage, func(s logical.Storage) error {
+		// The cached wallet is read-only, accounts are deleted from the stored one.
+		storage := store.NewHashicorpVaultStore(ctx, s, config.Network)
+		wallet, err := openStoredWallet(storage)
+		if err != nil {
+			return err
+		}
+
+		// Nothing is deleted unless all accounts exist
+		for _, pubKey := range pubKeys {
+			if _, err := wallet.AccountByPublicKey(hex.EncodeToString(pubKey)); err != nil {
+				return errors.Wrapf(err, "failed to delete account %#x", pubKey)
+			}
+		}
+
+		for _, pubKey := range pubKeys {
+			// The slashing protection is exported and the account deleted under the public key lock,
+			// so no sign request changes it in between.
+			err := b.lock(wallet, pubKey, func() error {
+				d, err := interchangeData(storage, pubKey)
+				if err != nil {
+					return err
+				}
+				if err := wallet.DeleteAccountByPublicKey(hex.EncodeToString(pubKey)); err != nil {
+					return err
+				}
+				interchange.Data = append(interchange.Data, d)
+				return nil
+			})
+			if err != nil {
+				return errors.Wrapf(err, "failed to delete account %#x", pubKey)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := make([]string, 0, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		deleted = append(deleted, hexutil.Encode(pubKey))
+
+		b.logger.WithFields(logrus.Fields{
+			"public_key": hexutil.Encode(pubKey),
+			"deleted_by": identity,
+		}).Info("Account deleted")
+	}
+
+	res := map[string]interface{}{
+		"deleted": deleted,
+	}
+	if data.Get("export").(bool) {
+		interchangeJSON, err := json.Marshal(interchange)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encode interchange")
+		}
+		res["interchange"] = string(interchangeJSON)
+	}
+	return &logical.Response{
+		Data: res,
+	}, nil
+}
@@ -0,0 +1,202 @@
+package backend
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/bloxapp/eth2-key-manager/core"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bloxapp/key-vault/backend/store"
+)
+
+// failingDeleteStorage fails the deletion of the failAt-th account.
+type failingDeleteStorage struct {
+	logical.Storage
+	deletes int
+	failAt  int
+}
+
+func (s *failingDeleteStorage) Delete(ctx context.Context, key string) error {
+	if strings.HasPrefix(key, store.AccountBase) {
+		s.deletes++
+		if s.deletes == s.failAt {
+			return errors.New("delete failed")
+		}
+	}
+	return s.Storage.Delete(ctx, key)
+}
+
+func TestAccountsDelete(t *testing.T) {
+	b, _ := getBackend(t)
+	pubKey := _byteArray(basePubKey)
+
+	deleteRequest := func(t *testing.T, s logical.Storage, data map[string]interface{}) (*logical.Response, error) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/delete")
+		req.Storage = s
+		req.EntityID = "admin"
+		req.Data = data
+		return b.HandleRequest(context.Background(), req)
+	}
+
+	t.Run("delete account and keep its slashing protection", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req)
+		storage, err := baseHashicorpStorage(context.Background(), req.Storage)
+		require.NoError(t, err)
+		require.NoError(t, storage.SaveHighestAttestation(pubKey, &phase0.AttestationData{
+			Source: &phase0.Checkpoint{Epoch: 2290},
+			Target: &phase0.Checkpoint{Epoch: 3007},
+		}))
+		require.NoError(t, storage.SaveHighestProposal(pubKey, 81952))
+
+		// Cache the wallet before the deletion
+		listReq := logical.TestRequest(t, logical.ListOperation, "accounts/")
+		listReq.Storage = req.Storage
+		res, err := b.HandleRequest(context.Background(), listReq)
+		require.NoError(t, err)
+		require.Len(t, res.Data["accounts"], 1)
+
+		res, err = deleteRequest(t, req.Storage, map[string]interface{}{
+			"public_keys": basePubKey,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"0x" + basePubKey}, res.Data["deleted"])
+		require.Nil(t, res.Data["interchange"])
+
+		res, err = b.HandleRequest(context.Background(), listReq)
+		require.NoError(t, err)
+		require.Empty(t, res.Data["accounts"])
+
+		req.Data = basicAttestationData()
+		_, err = b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "failed to sign: account not found")
+
+		highestAtt, found, err := storage.RetrieveHighestAttestation(pubKey)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.EqualValues(t, 3007, highestAtt.Target.Epoch)
+
+		// Importing the account again doesn't lower its slashing protection
+		importBaseStorage(t, b, req.Storage)
+
+		res, err = b.HandleRequest(context.Background(), listReq)
+		require.NoError(t, err)
+		require.Len(t, res.Data["accounts"], 1)
+
+		highestAtt, found, err = storage.RetrieveHighestAttestation(pubKey)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.EqualValues(t, 2290, highestAtt.Source.Epoch)
+		require.EqualValues(t, 3007, highestAtt.Target.Epoch)
+		highestProposal, found, err := storage.RetrieveHighestProposal(pubKey)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.EqualValues(t, 81952, highestProposal)
+
+		_, err = b.HandleRequest(context.Background(), req)
+		require.ErrorContains(t, err, "slashable attestation")
+	})
+
+	t.Run("delete account with export", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/delete")
+		setupBaseStorage(t, req)
+		storage, err := baseHashicorpStorage(context.Background(), req.Storage)
+		require.NoError(t, err)
+		require.NoError(t, storage.SaveHighestAttestation(pubKey, &phase0.AttestationData{
+			Source: &phase0.Checkpoint{Epoch: 2290},
+			Target: &phase0.Checkpoint{Epoch: 3007},
+		}))
+		require.NoError(t, storage.SaveHighestProposal(pubKey, 81952))
+
+		res, err := deleteRequest(t, req.Storage, map[string]interface{}{
+			"public_keys": basePubKey,
+			"export":      true,
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"metadata":{"interchange_format_version":"5","genesis_validators_root":"0x043db0d9a83813551ee2f33450d23797757d430911a9320530ad8a0eabc43efb"},`+
+			`"data":[{"pubkey":"0x`+basePubKey+`","signed_blocks":[{"slot":"81952"}],"signed_attestations":[{"source_epoch":"2290","target_epoch":"3007"}]}]}`,
+			res.Data["interchange"].(string))
+	})
+
+	t.Run("delete unknown account", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.ListOperation, "accounts/")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		_, err := deleteRequest(t, req.Storage, map[string]interface{}{
+			"public_keys": basePubKey + ",95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcd",
+		})
+		require.EqualError(t, err, "failed to delete account 0x95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcd: account not found")
+
+		// Nothing is deleted
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Len(t, res.Data["accounts"], 1)
+	})
+
+	t.Run("failed deletion is rolled back", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.ListOperation, "accounts/")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+		wallet, err := openStoredWallet(store.NewHashicorpVaultStore(context.Background(), req.Storage, core.PraterNetwork))
+		require.NoError(t, err)
+		account, err := wallet.CreateValidatorAccount(_byteArray("0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1fff"), nil)
+		require.NoError(t, err)
+
+		_, err = deleteRequest(t, &failingDeleteStorage{Storage: req.Storage, failAt: 2}, map[string]interface{}{
+			"public_keys": basePubKey + "," + hexutil.Encode(account.ValidatorPublicKey()),
+		})
+		require.ErrorContains(t, err, "delete failed")
+
+		// Nothing is deleted
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Len(t, res.Data["accounts"], 2)
+	})
+
+	t.Run("delete by token without entity", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/delete")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+		req.DisplayName = "root"
+		req.Data = map[string]interface{}{
+			"public_keys": basePubKey,
+		}
+
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, []string{"0x" + basePubKey}, res.Data["deleted"])
+	})
+
+	t.Run("delete without requester identity", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/delete")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+		req.Data = map[string]interface{}{
+			"public_keys": basePubKey,
+		}
+
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, ErrRequesterIdentity.Error())
+	})
+
+	t.Run("delete without public keys", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/delete")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		_, err := deleteRequest(t, req.Storage, nil)
+		require.EqualError(t, err, "public_keys is required")
+
+		_, err = deleteRequest(t, req.Storage, map[string]interface{}{
+			"public_keys": "0x01",
+		})
+		require.EqualError(t, err, `invalid public key "0x01" provided`)
+	})
+}
@@ -0,0 +1,194 @@
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/bloxapp/key-vault/backend/store"
+	"github.com/bloxapp/key-vault/keymanager/models"
+)
+
+// Endpoints patterns
+const (
+	// QuarantinePattern is the path pattern suffix for quarantine endpoint, prefixed by accounts/<public key>
+	QuarantinePattern = "/quarantine"
+)
+
+// ErrAccountQuarantined is returned when signing an attestation or a block of a quarantined account.
+var ErrAccountQuarantined = errors.New("account is quarantined")
+
+func accountsQuarantinePaths(b *backend) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         AccountsPattern + pubKeyRegex("public_key") + QuarantinePattern,
+			HelpSynopsis:    "Manage the signing quarantine of an account",
+			HelpDescription: `Read the signing quarantine of an imported account, or lift it`,
+			Fields: map[string]*framework.FieldSchema{
+				"public_key": {
+					Type:        framework.TypeString,
+					Description: "Hex encoded public key of the account",
+				},
+			},
+			ExistenceCheck: b.pathExistenceCheck,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathQuarantineRead,
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: b.pathQuarantineLift,
+				},
+			},
+		},
+	}
+}
+
+func (b *backend) pathQuarantineRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	pubKey, err := hexutil.Decode(ensureHexPrefix(data.Get("public_key").(string)))
+	if err != nil || len(pubKey) != BLSPubkeyLength {
+		return nil, errors.New("invalid public key provided")
+	}
+
+	// Load config
+	config, err := b.readConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get config")
+	}
+
+	storage, wallet, err := b.openWallet(ctx, req.Storage, config)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := wallet.AccountByPublicKey(hex.EncodeToString(pubKey)); err != nil {
+		return nil, err
+	}
+
+	quarantine, _, err := storage.RetrieveQuarantine(pubKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve quarantine")
+	}
+	return &logical.Response{
+		Data: quarantineMap(pubKey, quarantine, config.epochAt(b.now())),
+	}, nil
+}
+
+func (b *backend) pathQuarantineLift(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	identity, err := requesterIdentity(req)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey, err := hexutil.Decode(ensureHexPrefix(data.Get("public_key").(string)))
+	if err != nil || len(pubKey) != BLSPubkeyLength {
+		return nil, errors.New("invalid public key provided")
+	}
+
+	// Load config
+	config, err := b.readConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get config")
+	}
+
+	storage, wallet, err := b.openWallet(ctx, req.Storage, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var quarantine *store.Quarantine
+	err = b.lock(wallet, pubKey, func() error {
+		var found bool
+		quarantine, found, err = storage.RetrieveQuarantine(pubKey)
+		if err != nil {
+			return errors.Wrap(err, "failed to retrieve quarantine")
+		}
+		if !found {
+			return errors.Errorf("account %#x is not quarantined", pubKey)
+		}
+		if quarantine.LiftedAt != nil {
+			return nil
+		}
+
+		now := b.now()
+		quarantine.LiftedBy = identity
+		quarantine.LiftedAt = &now
+		if err := storage.SaveQuarantine(pubKey, quarantine); err != nil {
+			return errors.Wrap(err, "failed to save quarantine")
+		}
+		b.logger.WithFields(logrus.Fields{
+			"public_key": hexutil.Encode(pubKey),
+			"lifted_by":  identity,
+		}).Info("Quarantine lifted")
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: quarantineMap(pubKey, quarantine, config.epochAt(b.now())),
+	}, nil
+}
+
+// quarantineMap returns a map representation of the quarantine of the given public key, which may be nil.
+func quarantineMap(pubKey []byte, quarantine *store.Quarantine, epoch phase0.Epoch) map[string]interface{} {
+	ret := map[string]interface{}{
+		"public_key":    hexutil.Encode(pubKey),
+		"current_epoch": epoch,
+		"quarantined":   quarantine != nil && quarantine.Active(epoch),
+	}
+	if quarantine != nil {
+		ret["activation_epoch"] = quarantine.ActivationEpoch
+		ret["quarantined_at"] = quarantine.QuarantinedAt
+		ret["lifted_by"] = quarantine.LiftedBy
+		ret["lifted_at"] = quarantine.LiftedAt
+	}
+	return ret
+}
+
+// quarantineAccounts quarantines the given public keys for the configured number of epochs from now.
+func quarantineAccounts(storage *store.HashicorpVaultStore, config *Config, pubKeys [][]byte, now time.Time) error {
+	if config.QuarantineEpochs == 0 {
+		return nil
+	}
+
+	quarantine := &store.Quarantine{
+		ActivationEpoch: config.epochAt(now) + phase0.Epoch(config.QuarantineEpochs),
+		QuarantinedAt:   now,
+	}
+	for _, pubKey := range pubKeys {
+		if err := storage.SaveQuarantine(pubKey, quarantine); err != nil {
+			return errors.Wrapf(err, "failed to quarantine %x", pubKey)
+		}
+	}
+	return nil
+}
+
+// checkQuarantine refuses attestations and blocks of quarantined accounts.
+// Selection proofs, sync committee duties, registrations and exits are still signed.
+func checkQuarantine(storage *store.HashicorpVaultStore, config *Config, signReq *models.SignRequest, now time.Time) error {
+	switch signReq.GetObject().(type) {
+	case *models.SignRequestBlock,
+		*models.SignRequestBlindedBlock,
+		*models.SignRequestBlockHeader,
+		*models.SignRequestAttestationData,
+		*models.SignRequestAggregateAttestationAndProof:
+	default:
+		return nil
+	}
+
+	quarantine, found, err := storage.RetrieveQuarantine(signReq.PublicKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve quarantine")
+	}
+	if found && quarantine.Active(config.epochAt(now)) {
+		return errors.Wrapf(ErrAccountQuarantined, "refused to sign until epoch %d", quarantine.ActivationEpoch)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/bloxapp/eth2-key-manager/core"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bloxapp/key-vault/backend/store"
+	"github.com/bloxapp/key-vault/keymanager/models"
+	"github.com/bloxapp/key-vault/utils/encoder"
+)
+
+func importBaseStorage(t *testing.T, b logical.Backend, storage logical.Storage) {
+	inMemStore, _, err := baseInmemStorage()
+	require.NoError(t, err)
+	byts, err := json.Marshal(inMemStore)
+	require.NoError(t, err)
+
+	req := logical.TestRequest(t, logical.CreateOperation, "storage")
+	req.Storage = storage
+	req.Data = map[string]interface{}{
+		"data": hex.EncodeToString(byts),
+	}
+	res, err := b.HandleRequest(context.Background(), req)
+	require.NoError(t, err)
+	require.True(t, res.Data["status"].(bool))
+}
+
+func signSlotData() map[string]interface{} {
+	byts, _ := encoder.New().Encode(&models.SignRequest{
+		PublicKey:       _byteArray(basePubKey),
+		SignatureDomain: _byteArray32("05000000f071c66c6561d0b939feb15f513a019d99a84bd85635221e3ad42dac"),
+		Object:          &models.SignRequestSlot{Slot: 284115},
+	})
+	return map[string]interface{}{
+		"sign_req": hex.EncodeToString(byts),
+	}
+}
+
+// signingStorage sends a sign request whenever the wallet data is saved.
+type signingStorage struct {
+	logical.Storage
+	sign func()
+}
+
+func (s *signingStorage) Put(ctx context.Context, entry *logical.StorageEntry) error {
+	if err := s.Storage.Put(ctx, entry); err != nil {
+		return err
+	}
+	if entry.Key == store.WalletDataPath {
+		s.sign()
+	}
+	return nil
+}
+
+func TestQuarantine(t *testing.T) {
+	b, _ := getBackend(t)
+
+	withQuarantine := func(c *Config) {
+		c.QuarantineEpochs = 2
+	}
+	request := func(t *testing.T, storage logical.Storage, op logical.Operation, path string, data map[string]interface{}) (*logical.Response, error) {
+		req := logical.TestRequest(t, op, path)
+		req.Storage = storage
+		req.Data = data
+		req.DisplayName = "admin"
+		return b.HandleRequest(context.Background(), req)
+	}
+
+	t.Run("imported account is quarantined", func(t *testing.T) {
+		storage := &logical.InmemStorage{}
+		setupBaseStorage(t, &logical.Request{Storage: storage}, withQuarantine)
+		importBaseStorage(t, b, storage)
+
+		res, err := request(t, storage, logical.ReadOperation, "accounts/"+basePubKey+"/quarantine", nil)
+		require.NoError(t, err)
+		require.True(t, res.Data["quarantined"].(bool))
+		require.EqualValues(t, res.Data["current_epoch"].(phase0.Epoch)+2, res.Data["activation_epoch"])
+
+		_, err = request(t, storage, logical.CreateOperation, "accounts/sign", basicAttestationData())
+		require.ErrorIs(t, err, ErrAccountQuarantined)
+		require.EqualError(t, err, fmt.Sprintf("failed to sign: refused to sign until epoch %d: account is quarantined", res.Data["activation_epoch"]))
+
+		res, err = request(t, storage, logical.CreateOperation, "accounts/sign", signSlotData())
+		require.NoError(t, err)
+		require.NotEmpty(t, res.Data["signature"])
+	})
+
+	t.Run("signing is refused right after import", func(t *testing.T) {
+		storage := &logical.InmemStorage{}
+		setupBaseStorage(t, &logical.Request{Storage: storage}, withQuarantine)
+
+		// Sign requests never succeed while the account is imported
+		quarantined := false
+		importBaseStorage(t, b, &signingStorage{Storage: storage, sign: func() {
+			_, err := request(t, storage, logical.CreateOperation, "accounts/sign", basicAttestationData())
+			require.Error(t, err)
+			if errors.Is(err, ErrAccountQuarantined) {
+				quarantined = true
+			}
+		}})
+		require.True(t, quarantined)
+
+		_, err := request(t, storage, logical.CreateOperation, "accounts/sign", basicAttestationData())
+		require.ErrorIs(t, err, ErrAccountQuarantined)
+	})
+
+	t.Run("lift quarantine", func(t *testing.T) {
+		storage := &logical.InmemStorage{}
+		setupBaseStorage(t, &logical.Request{Storage: storage}, withQuarantine)
+		importBaseStorage(t, b, storage)
+
+		res, err := request(t, storage, logical.DeleteOperation, "accounts/"+basePubKey+"/quarantine", nil)
+		require.NoError(t, err)
+		require.False(t, res.Data["quarantined"].(bool))
+		require.NotNil(t, res.Data["lifted_at"])
+		require.Equal(t, "token:admin", res.Data["lifted_by"])
+
+		res, err = request(t, storage, logical.CreateOperation, "accounts/sign", basicAttestationData())
+		require.NoError(t, err)
+		require.NotEmpty(t, res.Data["signature"])
+	})
+
+	t.Run("existing account is not quarantined again", func(t *testing.T) {
+		storage := &logical.InmemStorage{}
+		setupBaseStorage(t, &logical.Request{Storage: storage}, withQuarantine)
+		importBaseStorage(t, b, storage)
+
+		_, err := request(t, storage, logical.DeleteOperation, "accounts/"+basePubKey+"/quarantine", nil)
+		require.NoError(t, err)
+		importBaseStorage(t, b, storage)
+
+		res, err := request(t, storage, logical.ReadOperation, "accounts/"+basePubKey+"/quarantine", nil)
+		require.NoError(t, err)
+		require.False(t, res.Data["quarantined"].(bool))
+	})
+
+	t.Run("no quarantine configured", func(t *testing.T) {
+		storage := &logical.InmemStorage{}
+		setupBaseStorage(t, &logical.Request{Storage: storage})
+		importBaseStorage(t, b, storage)
+
+		res, err := request(t, storage, logical.ReadOperation, "accounts/"+basePubKey+"/quarantine", nil)
+		require.NoError(t, err)
+		require.False(t, res.Data["quarantined"].(bool))
+		require.Nil(t, res.Data["activation_epoch"])
+
+		res, err = request(t, storage, logical.CreateOperation, "accounts/sign", basicAttestationData())
+		require.NoError(t, err)
+		require.NotEmpty(t, res.Data["signature"])
+	})
+
+	t.Run("lift without quarantine", func(t *testing.T) {
+		storage := &logical.InmemStorage{}
+		setupBaseStorage(t, &logical.Request{Storage: storage})
+		importBaseStorage(t, b, storage)
+
+		_, err := request(t, storage, logical.DeleteOperation, "accounts/"+basePubKey+"/quarantine", nil)
+		require.EqualError(t, err, "account 0x"+basePubKey+" is not quarantined")
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		storage := &logical.InmemStorage{}
+		setupBaseStorage(t, &logical.Request{Storage: storage}, withQuarantine)
+		importBaseStorage(t, b, storage)
+
+		_, err := request(t, storage, logical.ReadOperation, "accounts/95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcd/quarantine", nil)
+		require.EqualError(t, err, "account not found")
+	})
+}
+
+func TestConfigEpochAt(t *testing.T) {
+	config := Config{Network: core.PraterNetwork}
+	genesis := time.Unix(int64(core.PraterNetwork.MinGenesisTime()), 0)
+
+	require.EqualValues(t, 0, config.epochAt(genesis.Add(-time.Hour)))
+	require.EqualValues(t, 0, config.epochAt(genesis))
+	require.EqualValues(t, 0, config.epochAt(genesis.Add(383*time.Second)))
+	require.EqualValues(t, 1, config.epochAt(genesis.Add(384*time.Second)))
+
+	config.GenesisTime = uint64(genesis.Add(384 * time.Second).Unix())
+	require.EqualValues(t, 0, config.epochAt(genesis.Add(384*time.Second)))
+	require.EqualValues(t, 10, config.epochAt(genesis.Add(11*384*time.Second)))
+}
@@ -0,0 +1,187 @@
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/bloxapp/key-vault/backend/store"
+)
+
+// Endpoints patterns
+const (
+	// AccountStatusPattern is the path pattern suffix for account status endpoint, prefixed by accounts/<public key>
+	AccountStatusPattern = "/status"
+)
+
+// Account statuses
+const (
+	AccountStatusEnabled  = "enabled"
+	AccountStatusDisabled = "disabled"
+)
+
+// ErrAccountDisabled is returned when signing with a disabled account.
+var ErrAccountDisabled = errors.New("account is disabled")
+
+func accountsStatusPaths(b *backend) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         AccountsPattern + pubKeyRegex("public_key") + AccountStatusPattern,
+			HelpSynopsis:    "Manage the signing status of an account",
+			HelpDescription: `Enable or disable signing with an account, without deleting it`,
+			Fields: map[string]*framework.FieldSchema{
+				"public_key": {
+					Type:        framework.TypeString,
+					Description: "Hex encoded public key of the account",
+				},
+				"enabled": {
+					Type:        framework.TypeBool,
+					Description: "Whether the account signs",
+				},
+				"reason": {
+					Type:        framework.TypeString,
+					Description: "Reason of the change, required to disable the account",
+				},
+			},
+			ExistenceCheck: b.pathExistenceCheck,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathAccountStatusRead,
+				},
+				logical.CreateOperation: &framework.PathOperation{
+					Callback: b.pathAccountStatusWrite,
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.pathAccountStatusWrite,
+				},
+			},
+		},
+	}
+}
+
+func (b *backend) pathAccountStatusRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	pubKey, err := hexutil.Decode(ensureHexPrefix(data.Get("public_key").(string)))
+	if err != nil || len(pubKey) != BLSPubkeyLength {
+		return nil, errors.New("invalid public key provided")
+	}
+
+	// Load config
+	config, err := b.readConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get config")
+	}
+
+	storage, wallet, err := b.openWallet(ctx, req.Storage, config)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := wallet.AccountByPublicKey(hex.EncodeToString(pubKey)); err != nil {
+		return nil, err
+	}
+
+	status, _, err := storage.RetrieveAccountStatus(pubKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve account status")
+	}
+	return &logical.Response{
+		Data: accountStatusMap(pubKey, status),
+	}, nil
+}
+
+func (b *backend) pathAccountStatusWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	identity, err := requesterIdentity(req)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey, err := hexutil.Decode(ensureHexPrefix(data.Get("public_key").(string)))
+	if err != nil || len(pubKey) != BLSPubkeyLength {
+		return nil, errors.New("invalid public key provided")
+	}
+	enabled, ok := data.GetOk("enabled")
+	if !ok {
+		return nil, errors.New("enabled is required")
+	}
+	reason := strings.TrimSpace(data.Get("reason").(string))
+	if !enabled.(bool) && reason == "" {
+		return nil, errors.New("reason is required to disable an account")
+	}
+
+	// Load config
+	config, err := b.readConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get config")
+	}
+
+	storage, wallet, err := b.openWallet(ctx, req.Storage, config)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &store.AccountStatus{
+		Enabled:   enabled.(bool),
+		Reason:    reason,
+		ChangedBy: identity,
+		ChangedAt: b.now(),
+	}
+	// Changes are made under the public key lock, so no sign request of a disabled account is in flight once it returns.
+	err = b.lock(wallet, pubKey, func() error {
+		if err := storage.SaveAccountStatus(pubKey, status); err != nil {
+			return errors.Wrap(err, "failed to save account status")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b.logger.WithFields(logrus.Fields{
+		"public_key": hexutil.Encode(pubKey),
+		"enabled":    status.Enabled,
+		"reason":     status.Reason,
+		"changed_by": status.ChangedBy,
+	}).Info("Account status changed")
+	return &logical.Response{
+		Data: accountStatusMap(pubKey, status),
+	}, nil
+}
+
+// accountStatusMap returns a map representation of the status of the given public key, which may be nil.
+func accountStatusMap(pubKey []byte, status *store.AccountStatus) map[string]interface{} {
+	ret := map[string]interface{}{
+		"public_key": hexutil.Encode(pubKey),
+		"status":     accountStatusName(status),
+	}
+	if status != nil {
+		ret["reason"] = status.Reason
+		ret["changed_by"] = status.ChangedBy
+		ret["changed_at"] = status.ChangedAt
+	}
+	return ret
+}
+
+// accountStatusName returns the name of the given status, which may be nil.
+func accountStatusName(status *store.AccountStatus) string {
+	if status != nil && !status.Enabled {
+		return AccountStatusDisabled
+	}
+	return AccountStatusEnabled
+}
+
+// checkAccountEnabled refuses to sign with disabled accounts.
+func checkAccountEnabled(storage *store.HashicorpVaultStore, pubKey []byte) error {
+	status, found, err := storage.RetrieveAccountStatus(pubKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve account status")
+	}
+	if found && !status.Enabled {
+		return errors.Wrap(ErrAccountDisabled, "refused to sign")
+	}
+	return nil
+}
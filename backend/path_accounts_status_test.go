@@ -0,0 +1,162 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func setAccountStatus(t *testing.T, b logical.Backend, s logical.Storage, data map[string]interface{}) (*logical.Response, error) {
+	req := logical.TestRequest(t, logical.CreateOperation, "accounts/"+basePubKey+"/status")
+	req.Storage = s
+	req.EntityID = "admin"
+	req.Data = data
+	return b.HandleRequest(context.Background(), req)
+}
+
+func TestAccountStatus(t *testing.T) {
+	b, _ := getBackend(t)
+
+	t.Run("accounts are enabled by default", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.ReadOperation, "accounts/"+basePubKey+"/status")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, AccountStatusEnabled, res.Data["status"])
+		require.Nil(t, res.Data["reason"])
+	})
+
+	t.Run("disable and enable account", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		res, err := setAccountStatus(t, b, req.Storage, map[string]interface{}{
+			"enabled": false,
+			"reason":  "migration in progress",
+		})
+		require.NoError(t, err)
+		require.Equal(t, AccountStatusDisabled, res.Data["status"])
+		require.Equal(t, "migration in progress", res.Data["reason"])
+		require.Equal(t, "entity:admin", res.Data["changed_by"])
+
+		// Signing is refused
+		req.Data = basicAttestationData()
+		_, err = b.HandleRequest(context.Background(), req)
+		require.ErrorIs(t, err, ErrAccountDisabled)
+		require.EqualError(t, err, "failed to sign: refused to sign: account is disabled")
+
+		// Batch signing is refused with a distinct error type
+		batchReq := logical.TestRequest(t, logical.CreateOperation, "accounts/sign-batch")
+		batchReq.Storage = req.Storage
+		batchReq.Data = map[string]interface{}{
+			"sign_reqs": []string{basicAttestationData()["sign_req"].(string)},
+		}
+		res, err = b.HandleRequest(context.Background(), batchReq)
+		require.NoError(t, err)
+		require.Equal(t, SignErrorTypeAccountDisabled, res.Data["results"].([]map[string]interface{})[0]["error_type"])
+
+		// The status is listed
+		listReq := logical.TestRequest(t, logical.ListOperation, "accounts/")
+		listReq.Storage = req.Storage
+		res, err = b.HandleRequest(context.Background(), listReq)
+		require.NoError(t, err)
+		require.Equal(t, AccountStatusDisabled, res.Data["accounts"].([]map[string]string)[0]["status"])
+
+		// Signing again once enabled
+		res, err = setAccountStatus(t, b, req.Storage, map[string]interface{}{
+			"enabled": true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, AccountStatusEnabled, res.Data["status"])
+
+		res, err = b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.NotEmpty(t, res.Data["signature"])
+	})
+
+	t.Run("voluntary exit of disabled account", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign-voluntary-exit")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		approveVoluntaryExit(t, b, req.Storage, "0x"+basePubKey, 1)
+		_, err := setAccountStatus(t, b, req.Storage, map[string]interface{}{
+			"enabled": false,
+			"reason":  "suspected compromise",
+		})
+		require.NoError(t, err)
+
+		req.Data = basicVoluntaryExitData(false)
+		_, err = b.HandleRequest(context.Background(), req)
+		require.ErrorIs(t, err, ErrAccountDisabled)
+	})
+
+	t.Run("disable without reason", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		_, err := setAccountStatus(t, b, req.Storage, map[string]interface{}{
+			"enabled": false,
+		})
+		require.EqualError(t, err, "reason is required to disable an account")
+	})
+
+	t.Run("status without enabled", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		_, err := setAccountStatus(t, b, req.Storage, map[string]interface{}{
+			"reason": "no reason",
+		})
+		require.EqualError(t, err, "enabled is required")
+	})
+
+	t.Run("status of unknown account", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcd/status")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+		req.EntityID = "admin"
+		req.Data = map[string]interface{}{
+			"enabled": false,
+			"reason":  "exited",
+		}
+
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "account not found")
+	})
+
+	t.Run("token without entity", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/"+basePubKey+"/status")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+		req.DisplayName = "root"
+		req.Data = map[string]interface{}{
+			"enabled": false,
+			"reason":  "exited",
+		}
+
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, "token:root", res.Data["changed_by"])
+	})
+
+	t.Run("status without requester identity", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/"+basePubKey+"/status")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+		req.Data = map[string]interface{}{
+			"enabled": false,
+			"reason":  "exited",
+		}
+
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, ErrRequesterIdentity.Error())
+	})
+}
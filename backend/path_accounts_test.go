@@ -6,12 +6,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/bloxapp/eth2-key-manager/core"
 	log "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/sdk/helper/logging"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
+
+	"github.com/bloxapp/key-vault/backend/store"
 )
 
 func getBackend(t *testing.T) (logical.Backend, logical.Storage) {
@@ -33,6 +36,9 @@ func getBackend(t *testing.T) (logical.Backend, logical.Storage) {
 	return b, config.StorageView
 }
 
+// basePubKey is the hex encoded public key of the account of the base storage.
+const basePubKey = "95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcf"
+
 func setupBaseStorage(t *testing.T, req *logical.Request, configModifiers ...func(*Config)) {
 	cfg := Config{
 		Network:       core.PraterNetwork,
@@ -69,6 +75,161 @@ func TestAccountsList(t *testing.T) {
 			keys = append(keys, k)
 		}
 		sort.Strings(keys)
-		require.Equal(t, keys, []string{"id", "name", "validationPubKey", "withdrawalPubKey"})
+		require.Equal(t, keys, []string{"id", "name", "status", "validationPubKey", "withdrawalPubKey"})
+		require.Nil(t, res.Data["next_cursor"])
+	})
+
+	t.Run("List Accounts by pages", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.ListOperation, "accounts/")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+		storage := store.NewHashicorpVaultStore(context.Background(), req.Storage, core.PraterNetwork)
+		wallet, err := openStoredWallet(storage)
+		require.NoError(t, err)
+		for i := 0; i < 4; i++ {
+			_, err := wallet.CreateValidatorAccount(_byteArray("0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1fff"), nil)
+			require.NoError(t, err)
+		}
+
+		req.Data = map[string]interface{}{"limit": 2}
+		var pubKeys []string
+		for pages := 1; ; pages++ {
+			res, err := b.HandleRequest(context.Background(), req)
+			require.NoError(t, err)
+			for _, a := range res.Data["accounts"].([]map[string]string) {
+				pubKeys = append(pubKeys, a["validationPubKey"])
+			}
+			if res.Data["next_cursor"] == nil {
+				require.Equal(t, 3, pages)
+				break
+			}
+			require.Len(t, res.Data["accounts"], 2)
+			req.Data["after"] = res.Data["next_cursor"]
+		}
+		require.Len(t, pubKeys, 5)
+		require.True(t, sort.StringsAreSorted(pubKeys))
+
+		// Filters
+		req.Data = map[string]interface{}{"name": "account-[12]"}
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Len(t, res.Data["accounts"], 2)
+
+		req.Data = map[string]interface{}{"public_key_prefix": "0x" + basePubKey[:5]}
+		res, err = b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Len(t, res.Data["accounts"], 1)
+		require.Equal(t, basePubKey, res.Data["accounts"].([]map[string]string)[0]["validationPubKey"])
+
+		_, err = setAccountStatus(t, b, req.Storage, map[string]interface{}{
+			"enabled": false,
+			"reason":  "migration in progress",
+		})
+		require.NoError(t, err)
+		req.Data = map[string]interface{}{"status": AccountStatusEnabled}
+		res, err = b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Len(t, res.Data["accounts"], 4)
+		req.Data = map[string]interface{}{"status": AccountStatusDisabled, "limit": 1}
+		res, err = b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Len(t, res.Data["accounts"], 1)
+		require.Nil(t, res.Data["next_cursor"])
+	})
+
+	t.Run("List Accounts with invalid filters", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.ListOperation, "accounts/")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data = map[string]interface{}{"limit": MaxAccountsListLimit + 1}
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "invalid limit 1001 provided, must be at most 1000")
+
+		req.Data = map[string]interface{}{"public_key_prefix": "0xzz"}
+		_, err = b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, `invalid public key prefix "0xzz" provided`)
+
+		req.Data = map[string]interface{}{"name": "account-["}
+		_, err = b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, `invalid name pattern "account-[" provided`)
+
+		req.Data = map[string]interface{}{"status": "exited"}
+		_, err = b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, `invalid status "exited" provided`)
+	})
+}
+
+func TestAccountRead(t *testing.T) {
+	b, _ := getBackend(t)
+
+	t.Run("Successfully Read Account", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.ReadOperation, "accounts/0x"+basePubKey)
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, "account-0", res.Data["name"])
+		require.NotEmpty(t, res.Data["id"])
+		require.Equal(t, 0, res.Data["index"])
+		require.Equal(t, "m/12381/3600/0/0/0", res.Data["validation_path"])
+		require.Equal(t, "0x"+basePubKey, res.Data["public_key"])
+		require.Len(t, res.Data["withdrawal_public_key"], 98)
+		require.Equal(t, AccountStatusEnabled, res.Data["status"])
+		require.Equal(t, "0x6a3f3eE924A940ce0d795C5A41A817607e520520", res.Data["fee_recipient"])
+		require.Equal(t, map[string]interface{}{
+			"source_epoch": phase0.Epoch(0),
+			"target_epoch": phase0.Epoch(0),
+			"signing_root": "",
+		}, res.Data["highest_attestation"])
+		require.Equal(t, map[string]interface{}{
+			"slot":         phase0.Slot(1),
+			"signing_root": "",
+		}, res.Data["highest_proposal"])
+
+		// The signing root of signed objects is returned
+		signReq := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		signReq.Storage = req.Storage
+		signReq.Data = basicAttestationData()
+		signRes, err := b.HandleRequest(context.Background(), signReq)
+		require.NoError(t, err)
+
+		res, err = b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{
+			"source_epoch": phase0.Epoch(77),
+			"target_epoch": phase0.Epoch(78),
+			"signing_root": "0x" + signRes.Data["signing_root"].(string),
+		}, res.Data["highest_attestation"])
+	})
+
+	t.Run("Read Account without fee recipient and slashing protection", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.ReadOperation, "accounts/"+basePubKey)
+		setupBaseStorage(t, req, func(c *Config) {
+			c.FeeRecipients = nil
+		})
+		storage, err := baseHashicorpStorage(context.Background(), req.Storage)
+		require.NoError(t, err)
+		require.NoError(t, req.Storage.Delete(context.Background(), "highestAttestations/"+basePubKey))
+		require.NoError(t, req.Storage.Delete(context.Background(), "proposals/"+basePubKey))
+		_, found, err := storage.RetrieveHighestProposal(_byteArray(basePubKey))
+		require.NoError(t, err)
+		require.False(t, found)
+
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Nil(t, res.Data["fee_recipient"])
+		require.Nil(t, res.Data["highest_attestation"])
+		require.Nil(t, res.Data["highest_proposal"])
+	})
+
+	t.Run("Read unknown Account", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.ReadOperation, "accounts/95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcd")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "account not found")
 	})
 }
@@ -3,7 +3,12 @@ package backend
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
 
+	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/bloxapp/eth2-key-manager/core"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -27,16 +32,96 @@ const (
 
 // Config contains the configuration for each mount
 type Config struct {
-	Network       core.Network  `json:"network"`
-	FeeRecipients FeeRecipients `json:"fee_recipients"`
+	Network               core.Network        `json:"network"`
+	FeeRecipients         FeeRecipients       `json:"fee_recipients"`
+	WithdrawalAddresses   WithdrawalAddresses `json:"withdrawal_addresses"`
+	GenesisValidatorsRoot phase0.Root         `json:"genesis_validators_root"`
+	ForkSchedule          ForkSchedule        `json:"fork_schedule"`
+
+	// AttestationHistory enables the full attestation history slashing protection,
+	// keeping AttestationHistoryEpochs epochs of signed attestations, or all of them when 0.
+	AttestationHistory       bool   `json:"attestation_history"`
+	AttestationHistoryEpochs uint64 `json:"attestation_history_epochs"`
+
+	// GenesisTime and SlotDuration (in seconds) compute the current slot and epoch, SlotsPerEpoch the epoch of slots.
+	// The ones of the network are used when 0.
+	GenesisTime   uint64 `json:"genesis_time"`
+	SlotDuration  uint64 `json:"slot_duration"`
+	SlotsPerEpoch uint64 `json:"slots_per_epoch"`
+
+	// QuarantineEpochs is the number of epochs newly imported accounts don't sign attestations and blocks for.
+	QuarantineEpochs uint64 `json:"quarantine_epochs"`
+
+	// AttestationEpochTolerance and ProposalSlotTolerance are the max distance of the target epoch of attestations
+	// and the slot of blocks from the current epoch and slot, 0 doesn't limit them.
+	AttestationEpochTolerance uint64 `json:"attestation_epoch_tolerance"`
+	ProposalSlotTolerance     uint64 `json:"proposal_slot_tolerance"`
 }
 
 // Map returns a map representation of the FeeRecipients.
 func (c Config) Map() map[string]interface{} {
 	return map[string]interface{}{
-		"network":        c.Network,
-		"fee_recipients": c.FeeRecipients,
+		"network":                     c.Network,
+		"fee_recipients":              c.FeeRecipients,
+		"withdrawal_addresses":        c.WithdrawalAddresses,
+		"genesis_validators_root":     c.GenesisValidatorsRoot,
+		"fork_schedule":               c.ForkSchedule,
+		"attestation_history":         c.AttestationHistory,
+		"attestation_history_epochs":  c.AttestationHistoryEpochs,
+		"genesis_time":                c.GenesisTime,
+		"slot_duration":               c.SlotDuration,
+		"slots_per_epoch":             c.SlotsPerEpoch,
+		"quarantine_epochs":           c.QuarantineEpochs,
+		"attestation_epoch_tolerance": c.AttestationEpochTolerance,
+		"proposal_slot_tolerance":     c.ProposalSlotTolerance,
+	}
+}
+
+// genesisValidatorsRoot returns the configured genesis validators root, or the one of the network.
+func (c Config) genesisValidatorsRoot() phase0.Root {
+	if !c.GenesisValidatorsRoot.IsZero() {
+		return c.GenesisValidatorsRoot
 	}
+	return c.Network.GenesisValidatorsRoot()
+}
+
+// genesisForkVersion returns the genesis fork version of the configured fork schedule, or the one of the network.
+func (c Config) genesisForkVersion() phase0.Version {
+	if len(c.ForkSchedule) > 0 {
+		return c.ForkSchedule[0].Version
+	}
+	return c.Network.GenesisForkVersion()
+}
+
+// slotAt returns the slot at the given time, computed from the configured genesis time and slot duration
+// or the ones of the network.
+func (c Config) slotAt(t time.Time) phase0.Slot {
+	genesis := c.GenesisTime
+	if genesis == 0 {
+		genesis = c.Network.MinGenesisTime()
+	}
+	slotDuration := c.SlotDuration
+	if slotDuration == 0 {
+		slotDuration = uint64(c.Network.SlotDurationSec().Seconds())
+	}
+	if t.Unix() < int64(genesis) {
+		return 0
+	}
+	return phase0.Slot(uint64(t.Unix()-int64(genesis)) / slotDuration)
+}
+
+// epochAt returns the epoch at the given time.
+func (c Config) epochAt(t time.Time) phase0.Epoch {
+	return c.epochAtSlot(c.slotAt(t))
+}
+
+// epochAtSlot returns the epoch of the given slot, computed from the configured slots per epoch or the ones of the network.
+func (c Config) epochAtSlot(slot phase0.Slot) phase0.Epoch {
+	slotsPerEpoch := c.SlotsPerEpoch
+	if slotsPerEpoch == 0 {
+		slotsPerEpoch = c.Network.SlotsPerEpoch()
+	}
+	return phase0.Epoch(uint64(slot) / slotsPerEpoch)
 }
 
 func configPaths(b *backend) []*framework.Path {
@@ -55,7 +140,7 @@ func configPaths(b *backend) []*framework.Path {
 				},
 			},
 			HelpSynopsis:    "Configure the Vault Ethereum plugin.",
-			HelpDescription: "Configure the Vault Ethereum plugin.",
+			HelpDescription: "Configure the Vault Ethereum plugin. Signature domains are only validated once fork_schedule and genesis_validators_root are set.",
 			Fields: map[string]*framework.FieldSchema{
 				"network": {
 					Type: framework.TypeString,
@@ -71,12 +156,58 @@ func configPaths(b *backend) []*framework.Path {
 					Type:        framework.TypeMap,
 					Description: `Validator pubic keys and their associated fee recipient addresses.`,
 				},
+				"withdrawal_addresses": {
+					Type:        framework.TypeMap,
+					Description: `Withdrawal BLS public keys and the execution addresses they may be changed to.`,
+				},
+				"genesis_validators_root": {
+					Type:        framework.TypeString,
+					Description: `Genesis validators root of the network, required with fork_schedule.`,
+				},
+				"fork_schedule": {
+					Type: framework.TypeMap,
+					Description: `Fork versions of the network and their activation epochs.
+					When set, the signature domain of every sign request is validated against it. Domains aren't validated without it.`,
+				},
+				"attestation_history": {
+					Type: framework.TypeBool,
+					Description: `Record every signed attestation and check double and surround votes against them,
+					instead of refusing any attestation below the highest signed one.`,
+				},
+				"attestation_history_epochs": {
+					Type:        framework.TypeInt,
+					Description: `Number of epochs of attestation history to keep, 0 keeps all of it.`,
+				},
+				"genesis_time": {
+					Type:        framework.TypeInt,
+					Description: `Genesis time of the network in unix seconds, defaults to the genesis time of the network.`,
+				},
+				"slot_duration": {
+					Type:        framework.TypeInt,
+					Description: `Slot duration of the network in seconds, defaults to the slot duration of the network.`,
+				},
+				"slots_per_epoch": {
+					Type:        framework.TypeInt,
+					Description: `Number of slots in an epoch, defaults to the slots per epoch of the network.`,
+				},
+				"quarantine_epochs": {
+					Type:        framework.TypeInt,
+					Description: `Number of epochs newly imported accounts don't sign attestations and blocks for, 0 disables the quarantine.`,
+				},
+				"attestation_epoch_tolerance": {
+					Type:        framework.TypeInt,
+					Description: `Max distance of the target epoch of attestations from the current epoch, 0 doesn't limit it.`,
+				},
+				"proposal_slot_tolerance": {
+					Type:        framework.TypeInt,
+					Description: `Max distance of the slot of blocks from the current slot, 0 doesn't limit it.`,
+				},
 			},
 		},
 	}
 }
 
-// pathWriteConfig is the write config path handler
+// pathWriteConfig is the write config path handler, the fields which aren't given keep their stored value.
 func (b *backend) pathWriteConfig(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	network := core.NetworkFromString(data.Get("network").(string))
 	if network == "" {
@@ -96,6 +227,53 @@ func (b *backend) pathWriteConfig(ctx context.Context, req *logical.Request, dat
 		configBundle.FeeRecipients = recipients
 	}
 
+	// Parse and validate the withdrawal addresses (if given.)
+	if data, ok := data.Get("withdrawal_addresses").(map[string]interface{}); ok {
+		addresses, err := ParseWithdrawalAddresses(data)
+		if err != nil {
+			return nil, err
+		}
+		configBundle.WithdrawalAddresses = addresses
+	}
+
+	// Parse and validate the fork schedule (if given.)
+	if data, ok := data.Get("fork_schedule").(map[string]interface{}); ok && len(data) > 0 {
+		forkSchedule, err := ParseForkSchedule(data)
+		if err != nil {
+			return nil, err
+		}
+		configBundle.ForkSchedule = forkSchedule
+	}
+	if gvr := data.Get("genesis_validators_root").(string); gvr != "" {
+		root, err := hexutil.Decode(gvr)
+		if err != nil || len(root) != len(configBundle.GenesisValidatorsRoot) {
+			return nil, errors.New("invalid genesis_validators_root provided")
+		}
+		copy(configBundle.GenesisValidatorsRoot[:], root)
+	}
+	if len(configBundle.ForkSchedule) > 0 && configBundle.GenesisValidatorsRoot.IsZero() {
+		return nil, errors.New("genesis_validators_root is required with fork_schedule")
+	}
+
+	configBundle.AttestationHistory = data.Get("attestation_history").(bool)
+
+	// Parse the integer settings, all of them are non-negative.
+	for field, value := range map[string]*uint64{
+		"attestation_history_epochs":  &configBundle.AttestationHistoryEpochs,
+		"genesis_time":                &configBundle.GenesisTime,
+		"slot_duration":               &configBundle.SlotDuration,
+		"slots_per_epoch":             &configBundle.SlotsPerEpoch,
+		"quarantine_epochs":           &configBundle.QuarantineEpochs,
+		"attestation_epoch_tolerance": &configBundle.AttestationEpochTolerance,
+		"proposal_slot_tolerance":     &configBundle.ProposalSlotTolerance,
+	} {
+		v := data.Get(field).(int)
+		if v < 0 {
+			return nil, errors.Errorf("invalid %s provided", field)
+		}
+		*value = uint64(v)
+	}
+
 	// Create storage entry
 	entry, err := logical.StorageEntryJSON("config", configBundle.Map())
 	if err != nil {
@@ -211,3 +389,125 @@ func (f FeeRecipients) Get(pubKey []byte) (common.Address, bool) {
 	}
 	return common.HexToAddress(f[pubKeyHex]), true
 }
+
+// WithdrawalAddresses is a map of withdrawal BLS public keys and the execution addresses
+// their withdrawal credentials may be changed to.
+// Both the public key and the address are 0x-prefixed hex strings.
+type WithdrawalAddresses map[string]string
+
+// ParseWithdrawalAddresses parses & validates the withdrawal addresses from a given map[string]interface{}
+func ParseWithdrawalAddresses(input map[string]interface{}) (WithdrawalAddresses, error) {
+	withdrawalAddresses := WithdrawalAddresses{}
+	for key, value := range input {
+		withdrawalPubkey, err := hexutil.Decode(key)
+		if err != nil || len(withdrawalPubkey) != BLSPubkeyLength {
+			return nil, errors.Errorf("invalid withdrawal_addresses provided: invalid public key %q", key)
+		}
+
+		addrStr, _ := value.(string)
+		addr, err := hexutil.Decode(addrStr)
+		if err != nil || len(addr) != FeeRecipientLength {
+			return nil, errors.Errorf("invalid withdrawal_addresses provided: invalid address %q", addrStr)
+		}
+
+		withdrawalAddresses[hexutil.Encode(withdrawalPubkey)] = hexutil.Encode(addr)
+	}
+	return withdrawalAddresses, nil
+}
+
+// UnmarshalJSON decodes JSON-encoded WithdrawalAddresses with validation.
+func (w *WithdrawalAddresses) UnmarshalJSON(data []byte) error {
+	var input map[string]interface{}
+	if err := json.Unmarshal(data, &input); err != nil {
+		return err
+	}
+	withdrawalAddresses, err := ParseWithdrawalAddresses(input)
+	if err != nil {
+		return err
+	}
+	*w = withdrawalAddresses
+	return nil
+}
+
+// Get returns the allowed execution address for the given withdrawal public key.
+func (w WithdrawalAddresses) Get(withdrawalPubKey []byte) (common.Address, bool) {
+	addr, ok := w[hexutil.Encode(withdrawalPubKey)]
+	if !ok {
+		return common.Address{}, false
+	}
+	return common.HexToAddress(addr), true
+}
+
+// Fork is a scheduled fork of the network.
+type Fork struct {
+	Version phase0.Version
+	Epoch   phase0.Epoch
+}
+
+// ForkSchedule is the fork schedule of the network, ordered by activation epoch.
+type ForkSchedule []Fork
+
+// ParseForkSchedule parses & validates the fork schedule from a given map of fork versions to activation epochs.
+func ParseForkSchedule(input map[string]interface{}) (ForkSchedule, error) {
+	forkSchedule := make(ForkSchedule, 0, len(input))
+	for key, value := range input {
+		version, err := hexutil.Decode(key)
+		if err != nil || len(version) != phase0.ForkVersionLength {
+			return nil, errors.Errorf("invalid fork_schedule provided: invalid fork version %q", key)
+		}
+		epoch, err := strconv.ParseUint(fmt.Sprint(value), 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid fork_schedule provided")
+		}
+
+		fork := Fork{Epoch: phase0.Epoch(epoch)}
+		copy(fork.Version[:], version)
+		forkSchedule = append(forkSchedule, fork)
+	}
+
+	sort.Slice(forkSchedule, func(i, j int) bool {
+		return forkSchedule[i].Epoch < forkSchedule[j].Epoch
+	})
+	for i := 1; i < len(forkSchedule); i++ {
+		if forkSchedule[i].Epoch == forkSchedule[i-1].Epoch {
+			return nil, errors.Errorf("invalid fork_schedule provided: more than one fork at epoch %d", forkSchedule[i].Epoch)
+		}
+	}
+	if len(forkSchedule) > 0 && forkSchedule[0].Epoch != 0 {
+		return nil, errors.New("invalid fork_schedule provided: missing genesis fork")
+	}
+	return forkSchedule, nil
+}
+
+// MarshalJSON encodes the ForkSchedule as a map of fork versions to activation epochs.
+func (f ForkSchedule) MarshalJSON() ([]byte, error) {
+	output := make(map[string]uint64, len(f))
+	for _, fork := range f {
+		output[hexutil.Encode(fork.Version[:])] = uint64(fork.Epoch)
+	}
+	return json.Marshal(output)
+}
+
+// UnmarshalJSON decodes JSON-encoded ForkSchedule with validation.
+func (f *ForkSchedule) UnmarshalJSON(data []byte) error {
+	var input map[string]interface{}
+	if err := json.Unmarshal(data, &input); err != nil {
+		return err
+	}
+	forkSchedule, err := ParseForkSchedule(input)
+	if err != nil {
+		return err
+	}
+	*f = forkSchedule
+	return nil
+}
+
+// VersionAt returns the fork version active at the given epoch.
+func (f ForkSchedule) VersionAt(epoch phase0.Epoch) (phase0.Version, bool) {
+	for i := len(f) - 1; i >= 0; i-- {
+		if f[i].Epoch <= epoch {
+			return f[i].Version, true
+		}
+	}
+	return phase0.Version{}, false
+}
@@ -0,0 +1,92 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/bloxapp/eth2-key-manager/core"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteConfig(t *testing.T) {
+	b, _ := getBackend(t)
+	write := func(t *testing.T, s logical.Storage, data map[string]interface{}) (*logical.Response, error) {
+		req := logical.TestRequest(t, logical.UpdateOperation, "config")
+		req.Storage = s
+		req.Data = data
+		return b.HandleRequest(context.Background(), req)
+	}
+
+	t.Run("writes replace the config", func(t *testing.T) {
+		s := &logical.InmemStorage{}
+		res, err := write(t, s, map[string]interface{}{
+			"network":                    "prater",
+			"fee_recipients":             map[string]interface{}{"default": "0x6a3f3ee924a940ce0d795c5a41a817607e520520"},
+			"attestation_history":        true,
+			"attestation_history_epochs": 4096,
+			"quarantine_epochs":          3,
+		})
+		require.NoError(t, err)
+		require.Equal(t, FeeRecipients{"default": "0x6a3f3ee924a940ce0d795c5a41a817607e520520"}, res.Data["fee_recipients"])
+		require.Equal(t, true, res.Data["attestation_history"])
+		require.EqualValues(t, 4096, res.Data["attestation_history_epochs"])
+
+		config, err := b.(*backend).readConfig(context.Background(), s)
+		require.NoError(t, err)
+		require.True(t, config.AttestationHistory)
+		require.EqualValues(t, 4096, config.AttestationHistoryEpochs)
+		require.EqualValues(t, 3, config.QuarantineEpochs)
+
+		// Fields which are not given are cleared
+		_, err = write(t, s, map[string]interface{}{
+			"network": "prater",
+		})
+		require.NoError(t, err)
+		config, err = b.(*backend).readConfig(context.Background(), s)
+		require.NoError(t, err)
+		require.Equal(t, core.PraterNetwork, config.Network)
+		require.Empty(t, config.FeeRecipients)
+		require.False(t, config.AttestationHistory)
+		require.Zero(t, config.AttestationHistoryEpochs)
+		require.Zero(t, config.QuarantineEpochs)
+	})
+
+	t.Run("invalid attestation history epochs", func(t *testing.T) {
+		_, err := write(t, &logical.InmemStorage{}, map[string]interface{}{
+			"network":                    "prater",
+			"attestation_history_epochs": -1,
+		})
+		require.EqualError(t, err, "invalid attestation_history_epochs provided")
+	})
+
+	t.Run("network is required", func(t *testing.T) {
+		_, err := write(t, &logical.InmemStorage{}, map[string]interface{}{
+			"quarantine_epochs": 3,
+		})
+		require.EqualError(t, err, "invalid network provided")
+	})
+
+	t.Run("fork schedule requires a genesis validators root", func(t *testing.T) {
+		s := &logical.InmemStorage{}
+		config := &Config{}
+		withPraterForkSchedule(t)(config)
+		forkSchedule := make(map[string]interface{})
+		for _, fork := range config.ForkSchedule {
+			forkSchedule[fmt.Sprintf("%#x", fork.Version)] = uint64(fork.Epoch)
+		}
+		_, err := write(t, s, map[string]interface{}{
+			"network":                 "prater",
+			"fork_schedule":           forkSchedule,
+			"genesis_validators_root": fmt.Sprintf("%#x", config.GenesisValidatorsRoot),
+		})
+		require.NoError(t, err)
+
+		_, err = write(t, s, map[string]interface{}{
+			"network":       "prater",
+			"fork_schedule": forkSchedule,
+		})
+		require.EqualError(t, err, "genesis_validators_root is required with fork_schedule")
+	})
+}
@@ -0,0 +1,169 @@
+package backend
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/bloxapp/eth2-key-manager/signer"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+)
+
+// Endpoints patterns
+const (
+	// DepositDataPattern is the path pattern suffix for deposit data endpoint, prefixed by accounts/<public key>
+	DepositDataPattern = "/deposit-data"
+)
+
+// Withdrawal credentials types
+const (
+	WithdrawalCredentialsTypeBLS       = "bls"
+	WithdrawalCredentialsTypeExecution = "execution"
+)
+
+const (
+	// blsWithdrawalPrefix is the withdrawal credentials prefix of a BLS withdrawal public key.
+	blsWithdrawalPrefix = byte(0x00)
+
+	// eth1AddressWithdrawalPrefix is the withdrawal credentials prefix of an execution address.
+	eth1AddressWithdrawalPrefix = byte(0x01)
+
+	// minDepositAmount is the minimum deposit amount accepted by the deposit contract.
+	minDepositAmount = phase0.Gwei(1000000000)
+
+	// maxDepositAmount is the max effective balance of a validator.
+	maxDepositAmount = phase0.Gwei(32000000000)
+)
+
+func depositDataPaths(b *backend) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         AccountsPattern + pubKeyRegex("public_key") + DepositDataPattern,
+			HelpSynopsis:    "Generate deposit data",
+			HelpDescription: `Sign the deposit message of the given account and return a deposit_data-*.json compatible entry`,
+			Fields: map[string]*framework.FieldSchema{
+				"public_key": {
+					Type:        framework.TypeString,
+					Description: "Hex encoded public key of the account to deposit",
+				},
+				"amount": {
+					Type:        framework.TypeInt,
+					Description: "Deposit amount in Gwei, defaults to 32 ETH",
+					Default:     int(maxDepositAmount),
+				},
+				"withdrawal_credentials_type": {
+					Type:          framework.TypeString,
+					Description:   "Withdrawal credentials type, bls (0x00) uses the account withdrawal public key and execution (0x01) uses withdrawal_address",
+					Default:       WithdrawalCredentialsTypeBLS,
+					AllowedValues: []interface{}{WithdrawalCredentialsTypeBLS, WithdrawalCredentialsTypeExecution},
+				},
+				"withdrawal_address": {
+					Type:        framework.TypeString,
+					Description: "Execution address of execution withdrawal credentials",
+				},
+			},
+			ExistenceCheck: b.pathExistenceCheck,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.CreateOperation: &framework.PathOperation{
+					Callback: b.pathDepositData,
+				},
+			},
+		},
+	}
+}
+
+func (b *backend) pathDepositData(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	pubKey, err := hexutil.Decode(ensureHexPrefix(data.Get("public_key").(string)))
+	if err != nil || len(pubKey) != BLSPubkeyLength {
+		return nil, errors.New("invalid public key provided")
+	}
+	amount := data.Get("amount").(int)
+	if amount < int(minDepositAmount) || amount > int(maxDepositAmount) {
+		return nil, errors.Errorf("invalid amount provided, must be between %d and %d Gwei", minDepositAmount, maxDepositAmount)
+	}
+
+	// Load config
+	config, err := b.readConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get config")
+	}
+
+	_, wallet, err := b.openWallet(ctx, req.Storage, config)
+	if err != nil {
+		return nil, err
+	}
+	account, err := wallet.AccountByPublicKey(hex.EncodeToString(pubKey))
+	if err != nil {
+		return nil, err
+	}
+
+	var withdrawalCredentials []byte
+	switch data.Get("withdrawal_credentials_type").(string) {
+	case WithdrawalCredentialsTypeBLS:
+		h := sha256.Sum256(account.WithdrawalPublicKey())
+		withdrawalCredentials = append([]byte{blsWithdrawalPrefix}, h[1:]...)
+	case WithdrawalCredentialsTypeExecution:
+		addr, err := hexutil.Decode(data.Get("withdrawal_address").(string))
+		if err != nil || len(addr) != FeeRecipientLength {
+			return nil, errors.New("invalid withdrawal_address provided")
+		}
+		withdrawalCredentials = make([]byte, 32)
+		withdrawalCredentials[0] = eth1AddressWithdrawalPrefix
+		copy(withdrawalCredentials[12:], addr)
+	default:
+		return nil, errors.New("invalid withdrawal_credentials_type provided")
+	}
+
+	depositMessage := &phase0.DepositMessage{
+		WithdrawalCredentials: withdrawalCredentials,
+		Amount:                phase0.Gwei(amount),
+	}
+	copy(depositMessage.PublicKey[:], account.ValidatorPublicKey())
+	depositMessageRoot, err := depositMessage.HashTreeRoot()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compute deposit message root")
+	}
+
+	// Deposits are signed with the genesis fork version and an empty genesis validators root.
+	forkVersion := config.genesisForkVersion()
+	domain, err := computeDomain(DomainDeposit, forkVersion, phase0.Root{})
+	if err != nil {
+		return nil, err
+	}
+	root, err := signer.ComputeETHSigningRoot(depositMessage, domain)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compute signing root")
+	}
+	sig, err := account.ValidationKeySign(root[:])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign deposit message")
+	}
+
+	depositData := &phase0.DepositData{
+		PublicKey:             depositMessage.PublicKey,
+		WithdrawalCredentials: depositMessage.WithdrawalCredentials,
+		Amount:                depositMessage.Amount,
+	}
+	copy(depositData.Signature[:], sig)
+	depositDataRoot, err := depositData.HashTreeRoot()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compute deposit data root")
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"pubkey":                 hex.EncodeToString(depositData.PublicKey[:]),
+			"withdrawal_credentials": hex.EncodeToString(depositData.WithdrawalCredentials),
+			"amount":                 uint64(depositData.Amount),
+			"signature":              hex.EncodeToString(depositData.Signature[:]),
+			"deposit_message_root":   hex.EncodeToString(depositMessageRoot[:]),
+			"deposit_data_root":      hex.EncodeToString(depositDataRoot[:]),
+			"fork_version":           hex.EncodeToString(forkVersion[:]),
+			"network_name":           string(config.Network),
+		},
+	}, nil
+}
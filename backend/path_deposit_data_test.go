@@ -0,0 +1,107 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/bloxapp/eth2-key-manager/core"
+	"github.com/bloxapp/eth2-key-manager/signer"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/herumi/bls-eth-go-binary/bls"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bloxapp/key-vault/backend/store"
+)
+
+const testDepositDataPath = "accounts/0x95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcf/deposit-data"
+
+func TestDepositData(t *testing.T) {
+	b, _ := getBackend(t)
+
+	t.Run("Successfully generate bls deposit data", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, testDepositDataPath)
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+
+		// Compare with the deposit data generated by the key manager
+		wallet, err := store.NewHashicorpVaultStore(context.Background(), req.Storage, core.PraterNetwork).OpenWallet()
+		require.NoError(t, err)
+		expected, err := wallet.Accounts()[0].GetDepositData()
+		require.NoError(t, err)
+
+		require.Equal(t, expected["publicKey"], res.Data["pubkey"])
+		require.Equal(t, expected["withdrawalCredentials"], res.Data["withdrawal_credentials"])
+		require.EqualValues(t, expected["amount"], res.Data["amount"])
+		require.Equal(t, expected["signature"], res.Data["signature"])
+		require.Equal(t, expected["depositDataRoot"], res.Data["deposit_data_root"])
+		require.Equal(t, "00001020", res.Data["fork_version"])
+		require.Equal(t, "prater", res.Data["network_name"])
+		require.Len(t, res.Data["deposit_message_root"], 64)
+	})
+
+	t.Run("Successfully generate execution deposit data", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, testDepositDataPath)
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data = map[string]interface{}{
+			"amount":                      1000000000,
+			"withdrawal_credentials_type": "execution",
+			"withdrawal_address":          "0x6a3f3ee924a940ce0d795c5a41a817607e520520",
+		}
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, "0100000000000000000000006a3f3ee924a940ce0d795c5a41a817607e520520", res.Data["withdrawal_credentials"])
+		require.EqualValues(t, 1000000000, res.Data["amount"])
+
+		// Verify the signature under the deposit domain
+		messageRoot := _byteArray32(res.Data["deposit_message_root"].(string))
+		domain, err := computeDomain(DomainDeposit, core.PraterNetwork.GenesisForkVersion(), phase0.Root{})
+		require.NoError(t, err)
+		root, err := signer.ComputeETHSigningRoot(signer.SSZBytes(messageRoot[:]), domain)
+		require.NoError(t, err)
+
+		pubKey := &bls.PublicKey{}
+		require.NoError(t, pubKey.DeserializeHexStr(res.Data["pubkey"].(string)))
+		sig := &bls.Sign{}
+		require.NoError(t, sig.DeserializeHexStr(res.Data["signature"].(string)))
+		require.True(t, sig.VerifyByte(pubKey, root[:]))
+	})
+
+	t.Run("Generate deposit data with invalid amount", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, testDepositDataPath)
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data = map[string]interface{}{
+			"amount": 999999999,
+		}
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "invalid amount provided, must be between 1000000000 and 32000000000 Gwei")
+	})
+
+	t.Run("Generate execution deposit data without withdrawal address", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, testDepositDataPath)
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data = map[string]interface{}{
+			"withdrawal_credentials_type": "execution",
+		}
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "invalid withdrawal_address provided")
+	})
+
+	t.Run("Generate deposit data of unknown account", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcd/deposit-data")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "account not found")
+	})
+}
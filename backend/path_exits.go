@@ -0,0 +1,431 @@
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/uuid"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+)
+
+// Endpoints patterns
+const (
+	// ExitsPattern is the path pattern for list voluntary exit requests endpoint
+	ExitsPattern = "accounts/exits/"
+
+	// ExitProposePattern is the path pattern for propose voluntary exit endpoint
+	ExitProposePattern = "accounts/exits/propose"
+
+	// ExitApprovePattern is the path pattern for approve voluntary exit endpoint
+	ExitApprovePattern = "accounts/exits/approve"
+)
+
+const (
+	// exitRequestsPrefix is the storage prefix of voluntary exit requests
+	exitRequestsPrefix = "exit_requests/"
+
+	// exitApprovalPath is the storage path of the ID of the approved voluntary exit request of a public key and epoch
+	exitApprovalPath = "exit_approvals/%s/%d"
+
+	// defaultExitRequestTTL is the default time a voluntary exit request is valid for
+	defaultExitRequestTTL = 24 * time.Hour
+
+	// exitRequestRetention is the time completed and expired voluntary exit requests are kept for audit
+	exitRequestRetention = 30 * 24 * time.Hour
+)
+
+// ExitRequestStatus is the status of a voluntary exit request.
+type ExitRequestStatus string
+
+// Voluntary exit request statuses
+const (
+	ExitRequestPending   ExitRequestStatus = "pending"
+	ExitRequestApproved  ExitRequestStatus = "approved"
+	ExitRequestCompleted ExitRequestStatus = "completed"
+	ExitRequestExpired   ExitRequestStatus = "expired"
+)
+
+var (
+	// ErrExitNotApproved is returned when a voluntary exit is signed without a matching approved request.
+	ErrExitNotApproved = errors.New("no approved voluntary exit request")
+
+	// ErrExitSelfApproval is returned when a voluntary exit request is approved by its proposer.
+	ErrExitSelfApproval = errors.New("voluntary exit request can not be approved by its proposer")
+
+	// ErrExitRequesterEntity is returned when a voluntary exit is proposed or approved by a token without an identity entity.
+	ErrExitRequesterEntity = errors.New("requester identity entity is required")
+)
+
+// ExitRequest is a voluntary exit request, it has to be approved by a second person before the exit is signed.
+type ExitRequest struct {
+	ID          string            `json:"id"`
+	PublicKey   string            `json:"public_key"`
+	Epoch       phase0.Epoch      `json:"epoch"`
+	Status      ExitRequestStatus `json:"status"`
+	ProposedBy  string            `json:"proposed_by"`
+	ProposedAt  time.Time         `json:"proposed_at"`
+	ApprovedBy  string            `json:"approved_by,omitempty"`
+	ApprovedAt  *time.Time        `json:"approved_at,omitempty"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+	ExpiresAt   time.Time         `json:"expires_at"`
+}
+
+// endedAt returns the time the request was completed or expired at.
+func (r *ExitRequest) endedAt() time.Time {
+	if r.CompletedAt != nil {
+		return *r.CompletedAt
+	}
+	return r.ExpiresAt
+}
+
+// statusAt returns the status of the request at the given time.
+func (r *ExitRequest) statusAt(now time.Time) ExitRequestStatus {
+	if r.Status != ExitRequestCompleted && !now.Before(r.ExpiresAt) {
+		return ExitRequestExpired
+	}
+	return r.Status
+}
+
+// Map returns a map representation of the request at the given time.
+func (r *ExitRequest) Map(now time.Time) map[string]interface{} {
+	m := map[string]interface{}{
+		"id":          r.ID,
+		"public_key":  r.PublicKey,
+		"epoch":       uint64(r.Epoch),
+		"status":      r.statusAt(now),
+		"proposed_by": r.ProposedBy,
+		"proposed_at": r.ProposedAt,
+		"expires_at":  r.ExpiresAt,
+	}
+	if r.ApprovedAt != nil {
+		m["approved_by"] = r.ApprovedBy
+		m["approved_at"] = *r.ApprovedAt
+	}
+	if r.CompletedAt != nil {
+		m["completed_at"] = *r.CompletedAt
+	}
+	return m
+}
+
+func exitsPaths(b *backend) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         ExitsPattern,
+			HelpSynopsis:    "List voluntary exit requests",
+			HelpDescription: `List pending, approved, completed and expired voluntary exit requests`,
+			Fields:          map[string]*framework.FieldSchema{},
+			ExistenceCheck:  b.pathExistenceCheck,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.pathExitsList,
+				},
+			},
+		},
+		{
+			Pattern:         ExitProposePattern,
+			HelpSynopsis:    "Propose voluntary exit",
+			HelpDescription: `Propose a voluntary exit of the given public key at the given epoch, it must be approved by another identity before it is signed`,
+			Fields: map[string]*framework.FieldSchema{
+				"public_key": {
+					Type:        framework.TypeString,
+					Description: "Hex encoded public key of the account to exit",
+				},
+				"epoch": {
+					Type:        framework.TypeInt,
+					Description: "Epoch of the voluntary exit",
+				},
+				"ttl": {
+					Type:        framework.TypeDurationSecond,
+					Description: "Time the request is valid for, defaults to 24h",
+				},
+			},
+			ExistenceCheck: b.pathExistenceCheck,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.CreateOperation: &framework.PathOperation{
+					Callback: b.pathExitPropose,
+				},
+			},
+		},
+		{
+			Pattern:         ExitApprovePattern,
+			HelpSynopsis:    "Approve voluntary exit",
+			HelpDescription: `Approve a pending voluntary exit request, the approver must differ from the proposer`,
+			Fields: map[string]*framework.FieldSchema{
+				"id": {
+					Type:        framework.TypeString,
+					Description: "ID of the voluntary exit request",
+				},
+			},
+			ExistenceCheck: b.pathExistenceCheck,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.CreateOperation: &framework.PathOperation{
+					Callback: b.pathExitApprove,
+				},
+			},
+		},
+	}
+}
+
+func (b *backend) pathExitsList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	requests, err := listExitRequests(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	now := b.now()
+	keys := make([]string, 0, len(requests))
+	keyInfo := make(map[string]interface{}, len(requests))
+	for _, r := range requests {
+		keys = append(keys, r.ID)
+		keyInfo[r.ID] = r.Map(now)
+	}
+	return logical.ListResponseWithInfo(keys, keyInfo), nil
+}
+
+func (b *backend) pathExitPropose(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	identity, err := exitRequesterIdentity(req)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey, err := hexutil.Decode(ensureHexPrefix(data.Get("public_key").(string)))
+	if err != nil || len(pubKey) != BLSPubkeyLength {
+		return nil, errors.New("invalid public key provided")
+	}
+	epoch := data.Get("epoch").(int)
+	if epoch < 0 {
+		return nil, errors.New("invalid epoch provided")
+	}
+	ttl := defaultExitRequestTTL
+	if v, ok := data.GetOk("ttl"); ok {
+		ttl = time.Duration(v.(int)) * time.Second
+	}
+	if ttl <= 0 {
+		return nil, errors.New("invalid ttl provided")
+	}
+
+	// Load config
+	config, err := b.readConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get config")
+	}
+
+	// Make sure the account exists
+	_, wallet, err := b.openWallet(ctx, req.Storage, config)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := wallet.AccountByPublicKey(hex.EncodeToString(pubKey)); err != nil {
+		return nil, err
+	}
+
+	// Requests which ended long ago are pruned along the way
+	now := b.now()
+	if err := pruneExitRequests(ctx, req.Storage, now); err != nil {
+		return nil, err
+	}
+
+	exitReq := &ExitRequest{
+		ID:         uuid.New().String(),
+		PublicKey:  hexutil.Encode(pubKey),
+		Epoch:      phase0.Epoch(epoch),
+		Status:     ExitRequestPending,
+		ProposedBy: identity,
+		ProposedAt: now,
+		ExpiresAt:  now.Add(ttl),
+	}
+	if err := putExitRequest(ctx, req.Storage, exitReq); err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: exitReq.Map(now),
+	}, nil
+}
+
+func (b *backend) pathExitApprove(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	identity, err := exitRequesterIdentity(req)
+	if err != nil {
+		return nil, err
+	}
+
+	exitReq, err := getExitRequest(ctx, req.Storage, data.Get("id").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	now := b.now()
+	if status := exitReq.statusAt(now); status != ExitRequestPending {
+		return nil, errors.Errorf("voluntary exit request is %s", status)
+	}
+	if exitReq.ProposedBy == identity {
+		return nil, ErrExitSelfApproval
+	}
+
+	exitReq.Status = ExitRequestApproved
+	exitReq.ApprovedBy = identity
+	exitReq.ApprovedAt = &now
+	if err := putExitRequest(ctx, req.Storage, exitReq); err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, &logical.StorageEntry{Key: exitReq.approvalPath(), Value: []byte(exitReq.ID)}); err != nil {
+		return nil, errors.Wrap(err, "failed to store voluntary exit approval")
+	}
+
+	return &logical.Response{
+		Data: exitReq.Map(now),
+	}, nil
+}
+
+// approvalPath returns the storage path of the approval of the request.
+func (r *ExitRequest) approvalPath() string {
+	return fmt.Sprintf(exitApprovalPath, r.PublicKey, r.Epoch)
+}
+
+// approvedExitRequest returns the approved, unexpired voluntary exit request of the given public key and epoch.
+func approvedExitRequest(ctx context.Context, s logical.Storage, pubKey []byte, epoch phase0.Epoch, now time.Time) (*ExitRequest, error) {
+	entry, err := s.Get(ctx, fmt.Sprintf(exitApprovalPath, hexutil.Encode(pubKey), epoch))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get voluntary exit approval")
+	}
+	if entry == nil {
+		return nil, ErrExitNotApproved
+	}
+
+	exitReq, err := getExitRequest(ctx, s, string(entry.Value))
+	if err != nil {
+		return nil, err
+	}
+	if exitReq.statusAt(now) != ExitRequestApproved {
+		return nil, ErrExitNotApproved
+	}
+	return exitReq, nil
+}
+
+// completeExitRequest marks the given voluntary exit request as completed, so it can not be used again.
+func completeExitRequest(ctx context.Context, s logical.Storage, exitReq *ExitRequest, now time.Time) error {
+	exitReq.Status = ExitRequestCompleted
+	exitReq.CompletedAt = &now
+	if err := putExitRequest(ctx, s, exitReq); err != nil {
+		return err
+	}
+	return deleteExitApproval(ctx, s, exitReq)
+}
+
+// pruneExitRequests deletes the voluntary exit requests which were completed or expired
+// more than exitRequestRetention before the given time.
+func pruneExitRequests(ctx context.Context, s logical.Storage, now time.Time) error {
+	requests, err := listExitRequests(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range requests {
+		if status := r.statusAt(now); status != ExitRequestCompleted && status != ExitRequestExpired {
+			continue
+		}
+		if now.Before(r.endedAt().Add(exitRequestRetention)) {
+			continue
+		}
+		if err := deleteExitApproval(ctx, s, r); err != nil {
+			return err
+		}
+		if err := s.Delete(ctx, exitRequestsPrefix+r.ID); err != nil {
+			return errors.Wrap(err, "failed to delete voluntary exit request")
+		}
+	}
+	return nil
+}
+
+// deleteExitApproval deletes the approval of the given request, unless another request of the same public key
+// and epoch was approved since.
+func deleteExitApproval(ctx context.Context, s logical.Storage, exitReq *ExitRequest) error {
+	entry, err := s.Get(ctx, exitReq.approvalPath())
+	if err != nil {
+		return errors.Wrap(err, "failed to get voluntary exit approval")
+	}
+	if entry == nil || string(entry.Value) != exitReq.ID {
+		return nil
+	}
+	if err := s.Delete(ctx, exitReq.approvalPath()); err != nil {
+		return errors.Wrap(err, "failed to delete voluntary exit approval")
+	}
+	return nil
+}
+
+func getExitRequest(ctx context.Context, s logical.Storage, id string) (*ExitRequest, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, errors.New("invalid voluntary exit request id")
+	}
+
+	entry, err := s.Get(ctx, exitRequestsPrefix+id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get voluntary exit request")
+	}
+	if entry == nil {
+		return nil, errors.New("voluntary exit request not found")
+	}
+
+	var exitReq ExitRequest
+	if err := entry.DecodeJSON(&exitReq); err != nil {
+		return nil, errors.Wrap(err, "failed to decode voluntary exit request")
+	}
+	return &exitReq, nil
+}
+
+func putExitRequest(ctx context.Context, s logical.Storage, exitReq *ExitRequest) error {
+	entry, err := logical.StorageEntryJSON(exitRequestsPrefix+exitReq.ID, exitReq)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode voluntary exit request")
+	}
+	if err := s.Put(ctx, entry); err != nil {
+		return errors.Wrap(err, "failed to store voluntary exit request")
+	}
+	return nil
+}
+
+// listExitRequests returns all voluntary exit requests ordered by proposal time.
+func listExitRequests(ctx context.Context, s logical.Storage) ([]*ExitRequest, error) {
+	ids, err := s.List(ctx, exitRequestsPrefix)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list voluntary exit requests")
+	}
+
+	requests := make([]*ExitRequest, 0, len(ids))
+	for _, id := range ids {
+		exitReq, err := getExitRequest(ctx, s, id)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, exitReq)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].ProposedAt.Before(requests[j].ProposedAt)
+	})
+	return requests, nil
+}
+
+// exitRequesterIdentity returns the identity of the requester, used to tell the proposer and the approver apart.
+// Only identity entities are trusted: token display names are chosen by whoever creates the token.
+func exitRequesterIdentity(req *logical.Request) (string, error) {
+	if req.EntityID == "" {
+		return "", ErrExitRequesterEntity
+	}
+	return "entity:" + req.EntityID, nil
+}
+
+// ensureHexPrefix returns the given hex string with a 0x prefix.
+func ensureHexPrefix(s string) string {
+	if strings.HasPrefix(s, "0x") {
+		return s
+	}
+	return "0x" + s
+}
@@ -0,0 +1,143 @@
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExitRequests(t *testing.T) {
+	b, _ := getBackend(t)
+	pubKey := "0x95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcf"
+
+	t.Run("Propose exit of unknown account", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/exits/propose")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.EntityID = "proposer"
+		req.Data = map[string]interface{}{
+			"public_key": "0x95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcd",
+			"epoch":      1,
+		}
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "account not found")
+	})
+
+	t.Run("Approve own exit request", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/exits/approve")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.EntityID = "proposer"
+		req.Data = map[string]interface{}{
+			"id": proposeVoluntaryExit(t, b, req.Storage, pubKey, 1),
+		}
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, ErrExitSelfApproval.Error())
+	})
+
+	t.Run("Approve expired exit request", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/exits/approve")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		id := proposeVoluntaryExit(t, b, req.Storage, pubKey, 1)
+		exitReq, err := getExitRequest(context.Background(), req.Storage, id)
+		require.NoError(t, err)
+		exitReq.ExpiresAt = time.Now().Add(-time.Second)
+		require.NoError(t, putExitRequest(context.Background(), req.Storage, exitReq))
+
+		req.EntityID = "approver"
+		req.Data = map[string]interface{}{
+			"id": id,
+		}
+		_, err = b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "voluntary exit request is expired")
+	})
+
+	t.Run("Propose exit without identity entity", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/exits/propose")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		// Display names are chosen by the token creator, they don't identify the requester
+		req.DisplayName = "exit-approver"
+		req.Data = map[string]interface{}{
+			"public_key": pubKey,
+			"epoch":      1,
+		}
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, ErrExitRequesterEntity.Error())
+	})
+
+	t.Run("Prune ended exit requests", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.ListOperation, "accounts/exits/")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+		defer func() {
+			b.(*backend).now = time.Now
+		}()
+
+		now := time.Now()
+		b.(*backend).now = func() time.Time {
+			return now
+		}
+		approvedID := approveVoluntaryExit(t, b, req.Storage, pubKey, 1)
+		completedID := approveVoluntaryExit(t, b, req.Storage, pubKey, 2)
+		exitReq, err := approvedExitRequest(context.Background(), req.Storage, _byteArray(pubKey[2:]), 2, now)
+		require.NoError(t, err)
+		require.Equal(t, completedID, exitReq.ID)
+		require.NoError(t, completeExitRequest(context.Background(), req.Storage, exitReq, now))
+		_, err = approvedExitRequest(context.Background(), req.Storage, _byteArray(pubKey[2:]), 2, now)
+		require.EqualError(t, err, ErrExitNotApproved.Error())
+
+		// Kept for audit until the retention is over, completed requests ended when completed
+		start := now
+		now = start.Add(exitRequestRetention - time.Second)
+		keptID := proposeVoluntaryExit(t, b, req.Storage, pubKey, 3)
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{approvedID, completedID, keptID}, res.Data["keys"])
+
+		now = start.Add(exitRequestRetention)
+		newID := proposeVoluntaryExit(t, b, req.Storage, pubKey, 4)
+		res, err = b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{approvedID, keptID, newID}, res.Data["keys"])
+
+		// Expired requests ended when they expired
+		now = start.Add(defaultExitRequestTTL + exitRequestRetention)
+		lastID := proposeVoluntaryExit(t, b, req.Storage, pubKey, 5)
+		res, err = b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{keptID, newID, lastID}, res.Data["keys"])
+		approvals, err := req.Storage.List(context.Background(), "exit_approvals/")
+		require.NoError(t, err)
+		require.Empty(t, approvals)
+	})
+
+	t.Run("List exit requests", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.ListOperation, "accounts/exits/")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		pendingID := proposeVoluntaryExit(t, b, req.Storage, pubKey, 1)
+		approvedID := approveVoluntaryExit(t, b, req.Storage, pubKey, 2)
+
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, []string{pendingID, approvedID}, res.Data["keys"])
+
+		keyInfo := res.Data["key_info"].(map[string]interface{})
+		require.Equal(t, ExitRequestPending, keyInfo[pendingID].(map[string]interface{})["status"])
+		approved := keyInfo[approvedID].(map[string]interface{})
+		require.Equal(t, ExitRequestApproved, approved["status"])
+		require.Equal(t, "entity:proposer", approved["proposed_by"])
+		require.Equal(t, "entity:approver", approved["approved_by"])
+		require.EqualValues(t, 2, approved["epoch"])
+	})
+}
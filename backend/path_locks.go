@@ -0,0 +1,38 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// Endpoints patterns
+const (
+	// LocksPattern is the path pattern for public key locks stats endpoint
+	LocksPattern = "locks"
+)
+
+func locksPaths(b *backend) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         LocksPattern,
+			HelpSynopsis:    "Shows public key locks stats",
+			HelpDescription: `Shows the number of active public key locks and the time sign requests waited for them since the plugin started`,
+			Fields:          map[string]*framework.FieldSchema{},
+			ExistenceCheck:  b.pathExistenceCheck,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathLocks,
+				},
+			},
+		},
+	}
+}
+
+// pathLocks returns the public key lock table stats
+func (b *backend) pathLocks(_ context.Context, _ *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	return &logical.Response{
+		Data: b.signLocks.Stats().Map(),
+	}, nil
+}
@@ -0,0 +1,46 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocks(t *testing.T) {
+	lb, _ := getBackend(t)
+	b := lb.(*backend)
+
+	req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+	setupBaseStorage(t, req)
+	require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+	t.Run("Do not lock unknown accounts", func(t *testing.T) {
+		req.Data = basicAttestationDataWithOps(true, false, false, false, false)
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "failed to sign: account not found")
+		require.EqualValues(t, 0, b.signLocks.Stats().Acquisitions)
+	})
+
+	t.Run("Release locks after signing", func(t *testing.T) {
+		req.Data = basicAttestationData()
+		_, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+
+		stats := b.signLocks.Stats()
+		require.EqualValues(t, 1, stats.Acquisitions)
+		require.Equal(t, 0, stats.Active)
+	})
+
+	t.Run("Read locks stats", func(t *testing.T) {
+		readReq := logical.TestRequest(t, logical.ReadOperation, "locks")
+		readReq.Storage = req.Storage
+		res, err := b.HandleRequest(context.Background(), readReq)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Data["active"])
+		require.EqualValues(t, 1, res.Data["acquisitions"])
+		require.EqualValues(t, 0, res.Data["contended"])
+		require.Equal(t, "0s", res.Data["wait_max"])
+	})
+}
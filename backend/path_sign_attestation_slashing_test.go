@@ -112,7 +112,7 @@ func TestAttestationSlashing(t *testing.T) {
 		)
 	})
 
-	t.Run("Sign duplicated Attestation (exactly same), should NOT sign", func(t *testing.T) {
+	t.Run("Sign duplicated Attestation (exactly same), should sign again", func(t *testing.T) {
 		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
 		setupBaseStorage(t, req)
 
@@ -126,10 +126,40 @@ func TestAttestationSlashing(t *testing.T) {
 		res, err := b.HandleRequest(context.Background(), req)
 		require.NoError(t, err)
 		require.NotNil(t, res.Data)
+		signature := res.Data["signature"]
 
 		// duplicated attestation
 		req.Data = basicAttestationData()
 		res, err = b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, signature, res.Data["signature"])
+	})
+
+	t.Run("Sign duplicated Attestation (exactly same) saved without signing root, should NOT sign", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req)
+
+		// setup storage
+		err := setupStorageWithWalletAndAccounts(req.Storage)
+		require.NoError(t, err)
+		require.NoError(t, updateWithBasicHighestAtt(req.Storage))
+
+		// first attestation
+		req.Data = basicAttestationData()
+		_, err = b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+
+		// drop the signing root, like attestations saved by previous versions
+		storage := store.NewHashicorpVaultStore(context.Background(), req.Storage, core.PraterNetwork)
+		pubKey := _byteArray("95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcf")
+		highest, found, err := storage.RetrieveHighestAttestation(pubKey)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.NoError(t, storage.SaveHighestAttestation(pubKey, highest))
+
+		// duplicated attestation
+		req.Data = basicAttestationData()
+		res, err := b.HandleRequest(context.Background(), req)
 		require.EqualError(t, err, "failed to sign: slashable attestation (HighestAttestationVote), not signing")
 		require.Nil(t, res)
 	})
@@ -324,3 +354,43 @@ func TestAttestationSlashing(t *testing.T) {
 		require.Nil(t, res)
 	})
 }
+
+func TestAttestationHistorySlashing(t *testing.T) {
+	b, _ := getBackend(t)
+	withAttestationHistory := func(config *Config) {
+		config.AttestationHistory = true
+	}
+
+	t.Run("Re-sign duplicated Attestation (exactly same)", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req, withAttestationHistory)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+		require.NoError(t, updateWithBasicHighestAtt(req.Storage))
+
+		req.Data = basicAttestationData()
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		signature := res.Data["signature"]
+
+		req.Data = basicAttestationData()
+		res, err = b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, signature, res.Data["signature"])
+	})
+
+	t.Run("Sign double Attestation (different block root), should return error", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req, withAttestationHistory)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+		require.NoError(t, updateWithBasicHighestAtt(req.Storage))
+
+		req.Data = basicAttestationData()
+		_, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+
+		req.Data = basicAttestationDataWithOps(false, true, false, false, false)
+		res, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "failed to sign: slashable attestation (DoubleVote), not signing")
+		require.Nil(t, res)
+	})
+}
@@ -0,0 +1,194 @@
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"sync"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/bloxapp/eth2-key-manager/wallets/hd"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+
+	"github.com/bloxapp/key-vault/keymanager/models"
+	"github.com/bloxapp/key-vault/utils/encoder"
+)
+
+// Endpoints patterns
+const (
+	// SignBatchPattern is the path pattern for sign batch endpoint
+	SignBatchPattern = "accounts/sign-batch"
+)
+
+// Batch sign error types
+const (
+	SignErrorTypeInvalidRequest  = "invalid_request"
+	SignErrorTypeAccountNotFound = "account_not_found"
+	SignErrorTypeSlashable       = "slashable"
+	SignErrorTypeRefused         = "refused"
+	SignErrorTypeAccountDisabled = "account_disabled"
+	SignErrorTypeInternal        = "internal"
+)
+
+const (
+	// maxSignBatchSize is the maximum number of sign requests in a single batch.
+	maxSignBatchSize = 10000
+
+	// signBatchConcurrency is the number of sign requests of a batch processed concurrently.
+	signBatchConcurrency = 16
+)
+
+func signsBatchPaths(b *backend) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         SignBatchPattern,
+			HelpSynopsis:    "Sign batch",
+			HelpDescription: `Sign a batch of requests, the result of each request is returned in the request order`,
+			Fields: map[string]*framework.FieldSchema{
+				"sign_reqs": {
+					Type:        framework.TypeStringSlice,
+					Description: "List of SSZ Serialized sign request objects",
+				},
+				"encoding": signRequestEncodingField(),
+			},
+			ExistenceCheck: b.pathExistenceCheck,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.CreateOperation: &framework.PathOperation{
+					Callback: b.pathSignBatch,
+				},
+			},
+		},
+	}
+}
+
+func (b *backend) pathSignBatch(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	encoding := data.Get("encoding").(string)
+	reqsEncoded := data.Get("sign_reqs").([]string)
+	if len(reqsEncoded) == 0 {
+		return nil, errors.New("sign requests are required")
+	}
+	if len(reqsEncoded) > maxSignBatchSize {
+		return nil, errors.Errorf("too many sign requests, max is %d", maxSignBatchSize)
+	}
+
+	// Load config
+	config, err := b.readConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get config")
+	}
+
+	storage, wallet, err := b.openWallet(ctx, req.Storage, config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign")
+	}
+
+	results := make([]map[string]interface{}, len(reqsEncoded))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < signBatchConcurrency && w < len(reqsEncoded); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				signReq, err := b.decodeSignRequest(encoding, reqsEncoded[i])
+				if err != nil {
+					results[i] = signBatchErrorResult(SignErrorTypeInvalidRequest, err)
+					continue
+				}
+
+				var (
+					sig  []byte
+					root phase0.Root
+				)
+				err = b.lock(wallet, signReq.GetPublicKey(), func() error {
+					sig, root, err = signWithWallet(storage, wallet, config, signReq, b.now())
+					return err
+				})
+				if err != nil {
+					results[i] = signBatchErrorResult(signErrorType(err), err)
+					continue
+				}
+
+				results[i] = map[string]interface{}{
+					"signature":    hex.EncodeToString(sig),
+					"signing_root": hex.EncodeToString(root[:]),
+				}
+			}
+		}()
+	}
+	for i := range reqsEncoded {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"results": results,
+		},
+	}, nil
+}
+
+// signRequestEncodingField returns the schema of the sign request encoding field
+func signRequestEncodingField() *framework.FieldSchema {
+	return &framework.FieldSchema{
+		Type:          framework.TypeString,
+		Description:   "Sign request encoding, v2 (hex encoded) or json (plain JSON)",
+		Default:       encoder.EncodingV2,
+		AllowedValues: []interface{}{encoder.EncodingV2, encoder.EncodingJSON},
+	}
+}
+
+// decodeSignRequest decodes the given sign request of the given encoding
+func (b *backend) decodeSignRequest(encoding string, reqEncoded string) (*models.SignRequest, error) {
+	signReq := &models.SignRequest{}
+	switch encoding {
+	case encoder.EncodingJSON:
+		if err := b.jsonEncoder.Decode([]byte(reqEncoded), signReq); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal sign request")
+		}
+	case "", encoder.EncodingV2:
+		reqByts, err := hex.DecodeString(reqEncoded)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode sign request hex")
+		}
+		if err := b.encoder.Decode(reqByts, signReq); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal sign request")
+		}
+	default:
+		return nil, errors.Errorf("unsupported sign request encoding %q", encoding)
+	}
+	return signReq, nil
+}
+
+// signErrorType returns the batch sign error type of the given sign error
+func signErrorType(err error) string {
+	switch {
+	case errors.Is(err, hd.ErrAccountNotFound):
+		return SignErrorTypeAccountNotFound
+	case errors.Is(err, ErrAccountDisabled):
+		return SignErrorTypeAccountDisabled
+	case errors.Is(err, ErrSlashable):
+		return SignErrorTypeSlashable
+	case errors.Is(err, ErrAccountQuarantined),
+		errors.Is(err, ErrWallClockDistance),
+		errors.Is(err, ErrFarFuture),
+		errors.Is(err, ErrFeeRecipientNotSet),
+		errors.Is(err, ErrFeeRecipientDiffers):
+		return SignErrorTypeRefused
+	case errors.Is(err, ErrSigningRootMismatch),
+		errors.Is(err, ErrInvalidSignatureDomain),
+		errors.Is(err, ErrSignRequestNotSupported):
+		return SignErrorTypeInvalidRequest
+	default:
+		return SignErrorTypeInternal
+	}
+}
+
+func signBatchErrorResult(errType string, err error) map[string]interface{} {
+	return map[string]interface{}{
+		"error":      err.Error(),
+		"error_type": errType,
+	}
+}
@@ -0,0 +1,88 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bloxapp/eth2-key-manager/wallets/hd"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignBatch(t *testing.T) {
+	b, _ := getBackend(t)
+
+	t.Run("Successfully sign batch with per item results", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign-batch")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data = map[string]interface{}{
+			"sign_reqs": []string{
+				basicAttestationData()["sign_req"].(string),
+				basicAttestationDataWithOps(true, false, false, false, false)["sign_req"].(string),
+				"not hex",
+				basicAggregationAndProofData()["sign_req"].(string),
+			},
+		}
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+
+		results := res.Data["results"].([]map[string]interface{})
+		require.Len(t, results, 4)
+		require.Len(t, results[0]["signature"], 192)
+		require.Equal(t, SignErrorTypeAccountNotFound, results[1]["error_type"])
+		require.Equal(t, "account not found", results[1]["error"])
+		require.Equal(t, SignErrorTypeInvalidRequest, results[2]["error_type"])
+		require.Len(t, results[3]["signature"], 192)
+
+		// A slashable item is refused without failing the rest of the batch
+		req.Data = map[string]interface{}{
+			"sign_reqs": []string{
+				basicAttestationDataWithOps(false, true, false, false, false)["sign_req"].(string),
+				basicAttestationData()["sign_req"].(string),
+			},
+		}
+		res, err = b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+
+		results = res.Data["results"].([]map[string]interface{})
+		require.Len(t, results, 2)
+		require.Equal(t, SignErrorTypeSlashable, results[0]["error_type"])
+		require.Len(t, results[1]["signature"], 192)
+	})
+
+	t.Run("Sign empty batch", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign-batch")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data = map[string]interface{}{
+			"sign_reqs": []string{},
+		}
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "sign requests are required")
+	})
+}
+
+func TestSignErrorType(t *testing.T) {
+	slashable := &signError{err: errors.New("slashable attestation (HighestAttestationVote), not signing"), kind: ErrSlashable}
+	require.Equal(t, "slashable attestation (HighestAttestationVote), not signing", slashable.Error())
+
+	for err, errType := range map[error]string{
+		hd.ErrAccountNotFound:                              SignErrorTypeAccountNotFound,
+		errors.Wrap(ErrAccountDisabled, "refused to sign"): SignErrorTypeAccountDisabled,
+		slashable: SignErrorTypeSlashable,
+		errors.Wrap(ErrAccountQuarantined, "refused to sign until epoch 10"): SignErrorTypeRefused,
+		errors.Wrap(ErrWallClockDistance, "refused to sign block"):           SignErrorTypeRefused,
+		errors.Wrap(ErrFarFuture, "target epoch"):                            SignErrorTypeRefused,
+		errors.Wrap(ErrFeeRecipientDiffers, "refused to sign"):               SignErrorTypeRefused,
+		ErrSigningRootMismatch:                                               SignErrorTypeInvalidRequest,
+		ErrSignRequestNotSupported:                                           SignErrorTypeInvalidRequest,
+		// Error messages aren't matched
+		errors.New("account not found, slashable, refused to sign"): SignErrorTypeInternal,
+	} {
+		require.Equal(t, errType, signErrorType(errors.Wrap(err, "failed to sign")), err.Error())
+	}
+}
@@ -0,0 +1,155 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/bloxapp/eth2-key-manager/signer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+
+	"github.com/bloxapp/key-vault/keymanager/models"
+)
+
+// Endpoints patterns
+const (
+	// SignBLSToExecutionChangePattern is the path pattern for sign bls to execution change endpoint
+	SignBLSToExecutionChangePattern = "accounts/sign-bls-to-execution-change"
+)
+
+var (
+	// ErrWithdrawalKeyMismatch is returned when the signing account is not the withdrawal key of the change.
+	ErrWithdrawalKeyMismatch = errors.New("public key does not match the withdrawal public key of the bls to execution change")
+
+	// ErrWithdrawalKeyNotHeld is returned when the account does not hold the withdrawal private key.
+	ErrWithdrawalKeyNotHeld = errors.New("withdrawal private key is not held")
+
+	// ErrWithdrawalAddressNotSet is returned when no execution address is allowed for the withdrawal public key.
+	ErrWithdrawalAddressNotSet = errors.New("withdrawal address is not configured for withdrawal public key")
+
+	// ErrWithdrawalAddressDiffers is returned when the requested execution address does not match the allowed one.
+	ErrWithdrawalAddressDiffers = errors.New("requested execution address does not match configured withdrawal address")
+)
+
+func signsBLSToExecutionChangePaths(b *backend) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         SignBLSToExecutionChangePattern,
+			HelpSynopsis:    "Sign bls to execution change",
+			HelpDescription: `Sign a bls to execution change with the withdrawal key, the execution address must be allowed in the config withdrawal_addresses`,
+			Fields: map[string]*framework.FieldSchema{
+				"sign_req": {
+					Type:        framework.TypeString,
+					Description: "SSZ Serialized sign bls to execution change request object",
+					Default:     "",
+				},
+				"encoding": signRequestEncodingField(),
+			},
+			ExistenceCheck: b.pathExistenceCheck,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.CreateOperation: &framework.PathOperation{
+					Callback: b.pathSignBLSToExecutionChange,
+				},
+			},
+		},
+	}
+}
+
+func (b *backend) pathSignBLSToExecutionChange(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	// Load config
+	config, err := b.readConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get config")
+	}
+
+	// Parse request data
+	signReq, err := b.decodeSignRequest(data.Get("encoding").(string), data.Get("sign_req").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	sig, root, err := b.signBLSToExecutionChange(ctx, req.Storage, config, signReq)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign")
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"signature":    hex.EncodeToString(sig),
+			"signing_root": hex.EncodeToString(root[:]),
+		},
+	}, nil
+}
+
+// signBLSToExecutionChange signs the given bls to execution change request under the public key lock.
+// The request public key must be the withdrawal public key of the change, held by an account of the wallet,
+// and the execution address must match the one allowed for it in the config.
+func (b *backend) signBLSToExecutionChange(ctx context.Context, s logical.Storage, config *Config, signReq *models.SignRequest) ([]byte, phase0.Root, error) {
+	var (
+		sig  []byte
+		root phase0.Root
+	)
+	storage, wallet, err := b.openWallet(ctx, s, config)
+	if err != nil {
+		return nil, phase0.Root{}, err
+	}
+
+	t, ok := signReq.GetObject().(*models.SignRequestBLSToExecutionChange)
+	if !ok {
+		return nil, phase0.Root{}, errors.New("failed to cast to sign request bls to execution change")
+	}
+	// BLS to execution changes are always signed with the genesis fork version and the genesis validators root,
+	// the domain is computed rather than trusted. Requests without a domain get the computed one.
+	domain, err := computeDomain(DomainBLSToExecutionChange, config.genesisForkVersion(), config.genesisValidatorsRoot())
+	if err != nil {
+		return nil, phase0.Root{}, err
+	}
+	if signReq.SignatureDomain != (phase0.Domain{}) && signReq.SignatureDomain != domain {
+		return nil, phase0.Root{}, errors.Wrapf(ErrInvalidSignatureDomain, "expected the genesis fork domain %#x", domain[:])
+	}
+	signReq.SignatureDomain = domain
+	if root, err = verifySigningRoot(signReq); err != nil {
+		return nil, phase0.Root{}, err
+	}
+
+	change := t.BLSToExecutionChange
+	if !bytes.Equal(signReq.PublicKey, change.FromBLSPubkey[:]) {
+		return nil, phase0.Root{}, ErrWithdrawalKeyMismatch
+	}
+
+	// HD accounts only hold the withdrawal public key, the change can only be signed
+	// by an account imported from the withdrawal private key.
+	if _, err := wallet.AccountByPublicKey(hex.EncodeToString(signReq.PublicKey)); err != nil {
+		for _, account := range wallet.Accounts() {
+			if bytes.Equal(account.WithdrawalPublicKey(), signReq.PublicKey) {
+				return nil, phase0.Root{}, ErrWithdrawalKeyNotHeld
+			}
+		}
+		return nil, phase0.Root{}, err
+	}
+
+	err = b.lock(wallet, signReq.GetPublicKey(), func() error {
+		if err := validateRequestedWithdrawalAddress(change.FromBLSPubkey[:], config.WithdrawalAddresses, change.ToExecutionAddress); err != nil {
+			return errors.Wrap(err, "refused to sign")
+		}
+
+		sig, _, err = signer.NewSimpleSigner(wallet, nil, storage.Network()).SignBLSToExecutionChange(change, signReq.SignatureDomain, signReq.PublicKey)
+		return err
+	})
+	return sig, root, err
+}
+
+func validateRequestedWithdrawalAddress(withdrawalPubKey []byte, configWithdrawalAddresses WithdrawalAddresses, requestedAddress [20]byte) error {
+	withdrawalAddress, ok := configWithdrawalAddresses.Get(withdrawalPubKey)
+	if !ok {
+		return ErrWithdrawalAddressNotSet
+	}
+	if withdrawalAddress != common.BytesToAddress(requestedAddress[:]) {
+		return ErrWithdrawalAddressDiffers
+	}
+	return nil
+}
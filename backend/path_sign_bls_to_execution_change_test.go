@@ -0,0 +1,200 @@
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/bloxapp/eth2-key-manager/core"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bloxapp/key-vault/backend/store"
+	"github.com/bloxapp/key-vault/keymanager/models"
+	"github.com/bloxapp/key-vault/utils/encoder"
+)
+
+const (
+	testWithdrawalPubKey  = "0x95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcf"
+	testExecutionAddress  = "0x6a3f3ee924a940ce0d795c5a41a817607e520520"
+	testBLSChangeDomain   = "0a00000079df04282c5a87e1ed3e3928ad19967c5612f940186455587f05da12"
+	otherExecutionAddress = "0x0000000000000000000000000000000000000001"
+)
+
+func basicBLSToExecutionChangeData(t *testing.T, pubKey string, executionAddress string, domain string) map[string]interface{} {
+	change := &capella.BLSToExecutionChange{
+		ValidatorIndex: 1,
+	}
+	copy(change.FromBLSPubkey[:], hexutil.MustDecode(pubKey))
+	copy(change.ToExecutionAddress[:], hexutil.MustDecode(executionAddress))
+
+	req := &models.SignRequest{
+		PublicKey:       hexutil.MustDecode(pubKey),
+		SignatureDomain: _byteArray32(domain),
+		Object:          &models.SignRequestBLSToExecutionChange{BLSToExecutionChange: change},
+	}
+
+	byts, err := encoder.New().Encode(req)
+	require.NoError(t, err)
+	return map[string]interface{}{
+		"sign_req": hex.EncodeToString(byts),
+	}
+}
+
+func withWithdrawalAddress(pubKey string, executionAddress string) func(*Config) {
+	return func(c *Config) {
+		c.WithdrawalAddresses = WithdrawalAddresses{pubKey: executionAddress}
+	}
+}
+
+func TestSignBLSToExecutionChange(t *testing.T) {
+	b, _ := getBackend(t)
+
+	t.Run("Successfully sign bls to execution change", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign-bls-to-execution-change")
+		setupBaseStorage(t, req, withWithdrawalAddress(testWithdrawalPubKey, testExecutionAddress))
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data = basicBLSToExecutionChangeData(t, testWithdrawalPubKey, testExecutionAddress, testBLSChangeDomain)
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Len(t, res.Data["signature"], 192)
+		require.Len(t, res.Data["signing_root"], 64)
+	})
+
+	t.Run("Sign bls to execution change with genesis fork domain", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign-bls-to-execution-change")
+		setupBaseStorage(t, req, withWithdrawalAddress(testWithdrawalPubKey, testExecutionAddress), withPraterForkSchedule(t))
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		config := &Config{}
+		withPraterForkSchedule(t)(config)
+		genesisDomain, err := computeDomain(DomainBLSToExecutionChange, config.ForkSchedule[0].Version, config.GenesisValidatorsRoot)
+		require.NoError(t, err)
+		capellaDomain, err := computeDomain(DomainBLSToExecutionChange, config.ForkSchedule[3].Version, config.GenesisValidatorsRoot)
+		require.NoError(t, err)
+
+		req.Data = basicBLSToExecutionChangeData(t, testWithdrawalPubKey, testExecutionAddress, hex.EncodeToString(capellaDomain[:]))
+		_, err = b.HandleRequest(context.Background(), req)
+		require.ErrorIs(t, err, ErrInvalidSignatureDomain)
+
+		req.Data = basicBLSToExecutionChangeData(t, testWithdrawalPubKey, testExecutionAddress, hex.EncodeToString(genesisDomain[:]))
+		_, err = b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+	})
+
+	t.Run("Refuse other domains without fork schedule", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign-bls-to-execution-change")
+		setupBaseStorage(t, req, withWithdrawalAddress(testWithdrawalPubKey, testExecutionAddress))
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		// The domain of the Capella fork version, instead of the genesis one
+		capellaDomain, err := computeDomain(DomainBLSToExecutionChange, phase0.Version{0x03, 0x00, 0x10, 0x20}, core.PraterNetwork.GenesisValidatorsRoot())
+		require.NoError(t, err)
+		req.Data = basicBLSToExecutionChangeData(t, testWithdrawalPubKey, testExecutionAddress, hex.EncodeToString(capellaDomain[:]))
+		_, err = b.HandleRequest(context.Background(), req)
+		require.ErrorIs(t, err, ErrInvalidSignatureDomain)
+	})
+
+	t.Run("Sign without domain", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign-bls-to-execution-change")
+		setupBaseStorage(t, req, withWithdrawalAddress(testWithdrawalPubKey, testExecutionAddress))
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data = basicBLSToExecutionChangeData(t, testWithdrawalPubKey, testExecutionAddress, testBLSChangeDomain)
+		expected, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+
+		// The genesis fork domain is computed
+		req.Data = basicBLSToExecutionChangeData(t, testWithdrawalPubKey, testExecutionAddress, hex.EncodeToString(make([]byte, 32)))
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, expected.Data, res.Data)
+	})
+
+	t.Run("Refuse to sign without configured withdrawal address", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign-bls-to-execution-change")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data = basicBLSToExecutionChangeData(t, testWithdrawalPubKey, testExecutionAddress, testBLSChangeDomain)
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "failed to sign: refused to sign: withdrawal address is not configured for withdrawal public key")
+	})
+
+	t.Run("Refuse to sign different execution address", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign-bls-to-execution-change")
+		setupBaseStorage(t, req, withWithdrawalAddress(testWithdrawalPubKey, testExecutionAddress))
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data = basicBLSToExecutionChangeData(t, testWithdrawalPubKey, otherExecutionAddress, testBLSChangeDomain)
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "failed to sign: refused to sign: requested execution address does not match configured withdrawal address")
+	})
+
+	t.Run("Sign with withdrawal public key only held", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign-bls-to-execution-change")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		wallet, err := store.NewHashicorpVaultStore(context.Background(), req.Storage, core.PraterNetwork).OpenWallet()
+		require.NoError(t, err)
+		withdrawalPubKey := hexutil.Encode(wallet.Accounts()[0].WithdrawalPublicKey())
+
+		req.Data = basicBLSToExecutionChangeData(t, withdrawalPubKey, testExecutionAddress, testBLSChangeDomain)
+		_, err = b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "failed to sign: withdrawal private key is not held")
+	})
+
+	t.Run("Sign with unknown account", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign-bls-to-execution-change")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		pubKey := "0x95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcd"
+		req.Data = basicBLSToExecutionChangeData(t, pubKey, testExecutionAddress, testBLSChangeDomain)
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "failed to sign: account not found")
+	})
+
+	t.Run("Refuse other sign request objects", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign-bls-to-execution-change")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data = basicVoluntaryExitData(false)
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "failed to sign: failed to cast to sign request bls to execution change")
+	})
+}
+
+func TestSignRefusesBLSToExecutionChange(t *testing.T) {
+	b, _ := getBackend(t)
+
+	req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+	setupBaseStorage(t, req, withWithdrawalAddress(testWithdrawalPubKey, testExecutionAddress))
+	require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+	req.Data = basicBLSToExecutionChangeData(t, testWithdrawalPubKey, testExecutionAddress, testBLSChangeDomain)
+	_, err := b.HandleRequest(context.Background(), req)
+	require.EqualError(t, err, "failed to sign: sign request: not supported")
+}
+
+func TestParseWithdrawalAddresses(t *testing.T) {
+	addresses, err := ParseWithdrawalAddresses(map[string]interface{}{
+		testWithdrawalPubKey: testExecutionAddress,
+	})
+	require.NoError(t, err)
+	addr, ok := addresses.Get(hexutil.MustDecode(testWithdrawalPubKey))
+	require.True(t, ok)
+	require.Equal(t, testExecutionAddress, hexutil.Encode(addr[:]))
+
+	_, err = ParseWithdrawalAddresses(map[string]interface{}{"default": testExecutionAddress})
+	require.EqualError(t, err, `invalid withdrawal_addresses provided: invalid public key "default"`)
+
+	_, err = ParseWithdrawalAddresses(map[string]interface{}{testWithdrawalPubKey: "0x01"})
+	require.EqualError(t, err, `invalid withdrawal_addresses provided: invalid address "0x01"`)
+}
@@ -12,15 +12,20 @@ import (
 	apiv1bellatrix "github.com/attestantio/go-eth2-client/api/v1/bellatrix"
 	apiv1capella "github.com/attestantio/go-eth2-client/api/v1/capella"
 	apiv1deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	apiv1electra "github.com/attestantio/go-eth2-client/api/v1/electra"
 	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/altair"
 	"github.com/attestantio/go-eth2-client/spec/bellatrix"
 	"github.com/attestantio/go-eth2-client/spec/capella"
 	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/electra"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/bloxapp/eth2-key-manager/core"
 	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/prysmaticlabs/go-bitfield"
 	"github.com/stretchr/testify/require"
 
+	"github.com/bloxapp/key-vault/backend/store"
 	"github.com/bloxapp/key-vault/keymanager/models"
 	"github.com/bloxapp/key-vault/utils/encoder"
 )
@@ -242,23 +247,98 @@ func basicSignRequestBlock(blockVersion spec.DataVersion, isBlinded bool, differ
 			Version: spec.DataVersionDeneb,
 			Deneb:   block,
 		}}
+	case spec.DataVersionElectra:
+		if !isBlinded {
+			block := basicSignRequestBlock(spec.DataVersionDeneb, false, differentStateRoot, differentParentRoot, differentBodyRoot).(*models.SignRequestBlock).VersionedBeaconBlock.Deneb
+			return &models.SignRequestBlock{VersionedBeaconBlock: &spec.VersionedBeaconBlock{
+				Version: spec.DataVersionElectra,
+				Electra: &electra.BeaconBlock{
+					Slot:          block.Slot,
+					ProposerIndex: block.ProposerIndex,
+					ParentRoot:    block.ParentRoot,
+					StateRoot:     block.StateRoot,
+					Body: &electra.BeaconBlockBody{
+						RANDAOReveal:          block.Body.RANDAOReveal,
+						ETH1Data:              block.Body.ETH1Data,
+						Graffiti:              block.Body.Graffiti,
+						ProposerSlashings:     block.Body.ProposerSlashings,
+						AttesterSlashings:     []*electra.AttesterSlashing{},
+						Attestations:          electraAttestations(block.Body.Attestations),
+						Deposits:              block.Body.Deposits,
+						VoluntaryExits:        block.Body.VoluntaryExits,
+						SyncAggregate:         block.Body.SyncAggregate,
+						ExecutionPayload:      block.Body.ExecutionPayload,
+						BLSToExecutionChanges: block.Body.BLSToExecutionChanges,
+						BlobKZGCommitments:    block.Body.BlobKZGCommitments,
+						ExecutionRequests:     &electra.ExecutionRequests{},
+					},
+				},
+			}}
+		}
+
+		block := basicSignRequestBlock(spec.DataVersionDeneb, true, differentStateRoot, differentParentRoot, differentBodyRoot).(*models.SignRequestBlindedBlock).VersionedBlindedBeaconBlock.Deneb
+		return &models.SignRequestBlindedBlock{VersionedBlindedBeaconBlock: &api.VersionedBlindedBeaconBlock{
+			Version: spec.DataVersionElectra,
+			Electra: &apiv1electra.BlindedBeaconBlock{
+				Slot:          block.Slot,
+				ProposerIndex: block.ProposerIndex,
+				ParentRoot:    block.ParentRoot,
+				StateRoot:     block.StateRoot,
+				Body: &apiv1electra.BlindedBeaconBlockBody{
+					RANDAOReveal:           block.Body.RANDAOReveal,
+					ETH1Data:               block.Body.ETH1Data,
+					Graffiti:               block.Body.Graffiti,
+					ProposerSlashings:      block.Body.ProposerSlashings,
+					AttesterSlashings:      []*electra.AttesterSlashing{},
+					Attestations:           electraAttestations(block.Body.Attestations),
+					Deposits:               block.Body.Deposits,
+					VoluntaryExits:         block.Body.VoluntaryExits,
+					SyncAggregate:          block.Body.SyncAggregate,
+					ExecutionPayloadHeader: block.Body.ExecutionPayloadHeader,
+					BLSToExecutionChanges:  block.Body.BLSToExecutionChanges,
+					BlobKZGCommitments:     block.Body.BlobKZGCommitments,
+					ExecutionRequests:      &electra.ExecutionRequests{},
+				},
+			},
+		}}
 	default:
 		panic("block version not supported")
 	}
 }
 
+// electraAttestations returns the first of the given attestations as an electra one: its committee moves
+// to the committee bits, and fewer attestations fit in an electra block.
+func electraAttestations(attestations []*phase0.Attestation) []*electra.Attestation {
+	if len(attestations) == 0 {
+		return []*electra.Attestation{}
+	}
+	att := attestations[0]
+	committeeBits := bitfield.NewBitvector64()
+	committeeBits.SetBitAt(uint64(att.Data.Index), true)
+	data := *att.Data
+	data.Index = 0
+	return []*electra.Attestation{{
+		AggregationBits: att.AggregationBits,
+		Data:            &data,
+		Signature:       att.Signature,
+		CommitteeBits:   committeeBits,
+	}}
+}
+
 var testableBlockVersions = []spec.DataVersion{
 	spec.DataVersionPhase0,
 	spec.DataVersionAltair,
 	spec.DataVersionBellatrix,
 	spec.DataVersionCapella,
 	spec.DataVersionDeneb,
+	spec.DataVersionElectra,
 }
 
 var testableBlindedBlockVersions = []spec.DataVersion{
 	spec.DataVersionBellatrix,
 	spec.DataVersionCapella,
 	spec.DataVersionDeneb,
+	spec.DataVersionElectra,
 }
 
 // withEachBlockVersion runs the given subtest for each block version.
@@ -309,7 +389,28 @@ func TestProposalSlashing(t *testing.T) {
 		require.Nil(t, res)
 	})
 
-	withEachBlockVersion(t, "Sign proposal (exactly same), should error under minimal proposal protection", func(t *testing.T, blockVersion spec.DataVersion, isBlinded bool) {
+	withEachBlockVersion(t, "Sign proposal (exactly same), should sign again", func(t *testing.T, blockVersion spec.DataVersion, isBlinded bool) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req)
+
+		// setup storage
+		err := setupStorageWithWalletAndAccounts(req.Storage)
+		require.NoError(t, err)
+
+		// first proposal
+		req.Data = basicProposalData(blockVersion, isBlinded)
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		signature := res.Data["signature"]
+
+		// second proposal
+		req.Data = basicProposalData(blockVersion, isBlinded)
+		res, err = b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, signature, res.Data["signature"])
+	})
+
+	withEachBlockVersion(t, "Sign proposal (exactly same) saved without signing root, should error", func(t *testing.T, blockVersion spec.DataVersion, isBlinded bool) {
 		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
 		setupBaseStorage(t, req)
 
@@ -322,6 +423,14 @@ func TestProposalSlashing(t *testing.T) {
 		_, err = b.HandleRequest(context.Background(), req)
 		require.NoError(t, err)
 
+		// drop the signing root, like proposals saved by previous versions
+		storage := store.NewHashicorpVaultStore(context.Background(), req.Storage, core.PraterNetwork)
+		pubKey := _byteArray("95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcf")
+		slot, found, err := storage.RetrieveHighestProposal(pubKey)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.NoError(t, storage.SaveHighestProposal(pubKey, slot))
+
 		// second proposal
 		req.Data = basicProposalData(blockVersion, isBlinded)
 		res, err := b.HandleRequest(context.Background(), req)
@@ -4,13 +4,12 @@ import (
 	"context"
 	"encoding/hex"
 
-	vault "github.com/bloxapp/eth2-key-manager"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/bloxapp/eth2-key-manager/signer"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/pkg/errors"
 
-	"github.com/bloxapp/key-vault/backend/store"
 	"github.com/bloxapp/key-vault/keymanager/models"
 )
 
@@ -32,6 +31,7 @@ func signsVoluntaryExitPath(b *backend) []*framework.Path {
 					Description: "SSZ Serialized sign voluntary exit request object",
 					Default:     "",
 				},
+				"encoding": signRequestEncodingField(),
 			},
 			ExistenceCheck: b.pathExistenceCheck,
 			Operations: map[logical.Operation]framework.OperationHandler{
@@ -51,35 +51,37 @@ func (b *backend) pathSignVoluntaryExit(ctx context.Context, req *logical.Reques
 	}
 
 	// Parse request data
-	reqEncoded := data.Get("sign_req").(string)
-	reqByts, err := hex.DecodeString(reqEncoded)
+	signReq, err := b.decodeSignRequest(data.Get("encoding").(string), data.Get("sign_req").(string))
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to decode sign request hex")
+		return nil, err
 	}
 
-	signReq := &models.SignRequest{}
-	if err := b.encoder.Decode(reqByts, signReq); err != nil {
-		return nil, errors.Wrap(err, "failed to unmarshal sign request")
+	sig, root, err := b.signVoluntaryExit(ctx, req.Storage, config, signReq)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign")
 	}
 
-	var sig []byte
-	err = b.lock(signReq.GetPublicKey(), func() error {
-		// bring up KeyVault and wallet
-		storage := store.NewHashicorpVaultStore(ctx, req.Storage, config.Network)
-		options := vault.KeyVaultOptions{}
-		options.SetStorage(storage)
-
-		// Open wallet
-		kv, err := vault.OpenKeyVault(&options)
-		if err != nil {
-			return errors.Wrap(err, "failed to open key vault")
-		}
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"signature":    hex.EncodeToString(sig),
+			"signing_root": hex.EncodeToString(root[:]),
+		},
+	}, nil
+}
 
-		wallet, err := kv.Wallet()
-		if err != nil {
-			return errors.Wrap(err, "failed to retrieve wallet")
-		}
+// signVoluntaryExit signs the given voluntary exit request under the public key lock,
+// once it has been proposed and approved through the voluntary exit requests.
+func (b *backend) signVoluntaryExit(ctx context.Context, s logical.Storage, config *Config, signReq *models.SignRequest) ([]byte, phase0.Root, error) {
+	var (
+		sig  []byte
+		root phase0.Root
+	)
+	storage, wallet, err := b.openWallet(ctx, s, config)
+	if err != nil {
+		return nil, phase0.Root{}, err
+	}
 
+	err = b.lock(wallet, signReq.GetPublicKey(), func() error {
 		var (
 			simpleSigner signer.ValidatorSigner = signer.NewSimpleSigner(wallet, nil, storage.Network())
 			sigErr       error
@@ -89,17 +91,27 @@ func (b *backend) pathSignVoluntaryExit(ctx context.Context, req *logical.Reques
 		if !ok {
 			return errors.New("failed to cast to sign request voluntary exit")
 		}
-		sig, _, sigErr = simpleSigner.SignVoluntaryExit(t.VoluntaryExit, signReq.SignatureDomain, signReq.PublicKey)
+		if root, err = verifySigningRoot(signReq); err != nil {
+			return err
+		}
+		if err := validateSignatureDomain(config, signReq); err != nil {
+			return err
+		}
+		if err := checkAccountEnabled(storage, signReq.PublicKey); err != nil {
+			return err
+		}
 
-		return sigErr
-	})
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to sign")
-	}
+		// Only sign exits approved by a second person, the approval is consumed by the signature.
+		exitReq, err := approvedExitRequest(ctx, s, signReq.PublicKey, t.VoluntaryExit.Epoch, b.now())
+		if err != nil {
+			return err
+		}
 
-	return &logical.Response{
-		Data: map[string]interface{}{
-			"signature": hex.EncodeToString(sig),
-		},
-	}, nil
+		sig, _, sigErr = simpleSigner.SignVoluntaryExit(t.VoluntaryExit, signReq.SignatureDomain, signReq.PublicKey)
+		if sigErr != nil {
+			return sigErr
+		}
+		return completeExitRequest(ctx, s, exitReq, b.now())
+	})
+	return sig, root, err
 }
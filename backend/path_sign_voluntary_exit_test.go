@@ -36,6 +36,35 @@ func basicVoluntaryExitData(differentPubKey bool) map[string]interface{} {
 	}
 }
 
+func proposeVoluntaryExit(t *testing.T, b logical.Backend, s logical.Storage, pubKey string, epoch int) string {
+	req := logical.TestRequest(t, logical.CreateOperation, "accounts/exits/propose")
+	req.Storage = s
+	req.EntityID = "proposer"
+	req.Data = map[string]interface{}{
+		"public_key": pubKey,
+		"epoch":      epoch,
+	}
+	resp, err := b.HandleRequest(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, ExitRequestPending, resp.Data["status"])
+	return resp.Data["id"].(string)
+}
+
+func approveVoluntaryExit(t *testing.T, b logical.Backend, s logical.Storage, pubKey string, epoch int) string {
+	id := proposeVoluntaryExit(t, b, s, pubKey, epoch)
+
+	req := logical.TestRequest(t, logical.CreateOperation, "accounts/exits/approve")
+	req.Storage = s
+	req.EntityID = "approver"
+	req.Data = map[string]interface{}{
+		"id": id,
+	}
+	resp, err := b.HandleRequest(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, ExitRequestApproved, resp.Data["status"])
+	return id
+}
+
 func TestVoluntaryExit(t *testing.T) {
 	b, _ := getBackend(t)
 
@@ -47,10 +76,49 @@ func TestVoluntaryExit(t *testing.T) {
 		err := setupStorageWithWalletAndAccounts(req.Storage)
 		require.NoError(t, err)
 
+		approveVoluntaryExit(t, b, req.Storage, "0x95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcf", 1)
+
 		req.Data = basicVoluntaryExitData(false)
 		resp, err := b.HandleRequest(context.Background(), req)
 		require.NoError(t, err)
 		require.NotNil(t, resp.Data)
+
+		// The approval is consumed by the signature
+		_, err = b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "failed to sign: no approved voluntary exit request")
+	})
+
+	t.Run("Sign voluntary exit without approval", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign-voluntary-exit")
+		setupBaseStorage(t, req)
+
+		// setup storage
+		err := setupStorageWithWalletAndAccounts(req.Storage)
+		require.NoError(t, err)
+
+		// A proposed but not approved exit can not be signed
+		proposeVoluntaryExit(t, b, req.Storage, "0x95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcf", 1)
+
+		req.Data = basicVoluntaryExitData(false)
+		resp, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "failed to sign: no approved voluntary exit request")
+		require.Nil(t, resp)
+	})
+
+	t.Run("Sign voluntary exit approved for another epoch", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign-voluntary-exit")
+		setupBaseStorage(t, req)
+
+		// setup storage
+		err := setupStorageWithWalletAndAccounts(req.Storage)
+		require.NoError(t, err)
+
+		approveVoluntaryExit(t, b, req.Storage, "0x95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcf", 2)
+
+		req.Data = basicVoluntaryExitData(false)
+		resp, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "failed to sign: no approved voluntary exit request")
+		require.Nil(t, resp)
 	})
 
 	t.Run("Sign voluntary exit of unknown account", func(t *testing.T) {
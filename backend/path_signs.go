@@ -3,10 +3,13 @@ package backend
 import (
 	"context"
 	"encoding/hex"
-	"sync"
+	"time"
 
+	"github.com/attestantio/go-eth2-client/api"
+	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/bellatrix"
-	vault "github.com/bloxapp/eth2-key-manager"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/bloxapp/eth2-key-manager/core"
 	"github.com/bloxapp/eth2-key-manager/signer"
 	slashingprotection "github.com/bloxapp/eth2-key-manager/slashing_protection"
 	"github.com/ethereum/go-ethereum/common"
@@ -36,6 +39,7 @@ func signsPaths(b *backend) []*framework.Path {
 					Description: "SSZ Serialized sign request object",
 					Default:     "",
 				},
+				"encoding": signRequestEncodingField(),
 			},
 			ExistenceCheck: b.pathExistenceCheck,
 			Operations: map[logical.Operation]framework.OperationHandler{
@@ -55,105 +59,255 @@ func (b *backend) pathSign(ctx context.Context, req *logical.Request, data *fram
 	}
 
 	// Parse request data
-	reqEncoded := data.Get("sign_req").(string)
-	reqByts, err := hex.DecodeString(reqEncoded)
+	signReq, err := b.decodeSignRequest(data.Get("encoding").(string), data.Get("sign_req").(string))
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to decode sign request hex")
+		return nil, err
 	}
 
-	signReq := &models.SignRequest{}
-	if err := b.encoder.Decode(reqByts, signReq); err != nil {
-		return nil, errors.Wrap(err, "failed to unmarshal sign request")
+	sig, root, err := b.sign(ctx, req.Storage, config, signReq)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign")
 	}
 
-	var sig []byte
-	err = b.lock(signReq.GetPublicKey(), func() error {
-		// bring up KeyVault and wallet
-		storage := store.NewHashicorpVaultStore(ctx, req.Storage, config.Network)
-		options := vault.KeyVaultOptions{}
-		options.SetStorage(storage)
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"signature":    hex.EncodeToString(sig),
+			"signing_root": hex.EncodeToString(root[:]),
+		},
+	}, nil
+}
 
-		// Open wallet
-		kv, err := vault.OpenKeyVault(&options)
-		if err != nil {
-			return errors.Wrap(err, "failed to open key vault")
-		}
+// sign signs the given request under the public key lock with slashing protection.
+// It returns the signature and the signing root computed from the request object.
+func (b *backend) sign(ctx context.Context, s logical.Storage, config *Config, signReq *models.SignRequest) ([]byte, phase0.Root, error) {
+	var (
+		sig  []byte
+		root phase0.Root
+	)
+	storage, wallet, err := b.openWallet(ctx, s, config)
+	if err != nil {
+		return nil, phase0.Root{}, err
+	}
+
+	err = b.lock(wallet, signReq.GetPublicKey(), func() error {
+		sig, root, err = signWithWallet(storage, wallet, config, signReq, b.now())
+		return err
+	})
+	return sig, root, err
+}
+
+// signWithWallet signs the given request using the given wallet, the caller must hold the public key lock.
+// The signing root and the signature domain are verified against the request object before any slashing protection data is touched.
+func signWithWallet(storage *store.HashicorpVaultStore, wallet core.Wallet, config *Config, signReq *models.SignRequest, now time.Time) ([]byte, phase0.Root, error) {
+	root, err := verifySigningRoot(signReq)
+	if err != nil {
+		return nil, phase0.Root{}, err
+	}
+	if err := validateSignatureDomain(config, signReq); err != nil {
+		return nil, phase0.Root{}, err
+	}
+	if err := checkAccountEnabled(storage, signReq.PublicKey); err != nil {
+		return nil, phase0.Root{}, err
+	}
+	if err := validateWallClock(config, signReq, now); err != nil {
+		return nil, phase0.Root{}, err
+	}
+	if err := checkQuarantine(storage, config, signReq, now); err != nil {
+		return nil, phase0.Root{}, err
+	}
+
+	if err := checkFarFuture(storage.Network(), signReq); err != nil {
+		return nil, phase0.Root{}, err
+	}
 
-		wallet, err := kv.Wallet()
+	var (
+		protector    = slashingProtector(storage, config, root)
+		simpleSigner = signer.NewSimpleSigner(wallet, protector, storage.Network())
+		sig          []byte
+		sigErr       error
+	)
+
+	switch t := signReq.GetObject().(type) {
+	case *models.SignRequestBlock:
+		sig, sigErr = signBeaconBlock(simpleSigner, t.VersionedBeaconBlock, signReq.SignatureDomain, signReq.PublicKey)
+	case *models.SignRequestBlindedBlock:
+		sig, sigErr = signBlindedBeaconBlock(simpleSigner, t.VersionedBlindedBeaconBlock, signReq.SignatureDomain, signReq.PublicKey)
+	case *models.SignRequestBlockHeader:
+		sig, _, sigErr = simpleSigner.SignBlock(t.BeaconBlockHeader, t.BeaconBlockHeader.Slot, signReq.SignatureDomain, signReq.PublicKey)
+	case *models.SignRequestAttestationData:
+		sig, _, sigErr = simpleSigner.SignBeaconAttestation(t.AttestationData, signReq.SignatureDomain, signReq.PublicKey)
+	case *models.SignRequestSlot:
+		sig, _, sigErr = simpleSigner.SignSlot(t.Slot, signReq.SignatureDomain, signReq.PublicKey)
+	case *models.SignRequestEpoch:
+		sig, _, sigErr = simpleSigner.SignEpoch(t.Epoch, signReq.SignatureDomain, signReq.PublicKey)
+	case *models.SignRequestAggregateAttestationAndProof:
+		sig, sigErr = signAggregateAndProof(wallet, simpleSigner, t, signReq.SignatureDomain, signReq.PublicKey)
+	case *models.SignRequestSyncCommitteeMessage:
+		sig, _, sigErr = simpleSigner.SignSyncCommittee(t.Root, signReq.SignatureDomain, signReq.PublicKey)
+	case *models.SignRequestSyncAggregatorSelectionData:
+		sig, _, sigErr = simpleSigner.SignSyncCommitteeSelectionData(t.SyncAggregatorSelectionData, signReq.SignatureDomain, signReq.PublicKey)
+	case *models.SignRequestContributionAndProof:
+		sig, _, sigErr = simpleSigner.SignSyncCommitteeContributionAndProof(t.ContributionAndProof, signReq.SignatureDomain, signReq.PublicKey)
+	case *models.SignRequestRegistration:
+		feeRecipient, err := t.VersionedValidatorRegistration.FeeRecipient()
 		if err != nil {
-			return errors.Wrap(err, "failed to retrieve wallet")
+			return nil, phase0.Root{}, errors.Wrap(err, "failed to get fee recipient")
+		}
+		validateErr := validateRequestedFeeRecipient(signReq.PublicKey, config.FeeRecipients, feeRecipient)
+		if validateErr != nil {
+			return nil, phase0.Root{}, errors.Wrap(validateErr, "refused to sign")
 		}
+		sig, _, sigErr = simpleSigner.SignRegistration(t.VersionedValidatorRegistration, signReq.SignatureDomain, signReq.PublicKey)
+	default:
+		return nil, phase0.Root{}, ErrSignRequestNotSupported
+	}
 
-		var (
-			protector                           = slashingprotection.NewNormalProtection(storage)
-			simpleSigner signer.ValidatorSigner = signer.NewSimpleSigner(wallet, protector, storage.Network())
-			sigErr       error
-		)
-
-		switch t := signReq.GetObject().(type) {
-		case *models.SignRequestBlock:
-			sig, _, sigErr = simpleSigner.SignBeaconBlock(t.VersionedBeaconBlock, signReq.SignatureDomain, signReq.PublicKey)
-		case *models.SignRequestBlindedBlock:
-			sig, _, sigErr = simpleSigner.SignBlindedBeaconBlock(t.VersionedBlindedBeaconBlock, signReq.SignatureDomain, signReq.PublicKey)
-		case *models.SignRequestAttestationData:
-			sig, _, sigErr = simpleSigner.SignBeaconAttestation(t.AttestationData, signReq.SignatureDomain, signReq.PublicKey)
-		case *models.SignRequestSlot:
-			sig, _, sigErr = simpleSigner.SignSlot(t.Slot, signReq.SignatureDomain, signReq.PublicKey)
-		case *models.SignRequestEpoch:
-			sig, _, sigErr = simpleSigner.SignEpoch(t.Epoch, signReq.SignatureDomain, signReq.PublicKey)
-		case *models.SignRequestAggregateAttestationAndProof:
-			sig, _, sigErr = simpleSigner.SignAggregateAndProof(t.AggregateAttestationAndProof, signReq.SignatureDomain, signReq.PublicKey)
-		case *models.SignRequestSyncCommitteeMessage:
-			sig, _, sigErr = simpleSigner.SignSyncCommittee(t.Root, signReq.SignatureDomain, signReq.PublicKey)
-		case *models.SignRequestSyncAggregatorSelectionData:
-			sig, _, sigErr = simpleSigner.SignSyncCommitteeSelectionData(t.SyncAggregatorSelectionData, signReq.SignatureDomain, signReq.PublicKey)
-		case *models.SignRequestContributionAndProof:
-			sig, _, sigErr = simpleSigner.SignSyncCommitteeContributionAndProof(t.ContributionAndProof, signReq.SignatureDomain, signReq.PublicKey)
-		case *models.SignRequestRegistration:
-			feeRecipient, err := t.VersionedValidatorRegistration.FeeRecipient()
-			if err != nil {
-				return errors.Wrap(err, "failed to get fee recipient")
-			}
-			validateErr := validateRequestedFeeRecipient(signReq.PublicKey, config.FeeRecipients, feeRecipient)
-			if validateErr != nil {
-				return errors.Wrap(validateErr, "refused to sign")
-			}
-			sig, _, sigErr = simpleSigner.SignRegistration(t.VersionedValidatorRegistration, signReq.SignatureDomain, signReq.PublicKey)
-		default:
-			return errors.New("sign request: not supported")
+	// Some tests rely on the error message returned by SignBeaconBlock,
+	// so this error should not be wrapped!
+	if sigErr != nil {
+		if protector.Refused() {
+			return nil, phase0.Root{}, &signError{err: sigErr, kind: ErrSlashable}
 		}
+		return nil, phase0.Root{}, sigErr
+	}
+	return sig, root, nil
+}
 
-		// Some tests rely on the error message returned by SignBeaconBlock,
-		// so this error should not be wrapped!
-		return sigErr
-	})
+// signBeaconBlock signs the given block. The signer doesn't know electra blocks,
+// they are signed with SignBlock, which applies the same slashing protection.
+func signBeaconBlock(simpleSigner *signer.SimpleSigner, block *spec.VersionedBeaconBlock, domain phase0.Domain, pubKey []byte) ([]byte, error) {
+	if block.Version != spec.DataVersionElectra {
+		sig, _, err := simpleSigner.SignBeaconBlock(block, domain, pubKey)
+		return sig, err
+	}
+	if block.Electra == nil {
+		return nil, errors.New("no electra block")
+	}
+	sig, _, err := simpleSigner.SignBlock(block.Electra, block.Electra.Slot, domain, pubKey)
+	return sig, err
+}
+
+// signBlindedBeaconBlock signs the given blinded block, like signBeaconBlock.
+func signBlindedBeaconBlock(simpleSigner *signer.SimpleSigner, block *api.VersionedBlindedBeaconBlock, domain phase0.Domain, pubKey []byte) ([]byte, error) {
+	if block.Version != spec.DataVersionElectra {
+		sig, _, err := simpleSigner.SignBlindedBeaconBlock(block, domain, pubKey)
+		return sig, err
+	}
+	if block.Electra == nil {
+		return nil, errors.New("no electra blinded block")
+	}
+	sig, _, err := simpleSigner.SignBlock(block.Electra, block.Electra.Slot, domain, pubKey)
+	return sig, err
+}
+
+// signAggregateAndProof signs the given aggregate. Electra aggregates are signed with the validation key
+// like the signer signs the phase0 ones, aggregates have no slashing protection.
+func signAggregateAndProof(wallet core.Wallet, simpleSigner *signer.SimpleSigner, agg *models.SignRequestAggregateAttestationAndProof, domain phase0.Domain, pubKey []byte) ([]byte, error) {
+	if agg.Version < spec.DataVersionElectra {
+		sig, _, err := simpleSigner.SignAggregateAndProof(agg.AggregateAttestationAndProof, domain, pubKey)
+		return sig, err
+	}
+	if agg.ElectraAggregateAndProof == nil {
+		return nil, errors.New("no electra aggregate and proof")
+	}
+
+	account, err := wallet.AccountByPublicKey(hex.EncodeToString(pubKey))
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to sign")
+		return nil, err
 	}
+	root, err := signer.ComputeETHSigningRoot(agg.ElectraAggregateAndProof, domain)
+	if err != nil {
+		return nil, err
+	}
+	return account.ValidationKeySign(root[:])
+}
 
-	return &logical.Response{
-		Data: map[string]interface{}{
-			"signature": hex.EncodeToString(sig),
-		},
-	}, nil
+var (
+	// ErrSlashable is matched by errors of sign requests refused by the slashing protection.
+	ErrSlashable = errors.New("slashable sign request")
+
+	// ErrFarFuture is returned when the epoch or the slot of a sign request is too far into the future.
+	ErrFarFuture = errors.New("too far into the future")
+
+	// ErrSignRequestNotSupported is returned when the sign request object isn't supported.
+	ErrSignRequestNotSupported = errors.New("sign request: not supported")
+)
+
+// signError is an error of the given kind which keeps the message of the underlying error.
+type signError struct {
+	err  error
+	kind error
+}
+
+func (e *signError) Error() string {
+	return e.err.Error()
+}
+
+func (e *signError) Unwrap() error {
+	return e.err
+}
+
+func (e *signError) Is(target error) bool {
+	return target == e.kind
 }
 
-func (b *backend) lock(pubKeyBytes []byte, cb func() error) error {
-	lock := func() *sync.Mutex {
-		b.signMapLock.Lock()
-		defer b.signMapLock.Unlock()
-		pubKey := hex.EncodeToString(pubKeyBytes)
-		if _, ok := b.signLock[pubKey]; !ok {
-			b.signLock[pubKey] = &sync.Mutex{}
+// checkFarFuture refuses attestations and blocks too far into the future, like the signer does,
+// so they are refused with ErrFarFuture.
+func checkFarFuture(network core.Network, signReq *models.SignRequest) error {
+	var slot phase0.Slot
+	switch t := signReq.GetObject().(type) {
+	case *models.SignRequestAttestationData:
+		if !signer.IsValidFarFutureEpoch(network, t.AttestationData.Target.Epoch) {
+			return errors.Wrap(ErrFarFuture, "target epoch")
+		}
+		if !signer.IsValidFarFutureEpoch(network, t.AttestationData.Source.Epoch) {
+			return errors.Wrap(ErrFarFuture, "source epoch")
+		}
+		return nil
+	case *models.SignRequestBlock:
+		s, err := t.VersionedBeaconBlock.Slot()
+		if err != nil {
+			return nil
+		}
+		slot = s
+	case *models.SignRequestBlindedBlock:
+		s, err := t.VersionedBlindedBeaconBlock.Slot()
+		if err != nil {
+			return nil
 		}
-		return b.signLock[pubKey]
-	}()
+		slot = s
+	case *models.SignRequestBlockHeader:
+		slot = t.BeaconBlockHeader.Slot
+	default:
+		return nil
+	}
+	if !signer.IsValidFarFutureSlot(network, slot) {
+		return errors.Wrap(ErrFarFuture, "proposed block slot")
+	}
+	return nil
+}
 
-	lock.Lock()
-	err := cb()
-	lock.Unlock()
+// slashingProtector returns the slashing protector of a sign request of the given signing root.
+// The highest proposal and attestation may be re-signed with the same signing root.
+func slashingProtector(storage *store.HashicorpVaultStore, config *Config, root phase0.Root) *store.SigningRootProtection {
+	var protector core.SlashingProtector = slashingprotection.NewNormalProtection(storage)
+	if config.AttestationHistory {
+		protector = store.NewFullProtection(storage, root, phase0.Epoch(config.AttestationHistoryEpochs))
+	}
+	return store.NewSigningRootProtection(protector, storage, root)
+}
+
+// lock runs the given callback holding the lock of the given public key.
+// Locks are only taken for public keys of accounts in the given wallet, so unknown keys never enter the lock table.
+func (b *backend) lock(wallet core.Wallet, pubKeyBytes []byte, cb func() error) error {
+	pubKey := hex.EncodeToString(pubKeyBytes)
+	if _, err := wallet.AccountByPublicKey(pubKey); err != nil {
+		return err
+	}
 
-	return err
+	unlock := b.signLocks.lock(pubKey)
+	defer unlock()
+	return cb()
 }
 
 var (
@@ -11,9 +11,12 @@ import (
 	eth2apiv1 "github.com/attestantio/go-eth2-client/api/v1"
 	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/electra"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/bloxapp/eth2-key-manager/signer"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/herumi/bls-eth-go-binary/bls"
 	"github.com/prysmaticlabs/go-bitfield"
 	"github.com/stretchr/testify/require"
 
@@ -70,6 +73,41 @@ func basicAggregationAndProofDataWithOps(undefinedPubKey bool) map[string]interf
 	}
 }
 
+func basicElectraAggregationAndProofData(undefinedPubKey bool) (map[string]interface{}, *electra.AggregateAndProof) {
+	committeeBits := bitfield.NewBitvector64()
+	committeeBits.SetBitAt(12, true)
+	agg := &electra.AggregateAndProof{
+		AggregatorIndex: phase0.ValidatorIndex(1),
+		Aggregate: &electra.Attestation{
+			AggregationBits: bitfield.NewBitlist(12),
+			Data: &phase0.AttestationData{
+				Slot:   phase0.Slot(1),
+				Source: &phase0.Checkpoint{Epoch: phase0.Epoch(1)},
+				Target: &phase0.Checkpoint{Epoch: phase0.Epoch(1)},
+			},
+			CommitteeBits: committeeBits,
+		},
+	}
+
+	req := &models.SignRequest{
+		PublicKey:       _byteArray("95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcf"),
+		SignatureDomain: _byteArray32("01000000f071c66c6561d0b939feb15f513a019d99a84bd85635221e3ad42dac"),
+		Object: &models.SignRequestAggregateAttestationAndProof{
+			Version:                  spec.DataVersionElectra,
+			ElectraAggregateAndProof: agg,
+		},
+	}
+
+	if undefinedPubKey {
+		req.PublicKey = _byteArray("95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcd")
+	}
+
+	byts, _ := encoder.New().Encode(req)
+	return map[string]interface{}{
+		"sign_req": hex.EncodeToString(byts),
+	}, agg
+}
+
 func TestSignAttestation(t *testing.T) {
 	b, _ := getBackend(t)
 
@@ -176,6 +214,37 @@ func TestSignAggregation(t *testing.T) {
 		require.EqualError(t, err, "failed to sign: account not found")
 		require.Nil(t, resp)
 	})
+
+	t.Run("Successfully Sign Electra Aggregation", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		var agg *electra.AggregateAndProof
+		req.Data, agg = basicElectraAggregationAndProofData(false)
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+
+		// The signature is of the electra container, with the committee bits
+		root, err := signer.ComputeETHSigningRoot(agg, _byteArray32("01000000f071c66c6561d0b939feb15f513a019d99a84bd85635221e3ad42dac"))
+		require.NoError(t, err)
+		require.Equal(t, hex.EncodeToString(root[:]), res.Data["signing_root"])
+		pubKey := &bls.PublicKey{}
+		require.NoError(t, pubKey.DeserializeHexStr("95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcf"))
+		sig := &bls.Sign{}
+		require.NoError(t, sig.DeserializeHexStr(res.Data["signature"].(string)))
+		require.True(t, sig.VerifyByte(pubKey, root[:]))
+	})
+
+	t.Run("Sign Electra Aggregation of unknown account", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data, _ = basicElectraAggregationAndProofData(true)
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "failed to sign: account not found")
+	})
 }
 
 func TestSignRegistration(t *testing.T) {
@@ -217,6 +286,64 @@ func TestSignRegistration(t *testing.T) {
 	})
 }
 
+func TestSignVerifiesSigningRoot(t *testing.T) {
+	b, _ := getBackend(t)
+
+	attestationSignReq := func(blockRoot string, signingRoot []byte) map[string]interface{} {
+		byts, err := encoder.New().Encode(&models.SignRequest{
+			PublicKey:       _byteArray("95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcf"),
+			SigningRoot:     signingRoot,
+			SignatureDomain: _byteArray32("01000000f071c66c6561d0b939feb15f513a019d99a84bd85635221e3ad42dac"),
+			Object: &models.SignRequestAttestationData{AttestationData: &phase0.AttestationData{
+				Slot:            284115,
+				Index:           2,
+				BeaconBlockRoot: _byteArray32(blockRoot),
+				Source:          &phase0.Checkpoint{Epoch: 77, Root: _byteArray32("7402fdc1ce16d449d637c34a172b349a12b2bae8d6d77e401006594d8057c33d")},
+				Target:          &phase0.Checkpoint{Epoch: 78, Root: _byteArray32("17959acc370274756fa5e9fdd7e7adf17204f49cc8457e49438c42c4883cbfb0")},
+			}},
+		})
+		require.NoError(t, err)
+		return map[string]interface{}{
+			"sign_req": hex.EncodeToString(byts),
+		}
+	}
+
+	t.Run("Sign with mismatching signing root", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data = attestationSignReq("7b5679277ca45ea74e1deebc9d3e8c0e7d6c570b3cfaf6884be144a81dac9a0e", bytes.Repeat([]byte{1}, 32))
+		_, err := b.HandleRequest(context.Background(), req)
+		require.Error(t, err)
+		require.ErrorIs(t, err, ErrSigningRootMismatch)
+
+		// The refused request must not touch the slashing protection data,
+		// so a conflicting attestation for the same target is still signable.
+		req.Data = attestationSignReq("7b5679277ca45ea74e1deebc9d3e8c0e7d6c570b3cfaf6884be144a81dac9a0d", nil)
+		_, err = b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+	})
+
+	t.Run("Sign with matching signing root", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data = attestationSignReq("7b5679277ca45ea74e1deebc9d3e8c0e7d6c570b3cfaf6884be144a81dac9a0e", nil)
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		signingRoot := res.Data["signing_root"].(string)
+		require.Len(t, signingRoot, 64)
+
+		// Signing the same attestation again with the returned root is accepted
+		req.Data = attestationSignReq("7b5679277ca45ea74e1deebc9d3e8c0e7d6c570b3cfaf6884be144a81dac9a0e", _byteArray(signingRoot))
+		res2, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, signingRoot, res2.Data["signing_root"])
+	})
+}
+
 func TestValidateRequestedFeeRecipient(t *testing.T) {
 	recipient := func(lastByte byte) string { return hexutil.Encode(append(bytes.Repeat([]byte{0}, 19), lastByte)) }
 	pubKey := func(lastByte byte) string { return hexutil.Encode(append(bytes.Repeat([]byte{0}, 95), lastByte)) }
@@ -316,3 +443,53 @@ func TestValidateRequestedFeeRecipient(t *testing.T) {
 		})
 	}
 }
+
+func TestSignJSONEncoding(t *testing.T) {
+	b, _ := getBackend(t)
+
+	// The same attestation in the V2 and the JSON encodings
+	v2Req := basicAttestationData()
+	v2Byts, err := hex.DecodeString(v2Req["sign_req"].(string))
+	require.NoError(t, err)
+	signReq := &models.SignRequest{}
+	require.NoError(t, encoder.New().Decode(v2Byts, signReq))
+	jsonByts, err := encoder.NewJSON().Encode(signReq)
+	require.NoError(t, err)
+
+	sign := func(t *testing.T, data map[string]interface{}) (*logical.Response, error) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+		req.Data = data
+		return b.HandleRequest(context.Background(), req)
+	}
+
+	t.Run("Sign JSON encoded request", func(t *testing.T) {
+		v2Res, err := sign(t, v2Req)
+		require.NoError(t, err)
+
+		jsonRes, err := sign(t, map[string]interface{}{
+			"sign_req": string(jsonByts),
+			"encoding": encoder.EncodingJSON,
+		})
+		require.NoError(t, err)
+		require.Equal(t, v2Res.Data["signature"], jsonRes.Data["signature"])
+		require.Equal(t, v2Res.Data["signing_root"], jsonRes.Data["signing_root"])
+	})
+
+	t.Run("Sign V2 request as JSON", func(t *testing.T) {
+		_, err := sign(t, map[string]interface{}{
+			"sign_req": v2Req["sign_req"],
+			"encoding": encoder.EncodingJSON,
+		})
+		require.ErrorContains(t, err, "failed to unmarshal sign request")
+	})
+
+	t.Run("Sign with unsupported encoding", func(t *testing.T) {
+		_, err := sign(t, map[string]interface{}{
+			"sign_req": string(jsonByts),
+			"encoding": "ssz",
+		})
+		require.Error(t, err)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 
 	"github.com/bloxapp/eth2-key-manager/stores/inmemory"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/pkg/errors"
@@ -30,6 +31,14 @@ func storagePaths(b *backend) []*framework.Path {
 					Type:        framework.TypeString,
 					Description: "storage to update",
 				},
+				"override": {
+					Type:        framework.TypeBool,
+					Description: "Write accounts conflicting with stored ones, replacing the stored accounts of the same public key, name or index",
+				},
+				"dry_run": {
+					Type:        framework.TypeBool,
+					Description: "Return the result of the update of each account without writing anything",
+				},
 			},
 			ExistenceCheck: b.pathExistenceCheck,
 			Operations: map[logical.Operation]framework.OperationHandler{
@@ -64,15 +73,116 @@ func (b *backend) pathStorageUpdate(ctx context.Context, req *logical.Request, d
 		return nil, errors.Wrap(err, "failed to build in memory store")
 	}
 
-	// Update hashicorp store with new account(s)
-	_, err = store.FromInMemoryStoreV2(ctx, inMemStore, req.Storage)
+	// Load config
+	config, err := b.readConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get config")
+	}
+
+	return b.updateStorage(ctx, req.Storage, config, inMemStore, data.Get("override").(bool), data.Get("dry_run").(bool))
+}
+
+// updateStorage adds the accounts of the given in-memory store to the storage and quarantines the new ones,
+// and returns the result of the update of each account.
+func (b *backend) updateStorage(ctx context.Context, s logical.Storage, config *Config, inMemStore *inmemory.InMemStore, override, dryRun bool) (*logical.Response, error) {
+	if dryRun {
+		updates, err := store.PlanStorageUpdate(ctx, inMemStore, s)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to plan storage update")
+		}
+		return &logical.Response{
+			Data: map[string]interface{}{
+				"status":   true,
+				"dry_run":  true,
+				"accounts": accountUpdatesList(updates),
+			},
+		}, nil
+	}
+
+	// Storage updates are applied at once or not at all, see store.UpdateTransactionally.
+	b.storageLock.Lock()
+	defer b.storageLock.Unlock()
+	defer b.walletCache.reset()
+
+	var updates []*store.AccountUpdate
+	err := store.UpdateTransactionally(ctx, s, func(s logical.Storage) error {
+		existing, err := accountPublicKeys(ctx, s, config)
+		if err != nil {
+			return err
+		}
+
+		// Quarantine the new accounts before they are saved, they may have been signing elsewhere until now.
+		// A conflicting update is refused by FromInMemoryStoreV2 and rolled back along with the quarantine.
+		planned, err := store.PlanStorageUpdate(ctx, inMemStore, s)
+		if err != nil {
+			return errors.Wrap(err, "failed to plan storage update")
+		}
+		var added [][]byte
+		for _, update := range planned {
+			if _, ok := existing[hex.EncodeToString(update.PublicKey)]; !ok {
+				added = append(added, update.PublicKey)
+			}
+		}
+		if err := quarantineAccounts(store.NewHashicorpVaultStore(ctx, s, config.Network), config, added, b.now()); err != nil {
+			return err
+		}
+
+		// Update hashicorp store with new account(s)
+		_, updates, err = store.FromInMemoryStoreV2(ctx, inMemStore, s, override)
+		if err != nil {
+			return errors.Wrap(err, "failed to update storage from in memory")
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to update storage from in memory")
+		return nil, err
 	}
 
 	return &logical.Response{
 		Data: map[string]interface{}{
-			"status": true,
+			"status":   true,
+			"dry_run":  false,
+			"accounts": accountUpdatesList(updates),
 		},
 	}, nil
 }
+
+// accountUpdatesList returns a list representation of the given account updates.
+func accountUpdatesList(updates []*store.AccountUpdate) []map[string]interface{} {
+	ret := make([]map[string]interface{}, 0, len(updates))
+	for _, update := range updates {
+		u := map[string]interface{}{
+			"public_key": hexutil.Encode(update.PublicKey),
+			"name":       update.Name,
+			"index":      update.Index,
+			"result":     update.Result,
+		}
+		if update.Conflict != "" {
+			u["conflict"] = update.Conflict
+		}
+		ret = append(ret, u)
+	}
+	return ret
+}
+
+// accountPublicKeys returns the validator public keys of the stored accounts, by their hex encoding.
+func accountPublicKeys(ctx context.Context, s logical.Storage, config *Config) (map[string][]byte, error) {
+	pubKeys := make(map[string][]byte)
+
+	entry, err := s.Get(ctx, store.WalletDataPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get wallet data")
+	}
+	if entry == nil {
+		return pubKeys, nil
+	}
+
+	accounts, err := store.NewHashicorpVaultStore(ctx, s, config.Network).ListAccounts()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list accounts")
+	}
+	for _, a := range accounts {
+		pubKeys[hex.EncodeToString(a.ValidatorPublicKey())] = a.ValidatorPublicKey()
+	}
+	return pubKeys, nil
+}
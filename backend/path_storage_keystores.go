@@ -0,0 +1,281 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/bloxapp/eth2-key-manager/core"
+	"github.com/bloxapp/eth2-key-manager/encryptor/keystorev4"
+	"github.com/bloxapp/eth2-key-manager/stores/inmemory"
+	"github.com/bloxapp/eth2-key-manager/wallets/hd"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+
+	"github.com/bloxapp/key-vault/backend/store"
+)
+
+// Endpoints patterns
+const (
+	// StorageKeystoresPattern is the path pattern for keystores import endpoint
+	StorageKeystoresPattern = "storage/keystores"
+)
+
+// Keystore is an EIP-2335 keystore.
+type Keystore struct {
+	Crypto  map[string]interface{} `json:"crypto"`
+	PubKey  string                 `json:"pubkey"`
+	Path    string                 `json:"path"`
+	Version uint                   `json:"version"`
+}
+
+func storageKeystoresPaths(b *backend) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         StorageKeystoresPattern,
+			HelpSynopsis:    "Import EIP-2335 keystores",
+			HelpDescription: `Decrypt EIP-2335 keystores and add them as accounts, along with their EIP-3076 slashing protection`,
+			Fields: map[string]*framework.FieldSchema{
+				"keystores": {
+					Type:        framework.TypeSlice,
+					Description: "EIP-2335 keystores, as JSON objects or strings",
+				},
+				"passwords": {
+					Type:        framework.TypeSlice,
+					Description: "Passwords of the keystores, in the same order, or a single password of all of them",
+				},
+				"slashing_protection": {
+					Type:        framework.TypeString,
+					Description: "EIP-3076 interchange JSON of the keystores, in the complete or the minimal format. Keystores without records start at the current epoch and slot",
+				},
+				"override": {
+					Type:        framework.TypeBool,
+					Description: "Write accounts conflicting with stored ones, replacing the stored accounts of the same public key, name or index",
+				},
+				"dry_run": {
+					Type:        framework.TypeBool,
+					Description: "Return the result of the update of each account without writing anything",
+				},
+			},
+			ExistenceCheck: b.pathExistenceCheck,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.CreateOperation: &framework.PathOperation{
+					Callback: b.pathStorageKeystoresImport,
+				},
+			},
+		},
+	}
+}
+
+// pathStorageKeystoresImport adds the accounts of the given keystores to the storage, like pathStorageUpdate.
+func (b *backend) pathStorageKeystoresImport(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	// The keystores and passwords are JSON arrays: strings are never split on commas.
+	keystores := make([]string, 0)
+	for i, v := range data.Get("keystores").([]interface{}) {
+		switch keystore := v.(type) {
+		case string:
+			keystores = append(keystores, keystore)
+		case map[string]interface{}:
+			byts, err := json.Marshal(keystore)
+			if err != nil {
+				return nil, errors.Errorf("invalid keystore %d provided", i)
+			}
+			keystores = append(keystores, string(byts))
+		default:
+			return nil, errors.Errorf("invalid keystore %d provided", i)
+		}
+	}
+	if len(keystores) == 0 {
+		return nil, errors.New("keystores is required")
+	}
+	passwords := make([]string, 0)
+	for i, v := range data.Get("passwords").([]interface{}) {
+		password, ok := v.(string)
+		if !ok {
+			return nil, errors.Errorf("invalid password %d provided", i)
+		}
+		passwords = append(passwords, password)
+	}
+	if len(passwords) != 1 && len(passwords) != len(keystores) {
+		return nil, errors.Errorf("%d passwords provided for %d keystores", len(passwords), len(keystores))
+	}
+
+	// Load config
+	config, err := b.readConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get config")
+	}
+
+	var maxima map[phase0.BLSPubKey]*interchangeMaxima
+	if slashingProtection := data.Get("slashing_protection").(string); slashingProtection != "" {
+		interchange, err := parseInterchange([]byte(slashingProtection), config.genesisValidatorsRoot())
+		if err != nil {
+			return nil, err
+		}
+		maxima = interchange.maxima()
+	}
+
+	storedIndexes, nextIndex, err := storedAccountIndexes(ctx, req.Storage, config)
+	if err != nil {
+		return nil, err
+	}
+
+	type decryptedKeystore struct {
+		keystore   *Keystore
+		privateKey []byte
+		index      int
+	}
+	decrypted := make([]*decryptedKeystore, 0, len(keystores))
+	indexes := make(map[int]int)
+	for i, keystoreJSON := range keystores {
+		password := passwords[0]
+		if len(passwords) > 1 {
+			password = passwords[i]
+		}
+		keystore, privateKey, index, err := decryptKeystore([]byte(keystoreJSON), password, config.Network)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decrypt keystore %d", i)
+		}
+		if index < 0 {
+			// Keystores of stored accounts keep their index
+			key, err := core.MasterKeyFromPrivateKey(privateKey, config.Network)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to decrypt keystore %d", i)
+			}
+			hdKey, err := key.Derive(fmt.Sprintf(hd.ValidatorKeyPath, 0))
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to decrypt keystore %d", i)
+			}
+			if storedIndex, ok := storedIndexes[hex.EncodeToString(hdKey.PublicKey().Serialize())]; ok {
+				index = storedIndex
+			}
+		}
+		if index >= 0 {
+			if j, ok := indexes[index]; ok {
+				return nil, errors.Errorf("keystores %d and %d have the same index %d", j, i, index)
+			}
+			indexes[index] = i
+			if index >= nextIndex {
+				nextIndex = index + 1
+			}
+		}
+		decrypted = append(decrypted, &decryptedKeystore{keystore: keystore, privateKey: privateKey, index: index})
+	}
+
+	// The keystores are added to an in-memory store, which is added to the storage.
+	now := b.now()
+	inMemStore := inmemory.NewInMemStore(config.Network)
+	wallet := hd.NewWallet(&core.WalletContext{Storage: inMemStore})
+	if err := inMemStore.SaveWallet(wallet); err != nil {
+		return nil, errors.Wrap(err, "failed to save wallet")
+	}
+	for i, d := range decrypted {
+		// Other keystores without an EIP-2334 validator path follow the stored and the given indexes.
+		if d.index < 0 {
+			d.index = nextIndex
+			nextIndex++
+		}
+		account, err := wallet.CreateValidatorAccountFromPrivateKey(d.privateKey, &d.index)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create account of keystore %d", i)
+		}
+		if d.keystore.PubKey != "" {
+			pubKey, err := hexutil.Decode(ensureHexPrefix(d.keystore.PubKey))
+			if err != nil || !bytes.Equal(pubKey, account.ValidatorPublicKey()) {
+				return nil, errors.Errorf("keystore %d public key %q does not match its secret", i, d.keystore.PubKey)
+			}
+		}
+
+		// The slashing protection of other public keys is ignored. Keystores without records may have been signing
+		// elsewhere until now, they start at the current epoch and slot: the stored records are never lowered.
+		var pubKey phase0.BLSPubKey
+		copy(pubKey[:], account.ValidatorPublicKey())
+		m, ok := maxima[pubKey]
+		if !ok {
+			m = &interchangeMaxima{}
+		}
+		if !m.HasAtts {
+			m.HasAtts = true
+			m.SourceEpoch = config.epochAt(now)
+			m.TargetEpoch = m.SourceEpoch
+		}
+		if m.Slot == 0 {
+			m.Slot = config.slotAt(now)
+		}
+		err = inMemStore.SaveHighestAttestation(pubKey[:], &phase0.AttestationData{
+			Source: &phase0.Checkpoint{Epoch: m.SourceEpoch},
+			Target: &phase0.Checkpoint{Epoch: m.TargetEpoch},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to save highest attestation")
+		}
+		if m.Slot > 0 {
+			if err := inMemStore.SaveHighestProposal(pubKey[:], m.Slot); err != nil {
+				return nil, errors.Wrap(err, "failed to save highest proposal")
+			}
+		}
+	}
+
+	return b.updateStorage(ctx, req.Storage, config, inMemStore, data.Get("override").(bool), data.Get("dry_run").(bool))
+}
+
+// decryptKeystore returns the given keystore, its secret and the index of its EIP-2334 validator path,
+// or -1 when its path isn't one.
+func decryptKeystore(keystoreJSON []byte, password string, network core.Network) (*Keystore, []byte, int, error) {
+	var keystore *Keystore
+	if err := json.Unmarshal(keystoreJSON, &keystore); err != nil || keystore == nil {
+		return nil, nil, 0, errors.New("failed to unmarshal keystore")
+	}
+	if keystore.Version != 4 {
+		return nil, nil, 0, errors.Errorf("unsupported keystore version %d", keystore.Version)
+	}
+
+	secret, err := keystorev4.New().Decrypt(keystore.Crypto, password)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	index := -1
+	var i int
+	if _, err := fmt.Sscanf(strings.TrimPrefix(keystore.Path, network.FullPath("")), hd.ValidatorKeyPath, &i); err == nil &&
+		keystore.Path == network.FullPath(fmt.Sprintf(hd.ValidatorKeyPath, i)) {
+		index = i
+	}
+	return keystore, secret, index, nil
+}
+
+// storedAccountIndexes returns the indexes of the stored accounts by their hex encoded public key,
+// and the index following the highest one.
+func storedAccountIndexes(ctx context.Context, s logical.Storage, config *Config) (map[string]int, int, error) {
+	indexes := make(map[string]int)
+	entry, err := s.Get(ctx, store.WalletDataPath)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to get wallet data")
+	}
+	if entry == nil {
+		return indexes, 0, nil
+	}
+
+	accounts, err := store.NewHashicorpVaultStore(ctx, s, config.Network).ListAccounts()
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to list accounts")
+	}
+	next := 0
+	for _, a := range accounts {
+		var index int
+		if _, err := fmt.Sscanf(a.BasePath(), hd.BaseAccountPath, &index); err != nil {
+			continue
+		}
+		indexes[hex.EncodeToString(a.ValidatorPublicKey())] = index
+		if index >= next {
+			next = index + 1
+		}
+	}
+	return indexes, next, nil
+}
@@ -0,0 +1,238 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/bloxapp/eth2-key-manager/core"
+	"github.com/bloxapp/eth2-key-manager/encryptor/keystorev4"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/herumi/bls-eth-go-binary/bls"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bloxapp/key-vault/backend/store"
+)
+
+// testKeystore returns an EIP-2335 keystore of the given secret and its public key.
+func testKeystore(t *testing.T, secretHex string, path string, password string) (string, string) {
+	require.NoError(t, core.InitBLS())
+	sk := &bls.SecretKey{}
+	require.NoError(t, sk.SetHexString(secretHex))
+	pubKey := hexutil.Encode(sk.GetPublicKey().Serialize())
+
+	crypto, err := keystorev4.New(keystorev4.WithCipher("pbkdf2")).Encrypt(sk.Serialize(), password)
+	require.NoError(t, err)
+	byts, err := json.Marshal(&Keystore{
+		Crypto:  crypto,
+		PubKey:  pubKey[2:],
+		Path:    path,
+		Version: 4,
+	})
+	require.NoError(t, err)
+	return string(byts), pubKey
+}
+
+func TestStorageKeystoresImport(t *testing.T) {
+	b, _ := getBackend(t)
+	b.(*backend).now = func() time.Time {
+		return testNow
+	}
+	keystore1, pubKey1 := testKeystore(t, "25295f0d1d592a90b333e26e85149708208e9f8e8bc18f6c77bd62f8ad7a6866", "m/12381/3600/5/0/0", "password1")
+	keystore2, pubKey2 := testKeystore(t, "1111111111111111111111111111111111111111111111111111111111111111", "", "password2")
+
+	importRequest := func(t *testing.T, s logical.Storage, data map[string]interface{}) (*logical.Response, error) {
+		req := logical.TestRequest(t, logical.CreateOperation, "storage/keystores")
+		req.Storage = s
+		req.Data = data
+		return b.HandleRequest(context.Background(), req)
+	}
+	readAccount := func(t *testing.T, s logical.Storage, pubKey string) map[string]interface{} {
+		req := logical.TestRequest(t, logical.ReadOperation, "accounts/"+pubKey)
+		req.Storage = s
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		return res.Data
+	}
+
+	t.Run("import keystores with slashing protection", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.ListOperation, "accounts/")
+		setupBaseStorage(t, req, withGenesisAtEpoch(78))
+		importBaseStorage(t, b, req.Storage)
+
+		res, err := importRequest(t, req.Storage, map[string]interface{}{
+			"keystores": []string{keystore1, keystore2},
+			"passwords": []string{"password1", "password2"},
+			"slashing_protection": `{"metadata":{"interchange_format_version":"5","genesis_validators_root":"0x043db0d9a83813551ee2f33450d23797757d430911a9320530ad8a0eabc43efb"},` +
+				`"data":[{"pubkey":"` + pubKey1 + `","signed_blocks":[{"slot":"81952"}],"signed_attestations":[{"source_epoch":"2290","target_epoch":"3007"}]}]}`,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []map[string]interface{}{
+			{"public_key": pubKey2, "name": "account-6", "index": 6, "result": store.AccountAdded},
+			{"public_key": pubKey1, "name": "account-5", "index": 5, "result": store.AccountAdded},
+		}, res.Data["accounts"])
+
+		res, err = b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Len(t, res.Data["accounts"], 3)
+
+		account := readAccount(t, req.Storage, pubKey1)
+		require.Equal(t, "m/12381/3600/5/0/0", account["validation_path"])
+		require.Equal(t, map[string]interface{}{
+			"source_epoch": phase0.Epoch(2290),
+			"target_epoch": phase0.Epoch(3007),
+			"signing_root": "",
+		}, account["highest_attestation"])
+		require.Equal(t, map[string]interface{}{
+			"slot":         phase0.Slot(81952),
+			"signing_root": "",
+		}, account["highest_proposal"])
+
+		// The keystore without slashing protection starts at the current epoch and slot
+		account = readAccount(t, req.Storage, pubKey2)
+		require.Equal(t, map[string]interface{}{
+			"source_epoch": phase0.Epoch(78),
+			"target_epoch": phase0.Epoch(78),
+			"signing_root": "",
+		}, account["highest_attestation"])
+		require.Equal(t, map[string]interface{}{
+			"slot":         phase0.Slot(78 * 32),
+			"signing_root": "",
+		}, account["highest_proposal"])
+
+		// Importing them again skips them
+		res, err = importRequest(t, req.Storage, map[string]interface{}{
+			"keystores": []string{keystore1, keystore2},
+			"passwords": []string{"password1", "password2"},
+		})
+		require.NoError(t, err)
+		for _, update := range res.Data["accounts"].([]map[string]interface{}) {
+			require.Equal(t, store.AccountSkipped, update["result"])
+		}
+	})
+
+	t.Run("sign after import without slashing protection", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req, withGenesisAtEpoch(76))
+
+		_, err := importRequest(t, req.Storage, map[string]interface{}{
+			"keystores": []interface{}{keystore2},
+			"passwords": []interface{}{"password2"},
+		})
+		require.NoError(t, err)
+
+		attestation := func(source, target phase0.Epoch) *phase0.AttestationData {
+			return &phase0.AttestationData{
+				Slot:   phase0.Slot(target * 32),
+				Source: &phase0.Checkpoint{Epoch: source},
+				Target: &phase0.Checkpoint{Epoch: target},
+			}
+		}
+		domain := _byteArray32("01000000f071c66c6561d0b939feb15f513a019d99a84bd85635221e3ad42dac")
+
+		// Attestations up to the current epoch may have been signed elsewhere
+		req.Data = reqObject(attestation(75, 76), domain, hexutil.MustDecode(pubKey2))
+		_, err = b.HandleRequest(context.Background(), req)
+		require.ErrorContains(t, err, "slashable attestation")
+
+		req.Data = reqObject(attestation(76, 77), domain, hexutil.MustDecode(pubKey2))
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.NotEmpty(t, res.Data["signature"])
+	})
+
+	t.Run("keystores as JSON objects", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.ListOperation, "accounts/")
+		setupBaseStorage(t, req)
+
+		var keystore map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(keystore2), &keystore))
+		res, err := importRequest(t, req.Storage, map[string]interface{}{
+			"keystores": []interface{}{keystore},
+			"passwords": "password2",
+		})
+		require.NoError(t, err)
+		require.Equal(t, pubKey2, res.Data["accounts"].([]map[string]interface{})[0]["public_key"])
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.ListOperation, "accounts/")
+		setupBaseStorage(t, req)
+		importBaseStorage(t, b, req.Storage)
+
+		res, err := importRequest(t, req.Storage, map[string]interface{}{
+			"keystores": []string{keystore2},
+			"passwords": []string{"password2"},
+			"dry_run":   true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []map[string]interface{}{
+			{"public_key": pubKey2, "name": "account-1", "index": 1, "result": store.AccountAdded},
+		}, res.Data["accounts"])
+
+		res, err = b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Len(t, res.Data["accounts"], 1)
+	})
+
+	t.Run("invalid keystores", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.ListOperation, "accounts/")
+		setupBaseStorage(t, req)
+		importBaseStorage(t, b, req.Storage)
+
+		_, err := importRequest(t, req.Storage, map[string]interface{}{
+			"passwords": []string{"password1"},
+		})
+		require.EqualError(t, err, "keystores is required")
+
+		_, err = importRequest(t, req.Storage, map[string]interface{}{
+			"keystores": []string{keystore1, keystore2},
+			"passwords": []string{"password1", "password2", "password3"},
+		})
+		require.EqualError(t, err, "3 passwords provided for 2 keystores")
+
+		_, err = importRequest(t, req.Storage, map[string]interface{}{
+			"keystores": []string{keystore1},
+			"passwords": []string{"password2"},
+		})
+		require.EqualError(t, err, "failed to decrypt keystore 0: invalid checksum")
+
+		var keystore map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(keystore1), &keystore))
+		keystore["pubkey"] = pubKey2
+		byts, err := json.Marshal(keystore)
+		require.NoError(t, err)
+		_, err = importRequest(t, req.Storage, map[string]interface{}{
+			"keystores": []string{string(byts)},
+			"passwords": []string{"password1"},
+		})
+		require.EqualError(t, err, "keystore 0 public key \""+pubKey2+"\" does not match its secret")
+
+		_, err = importRequest(t, req.Storage, map[string]interface{}{
+			"keystores": []interface{}{keystore1, 1},
+			"passwords": "password1",
+		})
+		require.EqualError(t, err, "invalid keystore 1 provided")
+
+		_, err = importRequest(t, req.Storage, map[string]interface{}{
+			"keystores": []string{keystore1},
+			"passwords": []interface{}{1},
+		})
+		require.EqualError(t, err, "invalid password 0 provided")
+
+		// A single password isn't split on commas
+		_, err = importRequest(t, req.Storage, map[string]interface{}{
+			"keystores": []string{keystore1, keystore2},
+			"passwords": "password1,password2",
+		})
+		require.EqualError(t, err, "failed to decrypt keystore 0: invalid checksum")
+
+		// Nothing is written
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Len(t, res.Data["accounts"], 1)
+	})
+}
@@ -7,7 +7,6 @@ import (
 	"sync"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
-	vault "github.com/bloxapp/eth2-key-manager"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/pkg/errors"
@@ -56,19 +55,9 @@ func (b *backend) pathMinimalSlashingStorageRead(ctx context.Context, req *logic
 	}
 
 	// bring up KeyVault and wallet
-	storage := store.NewHashicorpVaultStore(ctx, req.Storage, config.Network)
-	options := vault.KeyVaultOptions{}
-	options.SetStorage(storage)
-
-	// Open wallet
-	kv, err := vault.OpenKeyVault(&options)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to open key vault")
-	}
-
-	wallet, err := kv.Wallet()
+	storage, wallet, err := b.openWallet(ctx, req.Storage, config)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to retrieve wallet")
+		return nil, err
 	}
 
 	// Load accounts slashing history
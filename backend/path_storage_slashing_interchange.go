@@ -0,0 +1,238 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/bloxapp/eth2-key-manager/core"
+	slashingprotection "github.com/bloxapp/eth2-key-manager/slashing_protection"
+	"github.com/bloxapp/eth2-key-manager/wallets/hd"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+
+	"github.com/bloxapp/key-vault/backend/store"
+)
+
+// Endpoints patterns
+const (
+	// SlashingStorageImportPattern is the path pattern for slashing storage import endpoint
+	SlashingStorageImportPattern = "storage/slashing/import"
+
+	// SlashingStorageExportPattern is the path pattern for slashing storage export endpoint
+	SlashingStorageExportPattern = "storage/slashing/export"
+)
+
+func storageSlashingInterchangePaths(b *backend) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         SlashingStorageImportPattern,
+			HelpSynopsis:    "Import slashing protection interchange",
+			HelpDescription: `Import an EIP-3076 slashing protection interchange, the highest attestation and proposal of each account are raised to at least the imported ones`,
+			Fields: map[string]*framework.FieldSchema{
+				"interchange": {
+					Type:        framework.TypeString,
+					Description: "EIP-3076 interchange JSON, in the complete or the minimal format",
+				},
+			},
+			ExistenceCheck: b.pathExistenceCheck,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.CreateOperation: &framework.PathOperation{
+					Callback: b.pathSlashingStorageImport,
+				},
+			},
+		},
+		{
+			Pattern:         SlashingStorageExportPattern,
+			HelpSynopsis:    "Export slashing protection interchange",
+			HelpDescription: `Export an EIP-3076 slashing protection interchange in the minimal format, built from the highest attestation and proposal of each account`,
+			Fields: map[string]*framework.FieldSchema{
+				"public_keys": {
+					Type:        framework.TypeCommaStringSlice,
+					Description: "Hex encoded public keys to export, including the ones of deleted accounts, defaults to all accounts",
+				},
+			},
+			ExistenceCheck: b.pathExistenceCheck,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathSlashingStorageExport,
+				},
+			},
+		},
+	}
+}
+
+// pathSlashingStorageImport imports the slashing protection data of the given interchange.
+// Public keys without an account in the wallet are skipped.
+func (b *backend) pathSlashingStorageImport(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	// Load config
+	config, err := b.readConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get config")
+	}
+
+	interchange, err := parseInterchange([]byte(data.Get("interchange").(string)), config.genesisValidatorsRoot())
+	if err != nil {
+		return nil, err
+	}
+
+	storage, wallet, err := b.openWallet(ctx, req.Storage, config)
+	if err != nil {
+		return nil, err
+	}
+	protector := slashingprotection.NewNormalProtection(storage)
+
+	maxima := interchange.maxima()
+	pubKeys := make([]phase0.BLSPubKey, 0, len(maxima))
+	for pubKey := range maxima {
+		pubKeys = append(pubKeys, pubKey)
+	}
+	sort.Slice(pubKeys, func(i, j int) bool {
+		return bytes.Compare(pubKeys[i][:], pubKeys[j][:]) < 0
+	})
+
+	imported := make([]string, 0, len(pubKeys))
+	skipped := make([]string, 0)
+	for _, pubKey := range pubKeys {
+		m := maxima[pubKey]
+		err := b.lock(wallet, pubKey[:], func() error {
+			// The highest attestation and proposal are only ever raised.
+			if m.HasAtts {
+				if err := storage.RaiseAttestationWatermark(pubKey[:], m.SourceEpoch, m.TargetEpoch); err != nil {
+					return err
+				}
+			}
+			if m.Slot > 0 {
+				if err := protector.UpdateHighestProposal(pubKey[:], m.Slot); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		switch {
+		case errors.Is(err, hd.ErrAccountNotFound):
+			skipped = append(skipped, hex.EncodeToString(pubKey[:]))
+		case err != nil:
+			return nil, errors.Wrapf(err, "failed to import slashing protection of %x", pubKey[:])
+		default:
+			imported = append(imported, hex.EncodeToString(pubKey[:]))
+		}
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"imported": imported,
+			"skipped":  skipped,
+		},
+	}, nil
+}
+
+// pathSlashingStorageExport exports the slashing protection data of the requested accounts.
+func (b *backend) pathSlashingStorageExport(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	// Load config
+	config, err := b.readConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get config")
+	}
+
+	storage, wallet, err := b.openWallet(ctx, req.Storage, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var pubKeys [][]byte
+	for _, pubKeyHex := range data.Get("public_keys").([]string) {
+		pubKey, err := hexutil.Decode(ensureHexPrefix(pubKeyHex))
+		if err != nil || len(pubKey) != BLSPubkeyLength {
+			return nil, errors.Errorf("invalid public key %q provided", pubKeyHex)
+		}
+		pubKeys = append(pubKeys, pubKey)
+	}
+	if len(pubKeys) == 0 {
+		for _, account := range wallet.Accounts() {
+			pubKeys = append(pubKeys, account.ValidatorPublicKey())
+		}
+	}
+
+	interchange, err := b.exportInterchange(storage, wallet, config.genesisValidatorsRoot(), pubKeys)
+	if err != nil {
+		return nil, err
+	}
+	interchangeJSON, err := json.Marshal(interchange)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode interchange")
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"interchange": string(interchangeJSON),
+		},
+	}, nil
+}
+
+// exportInterchange returns a minimal format interchange of the given public keys, built from their highest attestation and proposal.
+// Public keys of deleted accounts are exported from their kept records.
+func (b *backend) exportInterchange(storage *store.HashicorpVaultStore, wallet core.Wallet, genesisValidatorsRoot phase0.Root, pubKeys [][]byte) (*Interchange, error) {
+	interchange := &Interchange{
+		Metadata: InterchangeMetadata{
+			InterchangeFormatVersion: InterchangeFormatVersion,
+			GenesisValidatorsRoot:    genesisValidatorsRoot,
+		},
+		Data: make([]*InterchangeData, 0, len(pubKeys)),
+	}
+	for _, pubKey := range pubKeys {
+		var d *InterchangeData
+		err := b.lock(wallet, pubKey, func() (err error) {
+			d, err = interchangeData(storage, pubKey)
+			return err
+		})
+		if errors.Is(err, hd.ErrAccountNotFound) {
+			// The records of deleted accounts are kept, they are read without a lock since nothing signs with them.
+			d, err = interchangeData(storage, pubKey)
+			if err == nil && len(d.SignedBlocks) == 0 && len(d.SignedAttestations) == 0 {
+				err = hd.ErrAccountNotFound
+			}
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to export slashing protection of %x", pubKey)
+		}
+		interchange.Data = append(interchange.Data, d)
+	}
+	return interchange, nil
+}
+
+// interchangeData returns the minimal format interchange data of the given public key, the caller must hold the public key lock.
+func interchangeData(storage *store.HashicorpVaultStore, pubKey []byte) (*InterchangeData, error) {
+	d := &InterchangeData{
+		SignedBlocks:       make([]*InterchangeBlock, 0, 1),
+		SignedAttestations: make([]*InterchangeAttestation, 0, 1),
+	}
+	copy(d.PublicKey[:], pubKey)
+
+	att, found, err := storage.RetrieveHighestAttestation(pubKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve highest attestation")
+	}
+	if found && att != nil {
+		d.SignedAttestations = append(d.SignedAttestations, &InterchangeAttestation{
+			SourceEpoch: att.Source.Epoch,
+			TargetEpoch: att.Target.Epoch,
+		})
+	}
+
+	slot, found, err := storage.RetrieveHighestProposal(pubKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve highest proposal")
+	}
+	if found && slot > 0 {
+		d.SignedBlocks = append(d.SignedBlocks, &InterchangeBlock{
+			Slot: slot,
+		})
+	}
+	return d, nil
+}
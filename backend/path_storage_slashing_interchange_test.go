@@ -0,0 +1,239 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/bloxapp/eth2-key-manager/core"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bloxapp/key-vault/backend/store"
+)
+
+const (
+	interchangePubKey        = "0x95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcf"
+	interchangeUnknownPubKey = "0x95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcd"
+)
+
+// interchangeJSON returns a complete format interchange of the given public key.
+func interchangeJSON(gvr string, pubKey string, slots []uint64, epochs [][2]uint64) string {
+	blocks := ""
+	for i, slot := range slots {
+		if i > 0 {
+			blocks += ","
+		}
+		blocks += fmt.Sprintf(`{"slot":"%d","signing_root":"0x4ff6f743a43f3b4f95350831aeaf0a122a1a392922c45d804280284a69eb850b"}`, slot)
+	}
+	atts := ""
+	for i, epoch := range epochs {
+		if i > 0 {
+			atts += ","
+		}
+		atts += fmt.Sprintf(`{"source_epoch":"%d","target_epoch":"%d"}`, epoch[0], epoch[1])
+	}
+	return fmt.Sprintf(`{
+		"metadata":{"interchange_format_version":"5","genesis_validators_root":"%s"},
+		"data":[{"pubkey":"%s","signed_blocks":[%s],"signed_attestations":[%s]}]
+	}`, gvr, pubKey, blocks, atts)
+}
+
+func TestSlashingStorageImport(t *testing.T) {
+	b, _ := getBackend(t)
+	praterGVR := fmt.Sprintf("%#x", core.PraterNetwork.GenesisValidatorsRoot())
+
+	setup := func(t *testing.T) (*logical.Request, *store.HashicorpVaultStore) {
+		req := logical.TestRequest(t, logical.CreateOperation, "storage/slashing/import")
+		setupBaseStorage(t, req)
+		storage, err := baseHashicorpStorage(context.Background(), req.Storage)
+		require.NoError(t, err)
+		return req, storage
+	}
+	pubKey := _byteArray(interchangePubKey[2:])
+
+	t.Run("Raise watermarks", func(t *testing.T) {
+		req, storage := setup(t)
+		req.Data = map[string]interface{}{
+			"interchange": interchangeJSON(praterGVR, interchangePubKey, []uint64{81952, 81951}, [][2]uint64{{2290, 3007}, {2291, 3006}}),
+		}
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, []string{interchangePubKey[2:]}, res.Data["imported"])
+		require.Empty(t, res.Data["skipped"])
+
+		att, found, err := storage.RetrieveHighestAttestation(pubKey)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.EqualValues(t, 2291, att.Source.Epoch)
+		require.EqualValues(t, 3007, att.Target.Epoch)
+
+		slot, found, err := storage.RetrieveHighestProposal(pubKey)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.EqualValues(t, 81952, slot)
+	})
+
+	t.Run("Never lower watermarks", func(t *testing.T) {
+		req, storage := setup(t)
+		require.NoError(t, storage.SaveHighestAttestation(pubKey, &phase0.AttestationData{
+			Source: &phase0.Checkpoint{Epoch: 100},
+			Target: &phase0.Checkpoint{Epoch: 101},
+		}))
+		require.NoError(t, storage.SaveHighestProposal(pubKey, 5000))
+
+		req.Data = map[string]interface{}{
+			"interchange": interchangeJSON(praterGVR, interchangePubKey, []uint64{10}, [][2]uint64{{50, 200}}),
+		}
+		_, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+
+		att, _, err := storage.RetrieveHighestAttestation(pubKey)
+		require.NoError(t, err)
+		require.EqualValues(t, 100, att.Source.Epoch)
+		require.EqualValues(t, 200, att.Target.Epoch)
+
+		slot, _, err := storage.RetrieveHighestProposal(pubKey)
+		require.NoError(t, err)
+		require.EqualValues(t, 5000, slot)
+	})
+
+	t.Run("Skip unknown accounts", func(t *testing.T) {
+		req, _ := setup(t)
+		req.Data = map[string]interface{}{
+			"interchange": interchangeJSON(praterGVR, interchangeUnknownPubKey, []uint64{10}, nil),
+		}
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Empty(t, res.Data["imported"])
+		require.Equal(t, []string{interchangeUnknownPubKey[2:]}, res.Data["skipped"])
+	})
+
+	t.Run("Reject genesis validators root of another network", func(t *testing.T) {
+		req, _ := setup(t)
+		req.Data = map[string]interface{}{
+			"interchange": interchangeJSON(fmt.Sprintf("%#x", core.MainNetwork.GenesisValidatorsRoot()), interchangePubKey, []uint64{10}, nil),
+		}
+		_, err := b.HandleRequest(context.Background(), req)
+		require.ErrorIs(t, err, ErrInterchangeGenesisValidatorsRoot)
+	})
+
+	t.Run("Validate genesis validators root against the config", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "storage/slashing/import")
+		setupBaseStorage(t, req, withPraterForkSchedule(t))
+		_, err := baseHashicorpStorage(context.Background(), req.Storage)
+		require.NoError(t, err)
+
+		config, err := b.(*backend).readConfig(context.Background(), req.Storage)
+		require.NoError(t, err)
+		req.Data = map[string]interface{}{
+			"interchange": interchangeJSON(fmt.Sprintf("%#x", config.GenesisValidatorsRoot), interchangePubKey, []uint64{10}, nil),
+		}
+		_, err = b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+	})
+
+	t.Run("Reject invalid interchange", func(t *testing.T) {
+		req, _ := setup(t)
+
+		req.Data = map[string]interface{}{
+			"interchange": `{"metadata":{"interchange_format_version":"4","genesis_validators_root":"` + praterGVR + `"},"data":[]}`,
+		}
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, `unsupported interchange format version "4"`)
+
+		req.Data = map[string]interface{}{
+			"interchange": interchangeJSON(praterGVR, interchangePubKey, nil, [][2]uint64{{10, 9}}),
+		}
+		_, err = b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "invalid interchange: attestation of "+interchangePubKey+" has source epoch 10 after target epoch 9")
+
+		req.Data = map[string]interface{}{
+			"interchange": `{"metadata":`,
+		}
+		_, err = b.HandleRequest(context.Background(), req)
+		require.ErrorContains(t, err, "failed to decode interchange")
+	})
+}
+
+func TestSlashingStorageExport(t *testing.T) {
+	b, _ := getBackend(t)
+	pubKey := _byteArray(interchangePubKey[2:])
+
+	req := logical.TestRequest(t, logical.ReadOperation, "storage/slashing/export")
+	setupBaseStorage(t, req)
+	storage, err := baseHashicorpStorage(context.Background(), req.Storage)
+	require.NoError(t, err)
+	require.NoError(t, storage.SaveHighestAttestation(pubKey, &phase0.AttestationData{
+		Source: &phase0.Checkpoint{Epoch: 2290},
+		Target: &phase0.Checkpoint{Epoch: 3007},
+	}))
+	require.NoError(t, storage.SaveHighestProposal(pubKey, 81952))
+
+	expected := `{"metadata":{"interchange_format_version":"5","genesis_validators_root":"0x043db0d9a83813551ee2f33450d23797757d430911a9320530ad8a0eabc43efb"},` +
+		`"data":[{"pubkey":"` + interchangePubKey + `","signed_blocks":[{"slot":"81952"}],"signed_attestations":[{"source_epoch":"2290","target_epoch":"3007"}]}]}`
+
+	t.Run("Export all accounts", func(t *testing.T) {
+		req.Data = nil
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.JSONEq(t, expected, res.Data["interchange"].(string))
+
+		// The export can be imported back
+		_, err = parseInterchange([]byte(res.Data["interchange"].(string)), core.PraterNetwork.GenesisValidatorsRoot())
+		require.NoError(t, err)
+	})
+
+	t.Run("Export requested accounts", func(t *testing.T) {
+		req.Data = map[string]interface{}{
+			"public_keys": interchangePubKey[2:],
+		}
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.JSONEq(t, expected, res.Data["interchange"].(string))
+	})
+
+	t.Run("Export unknown account", func(t *testing.T) {
+		req.Data = map[string]interface{}{
+			"public_keys": interchangeUnknownPubKey,
+		}
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "failed to export slashing protection of "+interchangeUnknownPubKey[2:]+": account not found")
+	})
+
+	t.Run("Export deleted account", func(t *testing.T) {
+		deleteReq := logical.TestRequest(t, logical.CreateOperation, "accounts/delete")
+		setupBaseStorage(t, deleteReq)
+		deleted, err := baseHashicorpStorage(context.Background(), deleteReq.Storage)
+		require.NoError(t, err)
+		require.NoError(t, deleted.SaveHighestAttestation(pubKey, &phase0.AttestationData{
+			Source: &phase0.Checkpoint{Epoch: 2290},
+			Target: &phase0.Checkpoint{Epoch: 3007},
+		}))
+		require.NoError(t, deleted.SaveHighestProposal(pubKey, 81952))
+		deleteReq.EntityID = "admin"
+		deleteReq.Data = map[string]interface{}{
+			"public_keys": interchangePubKey,
+		}
+		_, err = b.HandleRequest(context.Background(), deleteReq)
+		require.NoError(t, err)
+
+		exportReq := logical.TestRequest(t, logical.ReadOperation, "storage/slashing/export")
+		exportReq.Storage = deleteReq.Storage
+		exportReq.Data = map[string]interface{}{
+			"public_keys": interchangePubKey,
+		}
+		res, err := b.HandleRequest(context.Background(), exportReq)
+		require.NoError(t, err)
+		require.JSONEq(t, expected, res.Data["interchange"].(string))
+	})
+
+	t.Run("Export invalid public key", func(t *testing.T) {
+		req.Data = map[string]interface{}{
+			"public_keys": "0x1234",
+		}
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, `invalid public key "0x1234" provided`)
+	})
+}
@@ -0,0 +1,294 @@
+package backend
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/uuid"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/bloxapp/key-vault/backend/store"
+)
+
+// Endpoints patterns
+const (
+	// SlashingAuditPattern is the path pattern for list slashing watermark changes endpoint
+	SlashingAuditPattern = "storage/slashing/audit/"
+)
+
+// slashingAuditPrefix is the storage prefix of slashing watermark changes
+const slashingAuditPrefix = "slashing_audit/"
+
+// ErrSlashingWatermarkLowered is returned when a slashing watermark would be lowered without force.
+var ErrSlashingWatermarkLowered = errors.New("refusing to lower slashing watermark, force with a reason is required")
+
+// SlashingWatermark is the slashing protection watermark of an account.
+// Attestations must have a source epoch of at least SourceEpoch and a target epoch above TargetEpoch,
+// proposals must have a slot above ProposalSlot.
+type SlashingWatermark struct {
+	SourceEpoch  phase0.Epoch `json:"source_epoch"`
+	TargetEpoch  phase0.Epoch `json:"target_epoch"`
+	ProposalSlot phase0.Slot  `json:"proposal_slot"`
+}
+
+// lowers returns whether the watermark is lower than the given one in any way.
+func (w SlashingWatermark) lowers(other SlashingWatermark) bool {
+	return w.SourceEpoch < other.SourceEpoch || w.TargetEpoch < other.TargetEpoch || w.ProposalSlot < other.ProposalSlot
+}
+
+// SlashingAuditRecord records a change of a slashing watermark.
+type SlashingAuditRecord struct {
+	ID        string            `json:"id"`
+	PublicKey string            `json:"public_key"`
+	ChangedBy string            `json:"changed_by"`
+	ChangedAt time.Time         `json:"changed_at"`
+	Forced    bool              `json:"forced"`
+	Reason    string            `json:"reason,omitempty"`
+	Before    SlashingWatermark `json:"before"`
+	After     SlashingWatermark `json:"after"`
+}
+
+// Map returns a map representation of the record.
+func (r *SlashingAuditRecord) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"id":         r.ID,
+		"public_key": r.PublicKey,
+		"changed_by": r.ChangedBy,
+		"changed_at": r.ChangedAt,
+		"forced":     r.Forced,
+		"reason":     r.Reason,
+		"before":     r.Before,
+		"after":      r.After,
+	}
+}
+
+func storageSlashingWatermarkPaths(b *backend) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         SlashingStoragePattern + "/" + pubKeyRegex("public_key"),
+			HelpSynopsis:    "Set slashing watermark",
+			HelpDescription: `Set the minimum source and target epochs of attestations and the minimum slot of proposals of an account. Lowering them requires force with a reason, every change is recorded`,
+			Fields: map[string]*framework.FieldSchema{
+				"public_key": {
+					Type:        framework.TypeString,
+					Description: "Hex encoded public key of the account",
+				},
+				"source_epoch": {
+					Type:        framework.TypeInt,
+					Description: "Attestations must have a source epoch of at least this epoch",
+				},
+				"target_epoch": {
+					Type:        framework.TypeInt,
+					Description: "Attestations must have a target epoch above this epoch",
+				},
+				"proposal_slot": {
+					Type:        framework.TypeInt,
+					Description: "Proposals must have a slot above this slot",
+				},
+				"force": {
+					Type:        framework.TypeBool,
+					Description: "Allow lowering the watermark",
+				},
+				"reason": {
+					Type:        framework.TypeString,
+					Description: "Reason of the change, required with force",
+				},
+			},
+			ExistenceCheck: b.pathExistenceCheck,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.CreateOperation: &framework.PathOperation{
+					Callback: b.pathSlashingWatermarkWrite,
+				},
+			},
+		},
+		{
+			Pattern:         SlashingAuditPattern,
+			HelpSynopsis:    "List slashing watermark changes",
+			HelpDescription: `List the recorded slashing watermark changes`,
+			Fields:          map[string]*framework.FieldSchema{},
+			ExistenceCheck:  b.pathExistenceCheck,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.pathSlashingAuditList,
+				},
+			},
+		},
+	}
+}
+
+func (b *backend) pathSlashingWatermarkWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	identity, err := requesterIdentity(req)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey, err := hexutil.Decode(ensureHexPrefix(data.Get("public_key").(string)))
+	if err != nil || len(pubKey) != BLSPubkeyLength {
+		return nil, errors.New("invalid public key provided")
+	}
+	force := data.Get("force").(bool)
+	reason := strings.TrimSpace(data.Get("reason").(string))
+	if force && reason == "" {
+		return nil, errors.New("reason is required with force")
+	}
+
+	values := make(map[string]uint64)
+	for _, field := range []string{"source_epoch", "target_epoch", "proposal_slot"} {
+		if v, ok := data.GetOk(field); ok {
+			if v.(int) < 0 {
+				return nil, errors.Errorf("invalid %s provided", field)
+			}
+			values[field] = uint64(v.(int))
+		}
+	}
+	if len(values) == 0 {
+		return nil, errors.New("one of source_epoch, target_epoch or proposal_slot is required")
+	}
+	if slot, ok := values["proposal_slot"]; ok && slot == 0 {
+		return nil, errors.New("invalid proposal_slot provided")
+	}
+
+	// Load config
+	config, err := b.readConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get config")
+	}
+
+	storage, wallet, err := b.openWallet(ctx, req.Storage, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var record *SlashingAuditRecord
+	err = b.lock(wallet, pubKey, func() error {
+		before, err := slashingWatermark(storage, pubKey)
+		if err != nil {
+			return err
+		}
+
+		after := before
+		if v, ok := values["source_epoch"]; ok {
+			after.SourceEpoch = phase0.Epoch(v)
+		}
+		if v, ok := values["target_epoch"]; ok {
+			after.TargetEpoch = phase0.Epoch(v)
+		}
+		if v, ok := values["proposal_slot"]; ok {
+			after.ProposalSlot = phase0.Slot(v)
+		}
+		if after.lowers(before) && !force {
+			return ErrSlashingWatermarkLowered
+		}
+
+		if after.SourceEpoch != before.SourceEpoch || after.TargetEpoch != before.TargetEpoch {
+			if err := storage.SetAttestationWatermark(pubKey, after.SourceEpoch, after.TargetEpoch); err != nil {
+				return err
+			}
+		}
+		if after.ProposalSlot != before.ProposalSlot {
+			if err := storage.SaveHighestProposal(pubKey, after.ProposalSlot); err != nil {
+				return errors.Wrap(err, "failed to save highest proposal")
+			}
+		}
+
+		record = &SlashingAuditRecord{
+			ID:        uuid.New().String(),
+			PublicKey: hexutil.Encode(pubKey),
+			ChangedBy: identity,
+			ChangedAt: time.Now(),
+			Forced:    force,
+			Reason:    reason,
+			Before:    before,
+			After:     after,
+		}
+		return putSlashingAuditRecord(ctx, req.Storage, record)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b.logger.WithFields(logrus.Fields{
+		"public_key": record.PublicKey,
+		"changed_by": record.ChangedBy,
+		"forced":     record.Forced,
+		"reason":     record.Reason,
+	}).Info("Slashing watermark changed")
+	return &logical.Response{
+		Data: record.Map(),
+	}, nil
+}
+
+func (b *backend) pathSlashingAuditList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	ids, err := req.Storage.List(ctx, slashingAuditPrefix)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list slashing watermark changes")
+	}
+
+	records := make([]*SlashingAuditRecord, 0, len(ids))
+	for _, id := range ids {
+		entry, err := req.Storage.Get(ctx, slashingAuditPrefix+id)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get slashing watermark change")
+		}
+		if entry == nil {
+			continue
+		}
+		var record SlashingAuditRecord
+		if err := entry.DecodeJSON(&record); err != nil {
+			return nil, errors.Wrap(err, "failed to decode slashing watermark change")
+		}
+		records = append(records, &record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].ChangedAt.Before(records[j].ChangedAt)
+	})
+
+	keys := make([]string, 0, len(records))
+	keyInfo := make(map[string]interface{}, len(records))
+	for _, r := range records {
+		keys = append(keys, r.ID)
+		keyInfo[r.ID] = r.Map()
+	}
+	return logical.ListResponseWithInfo(keys, keyInfo), nil
+}
+
+// slashingWatermark returns the slashing watermark of the given public key, missing records are zero.
+func slashingWatermark(storage *store.HashicorpVaultStore, pubKey []byte) (SlashingWatermark, error) {
+	var watermark SlashingWatermark
+
+	att, found, err := storage.RetrieveHighestAttestation(pubKey)
+	if err != nil {
+		return watermark, errors.Wrap(err, "failed to retrieve highest attestation")
+	}
+	if found && att != nil {
+		watermark.SourceEpoch = att.Source.Epoch
+		watermark.TargetEpoch = att.Target.Epoch
+	}
+
+	slot, found, err := storage.RetrieveHighestProposal(pubKey)
+	if err != nil {
+		return watermark, errors.Wrap(err, "failed to retrieve highest proposal")
+	}
+	if found {
+		watermark.ProposalSlot = slot
+	}
+	return watermark, nil
+}
+
+func putSlashingAuditRecord(ctx context.Context, s logical.Storage, record *SlashingAuditRecord) error {
+	entry, err := logical.StorageEntryJSON(slashingAuditPrefix+record.ID, record)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode slashing watermark change")
+	}
+	if err := s.Put(ctx, entry); err != nil {
+		return errors.Wrap(err, "failed to store slashing watermark change")
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bloxapp/eth2-key-manager/core"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bloxapp/key-vault/backend/store"
+)
+
+func TestSlashingWatermark(t *testing.T) {
+	b, _ := getBackend(t)
+	pubKeyHex := "0x95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcf"
+	pubKey := _byteArray(pubKeyHex[2:])
+
+	setup := func(t *testing.T) (*logical.Request, *store.HashicorpVaultStore) {
+		req := logical.TestRequest(t, logical.CreateOperation, "storage/slashing/"+pubKeyHex)
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+		req.EntityID = "admin"
+		return req, store.NewHashicorpVaultStore(context.Background(), req.Storage, core.PraterNetwork)
+	}
+	requireWatermark := func(t *testing.T, storage *store.HashicorpVaultStore, expected SlashingWatermark) {
+		watermark, err := slashingWatermark(storage, pubKey)
+		require.NoError(t, err)
+		require.Equal(t, expected, watermark)
+	}
+
+	t.Run("Raise watermark", func(t *testing.T) {
+		req, storage := setup(t)
+		req.Data = map[string]interface{}{
+			"source_epoch":  10,
+			"target_epoch":  11,
+			"proposal_slot": 400,
+		}
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, "entity:admin", res.Data["changed_by"])
+		require.Equal(t, SlashingWatermark{SourceEpoch: 0, TargetEpoch: 0, ProposalSlot: 1}, res.Data["before"])
+		require.Equal(t, SlashingWatermark{SourceEpoch: 10, TargetEpoch: 11, ProposalSlot: 400}, res.Data["after"])
+		requireWatermark(t, storage, SlashingWatermark{SourceEpoch: 10, TargetEpoch: 11, ProposalSlot: 400})
+
+		// Fields which are not given are kept
+		req.Data = map[string]interface{}{
+			"target_epoch": 20,
+		}
+		_, err = b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		requireWatermark(t, storage, SlashingWatermark{SourceEpoch: 10, TargetEpoch: 20, ProposalSlot: 400})
+	})
+
+	t.Run("Refuse lowering watermark without force", func(t *testing.T) {
+		req, storage := setup(t)
+		require.NoError(t, storage.SaveHighestProposal(pubKey, 400))
+
+		req.Data = map[string]interface{}{
+			"proposal_slot": 300,
+		}
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, ErrSlashingWatermarkLowered.Error())
+
+		req.Data = map[string]interface{}{
+			"proposal_slot": 300,
+			"force":         true,
+		}
+		_, err = b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "reason is required with force")
+		requireWatermark(t, storage, SlashingWatermark{ProposalSlot: 400})
+	})
+
+	t.Run("Force lowering watermark", func(t *testing.T) {
+		req, storage := setup(t)
+		require.NoError(t, storage.SaveHighestProposal(pubKey, 400))
+
+		req.Data = map[string]interface{}{
+			"proposal_slot": 300,
+			"force":         true,
+			"reason":        "restore after wrong import",
+		}
+		_, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		requireWatermark(t, storage, SlashingWatermark{ProposalSlot: 300})
+
+		// The change is recorded
+		listReq := logical.TestRequest(t, logical.ListOperation, "storage/slashing/audit/")
+		listReq.Storage = req.Storage
+		res, err := b.HandleRequest(context.Background(), listReq)
+		require.NoError(t, err)
+		require.Len(t, res.Data["keys"], 1)
+		record := res.Data["key_info"].(map[string]interface{})[res.Data["keys"].([]string)[0]].(map[string]interface{})
+		require.Equal(t, pubKeyHex, record["public_key"])
+		require.Equal(t, "entity:admin", record["changed_by"])
+		require.Equal(t, true, record["forced"])
+		require.Equal(t, "restore after wrong import", record["reason"])
+		require.Equal(t, SlashingWatermark{ProposalSlot: 400}, record["before"])
+		require.Equal(t, SlashingWatermark{ProposalSlot: 300}, record["after"])
+	})
+
+	t.Run("Set watermark by token without entity", func(t *testing.T) {
+		req, storage := setup(t)
+		req.EntityID = ""
+		req.DisplayName = "root"
+		req.Data = map[string]interface{}{
+			"proposal_slot": 400,
+		}
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, "token:root", res.Data["changed_by"])
+		requireWatermark(t, storage, SlashingWatermark{ProposalSlot: 400})
+	})
+
+	t.Run("Set watermark of unknown account", func(t *testing.T) {
+		req, _ := setup(t)
+		req.Path = "storage/slashing/0x95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcd"
+		req.Data = map[string]interface{}{
+			"proposal_slot": 300,
+		}
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "account not found")
+	})
+
+	t.Run("Set watermark without values", func(t *testing.T) {
+		req, _ := setup(t)
+		_, err := b.HandleRequest(context.Background(), req)
+		require.EqualError(t, err, "one of source_epoch, target_epoch or proposal_slot is required")
+	})
+}
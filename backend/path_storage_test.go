@@ -4,12 +4,14 @@ import (
 	"context"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"testing"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/bloxapp/eth2-key-manager/core"
 	"github.com/bloxapp/eth2-key-manager/stores/inmemory"
 	"github.com/bloxapp/eth2-key-manager/wallets/hd"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/google/uuid"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/stretchr/testify/require"
@@ -132,3 +134,140 @@ func TestStorage(t *testing.T) {
 		require.EqualValues(t, att.Target.Epoch, 0)
 	})
 }
+
+func TestStorageUpdateReport(t *testing.T) {
+	b, _ := getBackend(t)
+
+	// A storage with another account at the index of the base one
+	conflictingStore := inmemory.NewInMemStore(core.PraterNetwork)
+	wallet := hd.NewWallet(&core.WalletContext{Storage: conflictingStore})
+	require.NoError(t, conflictingStore.SaveWallet(wallet))
+	conflictingAcc, err := wallet.CreateValidatorAccount(_byteArray("0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1fdf"), nil)
+	require.NoError(t, err)
+	byts, err := json.Marshal(conflictingStore)
+	require.NoError(t, err)
+	conflictingData := hex.EncodeToString(byts)
+
+	storageRequest := func(t *testing.T, s logical.Storage, data map[string]interface{}) (*logical.Response, error) {
+		req := logical.TestRequest(t, logical.CreateOperation, "storage")
+		req.Storage = s
+		req.Data = data
+		return b.HandleRequest(context.Background(), req)
+	}
+	listAccounts := func(t *testing.T, s logical.Storage) []map[string]string {
+		req := logical.TestRequest(t, logical.ListOperation, "accounts/")
+		req.Storage = s
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		accounts, _ := res.Data["accounts"].([]map[string]string)
+		return accounts
+	}
+
+	t.Run("report added and skipped accounts", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "storage")
+		setupBaseStorage(t, req)
+		inMemStore, _, err := baseInmemStorage()
+		require.NoError(t, err)
+		byts, err := json.Marshal(inMemStore)
+		require.NoError(t, err)
+
+		for _, result := range []string{store.AccountAdded, store.AccountSkipped} {
+			res, err := storageRequest(t, req.Storage, map[string]interface{}{
+				"data": hex.EncodeToString(byts),
+			})
+			require.NoError(t, err)
+			require.Equal(t, []map[string]interface{}{{
+				"public_key": "0x" + basePubKey,
+				"name":       "account-0",
+				"index":      0,
+				"result":     result,
+			}}, res.Data["accounts"])
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "storage")
+		setupBaseStorage(t, req)
+		importBaseStorage(t, b, req.Storage)
+
+		res, err := storageRequest(t, req.Storage, map[string]interface{}{
+			"data":    conflictingData,
+			"dry_run": true,
+		})
+		require.NoError(t, err)
+		require.True(t, res.Data["dry_run"].(bool))
+		require.Equal(t, []map[string]interface{}{{
+			"public_key": hexutil.Encode(conflictingAcc.ValidatorPublicKey()),
+			"name":       "account-0",
+			"index":      0,
+			"result":     store.AccountConflict,
+			"conflict":   "name is already stored with public key 0x" + basePubKey,
+		}}, res.Data["accounts"])
+
+		// Nothing is written
+		require.Len(t, listAccounts(t, req.Storage), 1)
+	})
+
+	t.Run("refuse conflicting accounts", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "storage")
+		setupBaseStorage(t, req)
+		importBaseStorage(t, b, req.Storage)
+
+		_, err := storageRequest(t, req.Storage, map[string]interface{}{
+			"data": conflictingData,
+		})
+		require.ErrorIs(t, err, store.ErrConflictingAccounts)
+		require.EqualError(t, err, "failed to update storage from in memory: refused to update storage ("+
+			hexutil.Encode(conflictingAcc.ValidatorPublicKey())+": name is already stored with public key 0x"+basePubKey+"): conflicting accounts")
+		require.Len(t, listAccounts(t, req.Storage), 1)
+	})
+
+	t.Run("override conflicting accounts", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "storage")
+		setupBaseStorage(t, req)
+		importBaseStorage(t, b, req.Storage)
+
+		res, err := storageRequest(t, req.Storage, map[string]interface{}{
+			"data":     conflictingData,
+			"override": true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, store.AccountOverridden, res.Data["accounts"].([]map[string]interface{})[0]["result"])
+
+		// The stored account of the same name and index is replaced
+		accounts := listAccounts(t, req.Storage)
+		require.Len(t, accounts, 1)
+		require.Equal(t, hex.EncodeToString(conflictingAcc.ValidatorPublicKey()), accounts[0]["validationPubKey"])
+	})
+}
+
+func TestStorageUpdateRecovery(t *testing.T) {
+	b, _ := getBackend(t)
+	req := logical.TestRequest(t, logical.ListOperation, "accounts/")
+	setupBaseStorage(t, req)
+	importBaseStorage(t, b, req.Storage)
+
+	// An update interrupted after writing an account
+	walEntry, err := logical.StorageEntryJSON(fmt.Sprintf(store.WALEntryPath, 0), &store.WALEntry{
+		Key: fmt.Sprintf(store.AccountPath, "interrupted"),
+	})
+	require.NoError(t, err)
+	require.NoError(t, req.Storage.Put(context.Background(), walEntry))
+	require.NoError(t, req.Storage.Put(context.Background(), &logical.StorageEntry{
+		Key:   fmt.Sprintf(store.AccountPath, "interrupted"),
+		Value: []byte("{}"),
+	}))
+
+	require.NoError(t, b.Initialize(context.Background(), &logical.InitializationRequest{Storage: req.Storage}))
+
+	entry, err := req.Storage.Get(context.Background(), fmt.Sprintf(store.AccountPath, "interrupted"))
+	require.NoError(t, err)
+	require.Nil(t, entry)
+	keys, err := req.Storage.List(context.Background(), store.WALBase)
+	require.NoError(t, err)
+	require.Empty(t, keys)
+
+	res, err := b.HandleRequest(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, res.Data["accounts"], 1)
+}
@@ -0,0 +1,517 @@
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/attestantio/go-eth2-client/api"
+	eth2apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+
+	"github.com/bloxapp/key-vault/keymanager/models"
+)
+
+// Endpoints patterns, the mount is the base URL of the Web3Signer API
+const (
+	// Web3SignerSignPattern is the path pattern for the Web3Signer compatible sign endpoint
+	Web3SignerSignPattern = "api/v1/eth2/sign/"
+
+	// Web3SignerPublicKeysPattern is the path pattern for the Web3Signer compatible public keys endpoint
+	Web3SignerPublicKeysPattern = "api/v1/eth2/publicKeys"
+
+	// Web3SignerUpcheckPattern is the path pattern for the Web3Signer compatible upcheck endpoint
+	Web3SignerUpcheckPattern = "upcheck"
+)
+
+// Web3Signer sign request types
+const (
+	Web3SignerTypeAggregationSlot                   = "AGGREGATION_SLOT"
+	Web3SignerTypeAggregateAndProof                 = "AGGREGATE_AND_PROOF"
+	Web3SignerTypeAggregateAndProofV2               = "AGGREGATE_AND_PROOF_V2"
+	Web3SignerTypeAttestation                       = "ATTESTATION"
+	Web3SignerTypeBlock                             = "BLOCK"
+	Web3SignerTypeBlockV2                           = "BLOCK_V2"
+	Web3SignerTypeRandaoReveal                      = "RANDAO_REVEAL"
+	Web3SignerTypeVoluntaryExit                     = "VOLUNTARY_EXIT"
+	Web3SignerTypeSyncCommitteeMessage              = "SYNC_COMMITTEE_MESSAGE"
+	Web3SignerTypeSyncCommitteeSelectionProof       = "SYNC_COMMITTEE_SELECTION_PROOF"
+	Web3SignerTypeSyncCommitteeContributionAndProof = "SYNC_COMMITTEE_CONTRIBUTION_AND_PROOF"
+	Web3SignerTypeValidatorRegistration             = "VALIDATOR_REGISTRATION"
+)
+
+// web3SignerForkInfo is the fork_info object of a Web3Signer sign request.
+type web3SignerForkInfo struct {
+	Fork                  *phase0.Fork `json:"fork"`
+	GenesisValidatorsRoot phase0.Root  `json:"genesis_validators_root"`
+}
+
+// web3SignerBeaconBlock is the beacon_block object of a Web3Signer BLOCK_V2 sign request.
+type web3SignerBeaconBlock struct {
+	Version     spec.DataVersion          `json:"version"`
+	Block       json.RawMessage           `json:"block"`
+	BlockHeader *phase0.BeaconBlockHeader `json:"block_header"`
+}
+
+// web3SignerAggregateAndProof is the aggregate_and_proof object of a Web3Signer AGGREGATE_AND_PROOF_V2 sign request.
+type web3SignerAggregateAndProof struct {
+	Version spec.DataVersion `json:"version"`
+	Data    json.RawMessage  `json:"data"`
+}
+
+// web3SignerSlot is the aggregation_slot object of a Web3Signer sign request.
+type web3SignerSlot struct {
+	Slot phase0.Slot `json:"slot,string"`
+}
+
+// web3SignerEpoch is the randao_reveal object of a Web3Signer sign request.
+type web3SignerEpoch struct {
+	Epoch phase0.Epoch `json:"epoch,string"`
+}
+
+// web3SignerSyncCommitteeMessage is the sync_committee_message object of a Web3Signer sign request.
+type web3SignerSyncCommitteeMessage struct {
+	BeaconBlockRoot phase0.Root `json:"beacon_block_root"`
+	Slot            phase0.Slot `json:"slot,string"`
+}
+
+func web3SignerPaths(b *backend) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern:         Web3SignerSignPattern + pubKeyRegex("public_key"),
+			HelpSynopsis:    "Sign using the Web3Signer ETH2 API",
+			HelpDescription: `Sign a Web3Signer ETH2 API request with the given public key`,
+			Fields: map[string]*framework.FieldSchema{
+				"public_key": {
+					Type:        framework.TypeString,
+					Description: "Hex encoded public key of the account to sign with",
+				},
+				"type": {
+					Type:        framework.TypeString,
+					Description: "Web3Signer sign request type",
+				},
+				"fork_info": {
+					Type:        framework.TypeMap,
+					Description: "Fork and genesis validators root used to compute the signature domain",
+				},
+				"signingRoot": {
+					Type:        framework.TypeString,
+					Description: "Hex encoded signing root",
+				},
+				"aggregation_slot": {
+					Type:        framework.TypeMap,
+					Description: "AGGREGATION_SLOT object",
+				},
+				"aggregate_and_proof": {
+					Type:        framework.TypeMap,
+					Description: "AGGREGATE_AND_PROOF and AGGREGATE_AND_PROOF_V2 object",
+				},
+				"attestation": {
+					Type:        framework.TypeMap,
+					Description: "ATTESTATION object",
+				},
+				"block": {
+					Type:        framework.TypeMap,
+					Description: "BLOCK object",
+				},
+				"beacon_block": {
+					Type:        framework.TypeMap,
+					Description: "BLOCK_V2 object",
+				},
+				"randao_reveal": {
+					Type:        framework.TypeMap,
+					Description: "RANDAO_REVEAL object",
+				},
+				"voluntary_exit": {
+					Type:        framework.TypeMap,
+					Description: "VOLUNTARY_EXIT object",
+				},
+				"sync_committee_message": {
+					Type:        framework.TypeMap,
+					Description: "SYNC_COMMITTEE_MESSAGE object",
+				},
+				"sync_aggregator_selection_data": {
+					Type:        framework.TypeMap,
+					Description: "SYNC_COMMITTEE_SELECTION_PROOF object",
+				},
+				"contribution_and_proof": {
+					Type:        framework.TypeMap,
+					Description: "SYNC_COMMITTEE_CONTRIBUTION_AND_PROOF object",
+				},
+				"validator_registration": {
+					Type:        framework.TypeMap,
+					Description: "VALIDATOR_REGISTRATION object",
+				},
+			},
+			ExistenceCheck: b.pathExistenceCheck,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.CreateOperation: &framework.PathOperation{
+					Callback: b.pathWeb3SignerSign,
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.pathWeb3SignerSign,
+				},
+			},
+		},
+		{
+			Pattern:         Web3SignerPublicKeysPattern,
+			HelpSynopsis:    "List public keys using the Web3Signer ETH2 API",
+			HelpDescription: ``,
+			Fields:          map[string]*framework.FieldSchema{},
+			ExistenceCheck:  b.pathExistenceCheck,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathWeb3SignerPublicKeys,
+				},
+			},
+		},
+		{
+			Pattern:         Web3SignerUpcheckPattern,
+			HelpSynopsis:    "Upcheck using the Web3Signer ETH2 API",
+			HelpDescription: ``,
+			Fields:          map[string]*framework.FieldSchema{},
+			ExistenceCheck:  b.pathExistenceCheck,
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.pathWeb3SignerUpcheck,
+				},
+			},
+		},
+	}
+}
+
+// pathWeb3SignerSign signs a Web3Signer ETH2 API sign request
+func (b *backend) pathWeb3SignerSign(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	// Load config
+	config, err := b.readConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get config")
+	}
+
+	signReq, err := buildWeb3SignerSignRequest(config, data)
+	if err != nil {
+		return web3SignerRawResponse(http.StatusBadRequest, "text/plain", []byte(errors.Wrap(err, "failed to parse web3signer sign request").Error())), nil
+	}
+
+	var sig []byte
+	if _, ok := signReq.GetObject().(*models.SignRequestVoluntaryExit); ok {
+		sig, _, err = b.signVoluntaryExit(ctx, req.Storage, config, signReq)
+	} else {
+		sig, _, err = b.sign(ctx, req.Storage, config, signReq)
+	}
+	if err != nil {
+		// Web3Signer status codes tell clients whether to retry
+		err = errors.Wrap(err, "failed to sign")
+		switch signErrorType(err) {
+		case SignErrorTypeAccountNotFound:
+			return web3SignerRawResponse(http.StatusNotFound, "text/plain", []byte(err.Error())), nil
+		case SignErrorTypeSlashable, SignErrorTypeRefused, SignErrorTypeAccountDisabled:
+			return web3SignerRawResponse(http.StatusPreconditionFailed, "text/plain", []byte(err.Error())), nil
+		case SignErrorTypeInvalidRequest:
+			return web3SignerRawResponse(http.StatusBadRequest, "text/plain", []byte(err.Error())), nil
+		default:
+			return nil, err
+		}
+	}
+
+	// The signature is a hex string, or a JSON object when the client accepts JSON (or doesn't tell)
+	if accept := strings.Join(req.Headers["Accept"], ","); strings.Contains(accept, "text/plain") && !strings.Contains(accept, "application/json") {
+		return web3SignerRawResponse(http.StatusOK, "text/plain", []byte(hexutil.Encode(sig))), nil
+	}
+	body, err := json.Marshal(map[string]string{
+		"signature": hexutil.Encode(sig),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal signature")
+	}
+	return web3SignerRawResponse(http.StatusOK, "application/json", body), nil
+}
+
+// pathWeb3SignerPublicKeys returns the public keys of all the wallet accounts
+func (b *backend) pathWeb3SignerPublicKeys(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	// Load config
+	config, err := b.readConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get config")
+	}
+
+	_, wallet, err := b.openWallet(ctx, req.Storage, config)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKeys := make([]string, 0)
+	for _, a := range wallet.Accounts() {
+		publicKeys = append(publicKeys, hexutil.Encode(a.ValidatorPublicKey()))
+	}
+	sort.Strings(publicKeys)
+
+	body, err := json.Marshal(publicKeys)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal public keys")
+	}
+	return web3SignerRawResponse(http.StatusOK, "application/json", body), nil
+}
+
+// pathWeb3SignerUpcheck returns the status of the plugin
+func (b *backend) pathWeb3SignerUpcheck(_ context.Context, _ *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	return web3SignerRawResponse(http.StatusOK, "text/plain", []byte("OK")), nil
+}
+
+// web3SignerRawResponse returns a response which Vault writes as is, instead of wrapping it in its data envelope.
+func web3SignerRawResponse(status int, contentType string, body []byte) *logical.Response {
+	return &logical.Response{
+		Data: map[string]interface{}{
+			logical.HTTPStatusCode:  status,
+			logical.HTTPContentType: contentType,
+			logical.HTTPRawBody:     body,
+		},
+	}
+}
+
+// buildWeb3SignerSignRequest maps the given Web3Signer request onto a sign request
+func buildWeb3SignerSignRequest(config *Config, data *framework.FieldData) (*models.SignRequest, error) {
+	pubKey, err := hex.DecodeString(strings.TrimPrefix(data.Get("public_key").(string), "0x"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode public key")
+	}
+
+	signReq := &models.SignRequest{
+		PublicKey: pubKey,
+	}
+	if signingRoot := data.Get("signingRoot").(string); signingRoot != "" {
+		if signReq.SigningRoot, err = hexutil.Decode(signingRoot); err != nil {
+			return nil, errors.Wrap(err, "failed to decode signing root")
+		}
+	}
+
+	var (
+		domainType phase0.DomainType
+		epoch      phase0.Epoch
+	)
+	switch reqType := data.Get("type").(string); reqType {
+	case Web3SignerTypeAggregationSlot:
+		var obj web3SignerSlot
+		if err := decodeWeb3SignerField(data, "aggregation_slot", &obj); err != nil {
+			return nil, err
+		}
+		signReq.Object = &models.SignRequestSlot{Slot: obj.Slot}
+		domainType, epoch = DomainSelectionProof, config.epochAtSlot(obj.Slot)
+	case Web3SignerTypeAggregateAndProof:
+		obj := &phase0.AggregateAndProof{}
+		if err := decodeWeb3SignerField(data, "aggregate_and_proof", obj); err != nil {
+			return nil, err
+		}
+		signReq.Object = &models.SignRequestAggregateAttestationAndProof{AggregateAttestationAndProof: obj}
+		domainType, epoch = DomainAggregateAndProof, config.epochAtSlot(obj.Aggregate.Data.Slot)
+	case Web3SignerTypeAggregateAndProofV2:
+		var obj web3SignerAggregateAndProof
+		if err := decodeWeb3SignerField(data, "aggregate_and_proof", &obj); err != nil {
+			return nil, err
+		}
+		agg, err := web3SignerAggregateObject(&obj)
+		if err != nil {
+			return nil, err
+		}
+		attData, err := agg.AttestationData()
+		if err != nil {
+			return nil, errors.Wrap(err, "aggregate_and_proof")
+		}
+		signReq.Object = agg
+		domainType, epoch = DomainAggregateAndProof, config.epochAtSlot(attData.Slot)
+	case Web3SignerTypeAttestation:
+		obj := &phase0.AttestationData{}
+		if err := decodeWeb3SignerField(data, "attestation", obj); err != nil {
+			return nil, err
+		}
+		signReq.Object = &models.SignRequestAttestationData{AttestationData: obj}
+		domainType, epoch = DomainBeaconAttester, obj.Target.Epoch
+	case Web3SignerTypeBlock:
+		obj := &phase0.BeaconBlock{}
+		if err := decodeWeb3SignerField(data, "block", obj); err != nil {
+			return nil, err
+		}
+		signReq.Object = &models.SignRequestBlock{VersionedBeaconBlock: &spec.VersionedBeaconBlock{
+			Version: spec.DataVersionPhase0,
+			Phase0:  obj,
+		}}
+		domainType, epoch = DomainBeaconProposer, config.epochAtSlot(obj.Slot)
+	case Web3SignerTypeBlockV2:
+		var obj web3SignerBeaconBlock
+		if err := decodeWeb3SignerField(data, "beacon_block", &obj); err != nil {
+			return nil, err
+		}
+		var slot phase0.Slot
+		if signReq.Object, slot, err = web3SignerBlockObject(&obj); err != nil {
+			return nil, err
+		}
+		domainType, epoch = DomainBeaconProposer, config.epochAtSlot(slot)
+	case Web3SignerTypeRandaoReveal:
+		var obj web3SignerEpoch
+		if err := decodeWeb3SignerField(data, "randao_reveal", &obj); err != nil {
+			return nil, err
+		}
+		signReq.Object = &models.SignRequestEpoch{Epoch: obj.Epoch}
+		domainType, epoch = DomainRandao, obj.Epoch
+	case Web3SignerTypeVoluntaryExit:
+		obj := &phase0.VoluntaryExit{}
+		if err := decodeWeb3SignerField(data, "voluntary_exit", obj); err != nil {
+			return nil, err
+		}
+		signReq.Object = &models.SignRequestVoluntaryExit{VoluntaryExit: obj}
+		domainType, epoch = DomainVoluntaryExit, obj.Epoch
+	case Web3SignerTypeSyncCommitteeMessage:
+		var obj web3SignerSyncCommitteeMessage
+		if err := decodeWeb3SignerField(data, "sync_committee_message", &obj); err != nil {
+			return nil, err
+		}
+		signReq.Object = &models.SignRequestSyncCommitteeMessage{Root: obj.BeaconBlockRoot[:]}
+		domainType, epoch = DomainSyncCommittee, config.epochAtSlot(obj.Slot)
+	case Web3SignerTypeSyncCommitteeSelectionProof:
+		obj := &altair.SyncAggregatorSelectionData{}
+		if err := decodeWeb3SignerField(data, "sync_aggregator_selection_data", obj); err != nil {
+			return nil, err
+		}
+		signReq.Object = &models.SignRequestSyncAggregatorSelectionData{SyncAggregatorSelectionData: obj}
+		domainType, epoch = DomainSyncCommitteeSelectionProof, config.epochAtSlot(obj.Slot)
+	case Web3SignerTypeSyncCommitteeContributionAndProof:
+		obj := &altair.ContributionAndProof{}
+		if err := decodeWeb3SignerField(data, "contribution_and_proof", obj); err != nil {
+			return nil, err
+		}
+		signReq.Object = &models.SignRequestContributionAndProof{ContributionAndProof: obj}
+		domainType, epoch = DomainContributionAndProof, config.epochAtSlot(obj.Contribution.Slot)
+	case Web3SignerTypeValidatorRegistration:
+		obj := &eth2apiv1.ValidatorRegistration{}
+		if err := decodeWeb3SignerField(data, "validator_registration", obj); err != nil {
+			return nil, err
+		}
+		signReq.Object = &models.SignRequestRegistration{VersionedValidatorRegistration: &api.VersionedValidatorRegistration{
+			Version: spec.BuilderVersionV1,
+			V1:      obj,
+		}}
+
+		// Builder registrations are always signed with the genesis fork version and an empty genesis validators root.
+		signReq.SignatureDomain, err = computeDomain(DomainApplicationBuilder, config.genesisForkVersion(), phase0.Root{})
+		if err != nil {
+			return nil, err
+		}
+		return signReq, nil
+	default:
+		return nil, errors.Errorf("unsupported sign request type %q", reqType)
+	}
+
+	var forkInfo web3SignerForkInfo
+	if err := decodeWeb3SignerField(data, "fork_info", &forkInfo); err != nil {
+		return nil, err
+	}
+	if forkInfo.Fork == nil {
+		return nil, errors.New("fork_info: fork is required")
+	}
+	signReq.SignatureDomain, err = computeDomain(domainType, forkVersionAtEpoch(forkInfo.Fork, epoch), forkInfo.GenesisValidatorsRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	return signReq, nil
+}
+
+// web3SignerBlockObject returns the sign request object and slot of the given BLOCK_V2 object
+func web3SignerBlockObject(obj *web3SignerBeaconBlock) (models.ISignObject, phase0.Slot, error) {
+	if obj.BlockHeader != nil {
+		return &models.SignRequestBlockHeader{BeaconBlockHeader: obj.BlockHeader}, obj.BlockHeader.Slot, nil
+	}
+	if len(obj.Block) == 0 {
+		return nil, 0, errors.New("beacon_block: block or block_header is required")
+	}
+
+	block := &spec.VersionedBeaconBlock{Version: obj.Version}
+	var err error
+	switch obj.Version {
+	case spec.DataVersionPhase0:
+		block.Phase0 = &phase0.BeaconBlock{}
+		err = json.Unmarshal(obj.Block, block.Phase0)
+	case spec.DataVersionAltair:
+		block.Altair = &altair.BeaconBlock{}
+		err = json.Unmarshal(obj.Block, block.Altair)
+	case spec.DataVersionBellatrix:
+		block.Bellatrix = &bellatrix.BeaconBlock{}
+		err = json.Unmarshal(obj.Block, block.Bellatrix)
+	case spec.DataVersionCapella:
+		block.Capella = &capella.BeaconBlock{}
+		err = json.Unmarshal(obj.Block, block.Capella)
+	case spec.DataVersionDeneb:
+		block.Deneb = &deneb.BeaconBlock{}
+		err = json.Unmarshal(obj.Block, block.Deneb)
+	case spec.DataVersionElectra:
+		block.Electra = &electra.BeaconBlock{}
+		err = json.Unmarshal(obj.Block, block.Electra)
+	default:
+		return nil, 0, errors.Errorf("unsupported block version %s", obj.Version)
+	}
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to unmarshal block")
+	}
+
+	slot, err := block.Slot()
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to get block slot")
+	}
+	return &models.SignRequestBlock{VersionedBeaconBlock: block}, slot, nil
+}
+
+// web3SignerAggregateObject returns the sign request object of the given AGGREGATE_AND_PROOF_V2 object
+func web3SignerAggregateObject(obj *web3SignerAggregateAndProof) (*models.SignRequestAggregateAttestationAndProof, error) {
+	if len(obj.Data) == 0 {
+		return nil, errors.New("aggregate_and_proof: data is required")
+	}
+
+	agg := &models.SignRequestAggregateAttestationAndProof{Version: obj.Version}
+	var err error
+	switch {
+	case obj.Version == spec.DataVersionUnknown:
+		return nil, errors.New("aggregate_and_proof: version is required")
+	case obj.Version <= spec.DataVersionDeneb:
+		agg.AggregateAttestationAndProof = &phase0.AggregateAndProof{}
+		err = json.Unmarshal(obj.Data, agg.AggregateAttestationAndProof)
+	case obj.Version == spec.DataVersionElectra:
+		agg.ElectraAggregateAndProof = &electra.AggregateAndProof{}
+		err = json.Unmarshal(obj.Data, agg.ElectraAggregateAndProof)
+	default:
+		return nil, errors.Errorf("unsupported aggregate and proof version %s", obj.Version)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal aggregate and proof")
+	}
+	return agg, nil
+}
+
+// decodeWeb3SignerField decodes the given JSON object field into v
+func decodeWeb3SignerField(data *framework.FieldData, field string, v interface{}) error {
+	raw, ok := data.GetOk(field)
+	if !ok {
+		return errors.Errorf("%s is required", field)
+	}
+
+	byts, err := json.Marshal(raw)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal %s", field)
+	}
+	if err := json.Unmarshal(byts, v); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal %s", field)
+	}
+	return nil
+}
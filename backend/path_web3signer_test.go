@@ -0,0 +1,329 @@
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/api"
+	eth2apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/prysmaticlabs/go-bitfield"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bloxapp/key-vault/keymanager/models"
+	"github.com/bloxapp/key-vault/utils/encoder"
+)
+
+const web3SignerTestPubKey = "0x95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcf"
+
+// web3SignerClientResponse returns the status, content type and body of the given raw response, as Vault writes them
+// to the client: raw responses are not wrapped in the data envelope.
+func web3SignerClientResponse(t *testing.T, res *logical.Response) (int, string, []byte) {
+	require.NotNil(t, res)
+	require.Len(t, res.Data, 3)
+	status, ok := res.Data[logical.HTTPStatusCode].(int)
+	require.True(t, ok)
+	contentType, ok := res.Data[logical.HTTPContentType].(string)
+	require.True(t, ok)
+	body, ok := res.Data[logical.HTTPRawBody].([]byte)
+	require.True(t, ok)
+	return status, contentType, body
+}
+
+// web3SignerSignature decodes the signature of a successful sign response, like a Web3Signer client.
+func web3SignerSignature(t *testing.T, res *logical.Response) string {
+	status, contentType, body := web3SignerClientResponse(t, res)
+	require.Equal(t, http.StatusOK, status, string(body))
+	require.Equal(t, "application/json", contentType)
+	var signResponse struct {
+		Signature string `json:"signature"`
+	}
+	require.NoError(t, json.Unmarshal(body, &signResponse))
+	return signResponse.Signature
+}
+
+func web3SignerForkInfoData() map[string]interface{} {
+	return map[string]interface{}{
+		"fork": map[string]interface{}{
+			"previous_version": "0x00000000",
+			"current_version":  "0x01000000",
+			"epoch":            "10",
+		},
+		"genesis_validators_root": "0x0000000000000000000000000000000000000000000000000000000000000000",
+	}
+}
+
+func TestWeb3SignerSign(t *testing.T) {
+	b, _ := getBackend(t)
+
+	t.Run("Successfully sign randao reveal", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "api/v1/eth2/sign/"+web3SignerTestPubKey)
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data = map[string]interface{}{
+			"type":          Web3SignerTypeRandaoReveal,
+			"fork_info":     web3SignerForkInfoData(),
+			"randao_reveal": map[string]interface{}{"epoch": "12"},
+		}
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+
+		// Sign the same object through the native sign endpoint and compare
+		domain, err := computeDomain(DomainRandao, phase0.Version{0x01, 0x00, 0x00, 0x00}, phase0.Root{})
+		require.NoError(t, err)
+		byts, err := encoder.New().Encode(&models.SignRequest{
+			PublicKey:       _byteArray(web3SignerTestPubKey[2:]),
+			SignatureDomain: domain,
+			Object:          &models.SignRequestEpoch{Epoch: 12},
+		})
+		require.NoError(t, err)
+
+		nativeReq := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		nativeReq.Storage = req.Storage
+		nativeReq.Data = map[string]interface{}{
+			"sign_req": hex.EncodeToString(byts),
+		}
+		nativeRes, err := b.HandleRequest(context.Background(), nativeReq)
+		require.NoError(t, err)
+		require.Equal(t, "0x"+nativeRes.Data["signature"].(string), web3SignerSignature(t, res))
+
+		// Clients accepting only text get the hex encoded signature
+		req.Headers = map[string][]string{"Accept": {"text/plain"}}
+		res, err = b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		status, contentType, body := web3SignerClientResponse(t, res)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, "text/plain", contentType)
+		require.Equal(t, "0x"+nativeRes.Data["signature"].(string), string(body))
+	})
+
+	t.Run("Successfully sign attestation", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "api/v1/eth2/sign/"+web3SignerTestPubKey)
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data = map[string]interface{}{
+			"type":      Web3SignerTypeAttestation,
+			"fork_info": web3SignerForkInfoData(),
+			"attestation": map[string]interface{}{
+				"slot":              "284115",
+				"index":             "2",
+				"beacon_block_root": "0x7b5679277ca45ea74e1deebc9d3e8c0e7d6c570b3cfaf6884be144a81dac9a0e",
+				"source": map[string]interface{}{
+					"epoch": "8877",
+					"root":  "0x7402fdc1ce16d449d637c34a172b349a12b2bae8d6d77e401006594d8057c33d",
+				},
+				"target": map[string]interface{}{
+					"epoch": "8878",
+					"root":  "0x17959acc370274756fa5e9fdd7e7adf17204f49cc8457e49438c42c4883cbfb0",
+				},
+			},
+		}
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.Len(t, web3SignerSignature(t, res), 2+96*2)
+
+		// Signing it again with another root is refused by the slashing protection
+		req.Data["attestation"].(map[string]interface{})["beacon_block_root"] = "0x0000000000000000000000000000000000000000000000000000000000000000"
+		res, err = b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		status, _, body := web3SignerClientResponse(t, res)
+		require.Equal(t, http.StatusPreconditionFailed, status)
+		require.Contains(t, string(body), "slashable attestation")
+	})
+
+	t.Run("Successfully sign electra aggregate and proof", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "api/v1/eth2/sign/"+web3SignerTestPubKey)
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		committeeBits := bitfield.NewBitvector64()
+		committeeBits.SetBitAt(3, true)
+		agg := &electra.AggregateAndProof{
+			AggregatorIndex: 1,
+			Aggregate: &electra.Attestation{
+				AggregationBits: bitfield.NewBitlist(12),
+				Data: &phase0.AttestationData{
+					Slot:   320,
+					Source: &phase0.Checkpoint{Epoch: 9},
+					Target: &phase0.Checkpoint{Epoch: 10},
+				},
+				CommitteeBits: committeeBits,
+			},
+		}
+		aggJSON, err := json.Marshal(agg)
+		require.NoError(t, err)
+		var aggData map[string]interface{}
+		require.NoError(t, json.Unmarshal(aggJSON, &aggData))
+
+		req.Data = map[string]interface{}{
+			"type":                Web3SignerTypeAggregateAndProofV2,
+			"fork_info":           web3SignerForkInfoData(),
+			"aggregate_and_proof": map[string]interface{}{"version": "ELECTRA", "data": aggData},
+		}
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+
+		// The slot is at the fork epoch, so the current version is used
+		domain, err := computeDomain(DomainAggregateAndProof, phase0.Version{0x01, 0x00, 0x00, 0x00}, phase0.Root{})
+		require.NoError(t, err)
+		byts, err := encoder.New().Encode(&models.SignRequest{
+			PublicKey:       _byteArray(web3SignerTestPubKey[2:]),
+			SignatureDomain: domain,
+			Object:          &models.SignRequestAggregateAttestationAndProof{Version: spec.DataVersionElectra, ElectraAggregateAndProof: agg},
+		})
+		require.NoError(t, err)
+
+		nativeReq := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		nativeReq.Storage = req.Storage
+		nativeReq.Data = map[string]interface{}{
+			"sign_req": hex.EncodeToString(byts),
+		}
+		nativeRes, err := b.HandleRequest(context.Background(), nativeReq)
+		require.NoError(t, err)
+		require.Equal(t, "0x"+nativeRes.Data["signature"].(string), web3SignerSignature(t, res))
+
+		// The version is required to pick the container
+		req.Data["aggregate_and_proof"] = map[string]interface{}{"data": aggData}
+		res, err = b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		status, _, body := web3SignerClientResponse(t, res)
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, string(body), "aggregate_and_proof: version is required")
+	})
+
+	t.Run("Successfully sign validator registration with the configured genesis fork version", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "api/v1/eth2/sign/"+web3SignerTestPubKey)
+		setupBaseStorage(t, req, func(c *Config) {
+			c.FeeRecipients = FeeRecipients{web3SignerTestPubKey: "0x9831eef7a86c19e32becdad091c1dbc974cf452a"}
+			c.ForkSchedule = ForkSchedule{{Version: phase0.Version{0x10, 0x00, 0x00, 0x00}}}
+			c.GenesisValidatorsRoot = _byteArray32("043db0d9a83813551ee2f33450d23797757d430911a9320530ad8a0eabc43efb")
+		})
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		registration := map[string]interface{}{
+			"fee_recipient": "0x9831eef7a86c19e32becdad091c1dbc974cf452a",
+			"gas_limit":     "123456",
+			"timestamp":     "1658313712",
+			"pubkey":        web3SignerTestPubKey,
+		}
+		req.Data = map[string]interface{}{
+			"type":                   Web3SignerTypeValidatorRegistration,
+			"validator_registration": registration,
+		}
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+
+		// Sign the same object through the native sign endpoint, with the domain of the configured genesis fork version
+		registrationJSON, err := json.Marshal(registration)
+		require.NoError(t, err)
+		obj := &eth2apiv1.ValidatorRegistration{}
+		require.NoError(t, json.Unmarshal(registrationJSON, obj))
+		domain, err := computeDomain(DomainApplicationBuilder, phase0.Version{0x10, 0x00, 0x00, 0x00}, phase0.Root{})
+		require.NoError(t, err)
+		byts, err := encoder.New().Encode(&models.SignRequest{
+			PublicKey:       _byteArray(web3SignerTestPubKey[2:]),
+			SignatureDomain: domain,
+			Object: &models.SignRequestRegistration{VersionedValidatorRegistration: &api.VersionedValidatorRegistration{
+				Version: spec.BuilderVersionV1,
+				V1:      obj,
+			}},
+		})
+		require.NoError(t, err)
+
+		nativeReq := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		nativeReq.Storage = req.Storage
+		nativeReq.Data = map[string]interface{}{
+			"sign_req": hex.EncodeToString(byts),
+		}
+		nativeRes, err := b.HandleRequest(context.Background(), nativeReq)
+		require.NoError(t, err)
+		require.Equal(t, "0x"+nativeRes.Data["signature"].(string), web3SignerSignature(t, res))
+	})
+
+	t.Run("Sign of unknown account", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "api/v1/eth2/sign/"+web3SignerTestPubKey[:len(web3SignerTestPubKey)-1]+"d")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data = map[string]interface{}{
+			"type":          Web3SignerTypeRandaoReveal,
+			"fork_info":     web3SignerForkInfoData(),
+			"randao_reveal": map[string]interface{}{"epoch": "12"},
+		}
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		status, contentType, body := web3SignerClientResponse(t, res)
+		require.Equal(t, http.StatusNotFound, status)
+		require.Equal(t, "text/plain", contentType)
+		require.Equal(t, "failed to sign: account not found", string(body))
+	})
+
+	t.Run("Sign of unsupported type", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "api/v1/eth2/sign/"+web3SignerTestPubKey)
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data = map[string]interface{}{
+			"type":      "DEPOSIT",
+			"fork_info": web3SignerForkInfoData(),
+		}
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		status, _, body := web3SignerClientResponse(t, res)
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Equal(t, "failed to parse web3signer sign request: unsupported sign request type \"DEPOSIT\"", string(body))
+	})
+
+	t.Run("Sign without fork info", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "api/v1/eth2/sign/"+web3SignerTestPubKey)
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data = map[string]interface{}{
+			"type":          Web3SignerTypeRandaoReveal,
+			"randao_reveal": map[string]interface{}{"epoch": "12"},
+		}
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		status, _, body := web3SignerClientResponse(t, res)
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Equal(t, "failed to parse web3signer sign request: fork_info is required", string(body))
+	})
+}
+
+func TestWeb3SignerPublicKeys(t *testing.T) {
+	b, _ := getBackend(t)
+
+	req := logical.TestRequest(t, logical.ReadOperation, "api/v1/eth2/publicKeys")
+	setupBaseStorage(t, req)
+	require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+	res, err := b.HandleRequest(context.Background(), req)
+	require.NoError(t, err)
+	status, contentType, body := web3SignerClientResponse(t, res)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "application/json", contentType)
+	var publicKeys []string
+	require.NoError(t, json.Unmarshal(body, &publicKeys))
+	require.Equal(t, []string{web3SignerTestPubKey}, publicKeys)
+}
+
+func TestWeb3SignerUpcheck(t *testing.T) {
+	b, _ := getBackend(t)
+
+	req := logical.TestRequest(t, logical.ReadOperation, "upcheck")
+	res, err := b.HandleRequest(context.Background(), req)
+	require.NoError(t, err)
+	status, contentType, body := web3SignerClientResponse(t, res)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "text/plain", contentType)
+	require.Equal(t, "OK", string(body))
+}
@@ -0,0 +1,22 @@
+package backend
+
+import (
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+)
+
+// ErrRequesterIdentity is returned when a change is requested without an identity to record.
+var ErrRequesterIdentity = errors.New("requester identity is required")
+
+// requesterIdentity returns the identity of the requester, recorded with the changes it makes.
+// Tokens without an identity entity, e.g. the root token, are recorded by their display name.
+func requesterIdentity(req *logical.Request) (string, error) {
+	switch {
+	case req.EntityID != "":
+		return "entity:" + req.EntityID, nil
+	case req.DisplayName != "":
+		return "token:" + req.DisplayName, nil
+	default:
+		return "", ErrRequesterIdentity
+	}
+}
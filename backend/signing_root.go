@@ -0,0 +1,117 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/bloxapp/eth2-key-manager/signer"
+	ssz "github.com/ferranbt/fastssz"
+	"github.com/pkg/errors"
+
+	"github.com/bloxapp/key-vault/keymanager/models"
+)
+
+// ErrSigningRootMismatch is returned when the requested signing root does not match the signed object.
+var ErrSigningRootMismatch = errors.New("signing root does not match the sign request object")
+
+// verifySigningRoot computes the signing root of the given request object
+// and verifies it matches the requested signing root, if any.
+func verifySigningRoot(signReq *models.SignRequest) (phase0.Root, error) {
+	root, err := computeSigningRoot(signReq)
+	if err != nil {
+		return phase0.Root{}, errors.Wrap(err, "failed to compute signing root")
+	}
+
+	if requested := signReq.GetSigningRoot(); len(requested) != 0 && !bytes.Equal(requested, root[:]) {
+		return phase0.Root{}, errors.Wrapf(ErrSigningRootMismatch, "requested %s, computed %s", hex.EncodeToString(requested), hex.EncodeToString(root[:]))
+	}
+	return root, nil
+}
+
+// computeSigningRoot returns the signing root of the given request object and signature domain.
+func computeSigningRoot(signReq *models.SignRequest) (phase0.Root, error) {
+	var obj ssz.HashRoot
+	switch t := signReq.GetObject().(type) {
+	case *models.SignRequestBlock:
+		if t.VersionedBeaconBlock == nil {
+			return phase0.Root{}, errors.New("block is nil")
+		}
+		switch t.VersionedBeaconBlock.Version {
+		case spec.DataVersionPhase0:
+			obj = t.VersionedBeaconBlock.Phase0
+		case spec.DataVersionAltair:
+			obj = t.VersionedBeaconBlock.Altair
+		case spec.DataVersionBellatrix:
+			obj = t.VersionedBeaconBlock.Bellatrix
+		case spec.DataVersionCapella:
+			obj = t.VersionedBeaconBlock.Capella
+		case spec.DataVersionDeneb:
+			obj = t.VersionedBeaconBlock.Deneb
+		case spec.DataVersionElectra:
+			obj = t.VersionedBeaconBlock.Electra
+		default:
+			return phase0.Root{}, errors.Errorf("unsupported block version %d", t.VersionedBeaconBlock.Version)
+		}
+	case *models.SignRequestBlindedBlock:
+		if t.VersionedBlindedBeaconBlock == nil {
+			return phase0.Root{}, errors.New("blinded block is nil")
+		}
+		switch t.VersionedBlindedBeaconBlock.Version {
+		case spec.DataVersionBellatrix:
+			obj = t.VersionedBlindedBeaconBlock.Bellatrix
+		case spec.DataVersionCapella:
+			obj = t.VersionedBlindedBeaconBlock.Capella
+		case spec.DataVersionDeneb:
+			obj = t.VersionedBlindedBeaconBlock.Deneb
+		case spec.DataVersionElectra:
+			obj = t.VersionedBlindedBeaconBlock.Electra
+		default:
+			return phase0.Root{}, errors.Errorf("unsupported blinded block version %d", t.VersionedBlindedBeaconBlock.Version)
+		}
+	case *models.SignRequestBlockHeader:
+		obj = t.BeaconBlockHeader
+	case *models.SignRequestAttestationData:
+		obj = t.AttestationData
+	case *models.SignRequestSlot:
+		obj = signer.SSZUint64(t.Slot)
+	case *models.SignRequestEpoch:
+		obj = signer.SSZUint64(t.Epoch)
+	case *models.SignRequestAggregateAttestationAndProof:
+		if t.Version >= spec.DataVersionElectra {
+			obj = t.ElectraAggregateAndProof
+		} else {
+			obj = t.AggregateAttestationAndProof
+		}
+	case *models.SignRequestSyncCommitteeMessage:
+		root := signer.SSZBytes(t.Root)
+		obj = &root
+	case *models.SignRequestSyncAggregatorSelectionData:
+		obj = t.SyncAggregatorSelectionData
+	case *models.SignRequestContributionAndProof:
+		obj = t.ContributionAndProof
+	case *models.SignRequestRegistration:
+		if t.VersionedValidatorRegistration == nil {
+			return phase0.Root{}, errors.New("registration is nil")
+		}
+		switch t.VersionedValidatorRegistration.Version {
+		case spec.BuilderVersionV1:
+			obj = t.VersionedValidatorRegistration.V1
+		default:
+			return phase0.Root{}, errors.Errorf("unsupported registration version %d", t.VersionedValidatorRegistration.Version)
+		}
+	case *models.SignRequestVoluntaryExit:
+		obj = t.VoluntaryExit
+	case *models.SignRequestBLSToExecutionChange:
+		obj = t.BLSToExecutionChange
+	default:
+		return phase0.Root{}, errors.New("sign request: not supported")
+	}
+
+	if v := reflect.ValueOf(obj); v.Kind() == reflect.Ptr && v.IsNil() {
+		return phase0.Root{}, errors.New("sign request object is nil")
+	}
+	return signer.ComputeETHSigningRoot(obj, signReq.GetSignatureDomain())
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+)
+
+// Paths
+const (
+	AccountStatusPath = "accountStatus/%s"
+)
+
+// AccountStatus is the signing status of an account, accounts without a status are enabled.
+type AccountStatus struct {
+	Enabled   bool      `json:"enabled"`
+	Reason    string    `json:"reason"`
+	ChangedBy string    `json:"changed_by"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// SaveAccountStatus saves the status of the given public key.
+func (store *HashicorpVaultStore) SaveAccountStatus(pubKey []byte, status *AccountStatus) error {
+	if pubKey == nil {
+		return errors.New("pubKey must not be nil")
+	}
+
+	if status == nil {
+		return errors.New("account status could not be nil")
+	}
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal account status")
+	}
+
+	return store.storage.Put(store.ctx, &logical.StorageEntry{
+		Key:      fmt.Sprintf(AccountStatusPath, store.identifierFromKey(pubKey)),
+		Value:    data,
+		SealWrap: false,
+	})
+}
+
+// RetrieveAccountStatus retrieves the status of the given public key.
+func (store *HashicorpVaultStore) RetrieveAccountStatus(pubKey []byte) (*AccountStatus, bool, error) {
+	if pubKey == nil {
+		return nil, false, errors.New("public key could not be nil")
+	}
+
+	entry, err := store.storage.Get(store.ctx, fmt.Sprintf(AccountStatusPath, store.identifierFromKey(pubKey)))
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Return nothing if there is no record
+	if entry == nil {
+		return nil, false, nil
+	}
+
+	status := &AccountStatus{}
+	if err := json.Unmarshal(entry.Value, status); err != nil {
+		return nil, false, errors.Wrap(err, "failed to unmarshal account status")
+	}
+	return status, true, nil
+}
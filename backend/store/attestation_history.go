@@ -0,0 +1,234 @@
+package store
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+)
+
+// Paths
+const (
+	AttestationHistoryPath = "attestationHistory/%s"
+)
+
+const (
+	// attestationHistoryHeaderLength is the length of the encoded history watermark.
+	attestationHistoryHeaderLength = 16
+
+	// attestationRecordLength is the length of an encoded attestation record.
+	attestationRecordLength = 8 + 8 + phase0.RootLength
+)
+
+// AttestationRecord is a signed attestation of the attestation history.
+type AttestationRecord struct {
+	SourceEpoch phase0.Epoch
+	TargetEpoch phase0.Epoch
+	SigningRoot phase0.Root
+}
+
+// AttestationHistory contains the attestations signed by a public key, ordered by target epoch.
+// Attestations signed before the history was started or pruned from it are not recorded,
+// they are covered by the watermark: attestations with a source epoch lower than MinSourceEpoch
+// or a target epoch lower than or equal to MinTargetEpoch are refused.
+type AttestationHistory struct {
+	MinSourceEpoch phase0.Epoch
+	MinTargetEpoch phase0.Epoch
+	Records        []*AttestationRecord
+}
+
+// Find returns the record of the given target epoch.
+func (h *AttestationHistory) Find(target phase0.Epoch) *AttestationRecord {
+	i := sort.Search(len(h.Records), func(i int) bool {
+		return h.Records[i].TargetEpoch >= target
+	})
+	if i < len(h.Records) && h.Records[i].TargetEpoch == target {
+		return h.Records[i]
+	}
+	return nil
+}
+
+// Add adds the given record, keeping the records ordered by target epoch.
+func (h *AttestationHistory) Add(record *AttestationRecord) {
+	i := sort.Search(len(h.Records), func(i int) bool {
+		return h.Records[i].TargetEpoch >= record.TargetEpoch
+	})
+	h.Records = append(h.Records, nil)
+	copy(h.Records[i+1:], h.Records[i:])
+	h.Records[i] = record
+}
+
+// Prune removes the records with a target epoch lower than the given one and raises the watermark over them.
+func (h *AttestationHistory) Prune(minTarget phase0.Epoch) {
+	i := 0
+	for ; i < len(h.Records) && h.Records[i].TargetEpoch < minTarget; i++ {
+		if h.Records[i].SourceEpoch > h.MinSourceEpoch {
+			h.MinSourceEpoch = h.Records[i].SourceEpoch
+		}
+		if h.Records[i].TargetEpoch > h.MinTargetEpoch {
+			h.MinTargetEpoch = h.Records[i].TargetEpoch
+		}
+	}
+	h.Records = h.Records[i:]
+}
+
+// RaiseWatermark raises the watermark to at least the given epochs.
+func (h *AttestationHistory) RaiseWatermark(source, target phase0.Epoch) {
+	if source > h.MinSourceEpoch {
+		h.MinSourceEpoch = source
+	}
+	if target > h.MinTargetEpoch {
+		h.MinTargetEpoch = target
+	}
+}
+
+// MarshalBinary encodes the history as the watermark followed by the records.
+func (h *AttestationHistory) MarshalBinary() ([]byte, error) {
+	data := make([]byte, attestationHistoryHeaderLength+len(h.Records)*attestationRecordLength)
+	binary.LittleEndian.PutUint64(data[0:8], uint64(h.MinSourceEpoch))
+	binary.LittleEndian.PutUint64(data[8:16], uint64(h.MinTargetEpoch))
+	for i, record := range h.Records {
+		offset := attestationHistoryHeaderLength + i*attestationRecordLength
+		binary.LittleEndian.PutUint64(data[offset:offset+8], uint64(record.SourceEpoch))
+		binary.LittleEndian.PutUint64(data[offset+8:offset+16], uint64(record.TargetEpoch))
+		copy(data[offset+16:offset+attestationRecordLength], record.SigningRoot[:])
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a history encoded by MarshalBinary.
+func (h *AttestationHistory) UnmarshalBinary(data []byte) error {
+	if len(data) < attestationHistoryHeaderLength || (len(data)-attestationHistoryHeaderLength)%attestationRecordLength != 0 {
+		return errors.Errorf("invalid attestation history length %d", len(data))
+	}
+	h.MinSourceEpoch = phase0.Epoch(binary.LittleEndian.Uint64(data[0:8]))
+	h.MinTargetEpoch = phase0.Epoch(binary.LittleEndian.Uint64(data[8:16]))
+	h.Records = make([]*AttestationRecord, (len(data)-attestationHistoryHeaderLength)/attestationRecordLength)
+	for i := range h.Records {
+		offset := attestationHistoryHeaderLength + i*attestationRecordLength
+		record := &AttestationRecord{
+			SourceEpoch: phase0.Epoch(binary.LittleEndian.Uint64(data[offset : offset+8])),
+			TargetEpoch: phase0.Epoch(binary.LittleEndian.Uint64(data[offset+8 : offset+16])),
+		}
+		copy(record.SigningRoot[:], data[offset+16:offset+attestationRecordLength])
+		h.Records[i] = record
+	}
+	return nil
+}
+
+// SaveAttestationHistory saves the attestation history of the given public key.
+func (store *HashicorpVaultStore) SaveAttestationHistory(pubKey []byte, history *AttestationHistory) error {
+	if pubKey == nil {
+		return errors.New("pubKey must not be nil")
+	}
+
+	if history == nil {
+		return errors.New("attestation history could not be nil")
+	}
+
+	data, err := history.MarshalBinary()
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal attestation history")
+	}
+
+	return store.storage.Put(store.ctx, &logical.StorageEntry{
+		Key:      fmt.Sprintf(AttestationHistoryPath, store.identifierFromKey(pubKey)),
+		Value:    data,
+		SealWrap: false,
+	})
+}
+
+// RetrieveAttestationHistory retrieves the attestation history of the given public key.
+func (store *HashicorpVaultStore) RetrieveAttestationHistory(pubKey []byte) (*AttestationHistory, bool, error) {
+	if pubKey == nil {
+		return nil, false, errors.New("public key could not be nil")
+	}
+
+	entry, err := store.storage.Get(store.ctx, fmt.Sprintf(AttestationHistoryPath, store.identifierFromKey(pubKey)))
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Return nothing if there is no record
+	if entry == nil {
+		return nil, false, nil
+	}
+
+	history := &AttestationHistory{}
+	if err := history.UnmarshalBinary(entry.Value); err != nil {
+		return nil, false, errors.Wrap(err, "failed to unmarshal attestation history")
+	}
+	return history, true, nil
+}
+
+// RaiseAttestationWatermark raises the highest attestation and, if the public key has an attestation history,
+// its watermark to at least the given epochs. Neither is ever lowered.
+func (store *HashicorpVaultStore) RaiseAttestationWatermark(pubKey []byte, source, target phase0.Epoch) error {
+	highest, found, err := store.RetrieveHighestAttestation(pubKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve highest attestation")
+	}
+	// The highest attestation is only saved when raised, so it keeps its signing root otherwise.
+	raised := false
+	if !found || highest == nil {
+		highest = &phase0.AttestationData{
+			Source: &phase0.Checkpoint{},
+			Target: &phase0.Checkpoint{},
+		}
+		raised = true
+	}
+	if source > highest.Source.Epoch {
+		highest.Source.Epoch = source
+		raised = true
+	}
+	if target > highest.Target.Epoch {
+		highest.Target.Epoch = target
+		raised = true
+	}
+	if raised {
+		if err := store.SaveHighestAttestation(pubKey, highest); err != nil {
+			return errors.Wrap(err, "failed to save highest attestation")
+		}
+	}
+
+	history, found, err := store.RetrieveAttestationHistory(pubKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve attestation history")
+	}
+	if !found {
+		return nil
+	}
+	history.RaiseWatermark(source, target)
+	if err := store.SaveAttestationHistory(pubKey, history); err != nil {
+		return errors.Wrap(err, "failed to save attestation history")
+	}
+	return nil
+}
+
+// SetAttestationWatermark sets the highest attestation and, if the public key has an attestation history,
+// its watermark to the given epochs. Unlike RaiseAttestationWatermark, they may be lowered.
+func (store *HashicorpVaultStore) SetAttestationWatermark(pubKey []byte, source, target phase0.Epoch) error {
+	if err := store.SaveHighestAttestation(pubKey, &phase0.AttestationData{
+		Source: &phase0.Checkpoint{Epoch: source},
+		Target: &phase0.Checkpoint{Epoch: target},
+	}); err != nil {
+		return errors.Wrap(err, "failed to save highest attestation")
+	}
+
+	history, found, err := store.RetrieveAttestationHistory(pubKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve attestation history")
+	}
+	if !found {
+		return nil
+	}
+	history.MinSourceEpoch = source
+	history.MinTargetEpoch = target
+	if err := store.SaveAttestationHistory(pubKey, history); err != nil {
+		return errors.Wrap(err, "failed to save attestation history")
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bloxapp/key-vault/backend/store"
+)
+
+func TestAttestationHistory(t *testing.T) {
+	record := func(source, target phase0.Epoch) *store.AttestationRecord {
+		return &store.AttestationRecord{SourceEpoch: source, TargetEpoch: target, SigningRoot: phase0.Root{byte(target)}}
+	}
+
+	t.Run("Keep records ordered by target", func(t *testing.T) {
+		history := &store.AttestationHistory{}
+		history.Add(record(3, 4))
+		history.Add(record(1, 2))
+		history.Add(record(2, 3))
+
+		require.Equal(t, []*store.AttestationRecord{record(1, 2), record(2, 3), record(3, 4)}, history.Records)
+		require.Equal(t, record(2, 3), history.Find(3))
+		require.Nil(t, history.Find(5))
+	})
+
+	t.Run("Prune raises the watermark", func(t *testing.T) {
+		history := &store.AttestationHistory{MinSourceEpoch: 1, MinTargetEpoch: 1}
+		history.Add(record(1, 2))
+		history.Add(record(3, 4))
+		history.Add(record(4, 6))
+
+		history.Prune(5)
+		require.Equal(t, []*store.AttestationRecord{record(4, 6)}, history.Records)
+		require.EqualValues(t, 3, history.MinSourceEpoch)
+		require.EqualValues(t, 4, history.MinTargetEpoch)
+	})
+
+	t.Run("Encode and decode", func(t *testing.T) {
+		history := &store.AttestationHistory{MinSourceEpoch: 7, MinTargetEpoch: 8}
+		history.Add(record(9, 10))
+		history.Add(record(10, 11))
+
+		data, err := history.MarshalBinary()
+		require.NoError(t, err)
+		require.Len(t, data, 16+2*48)
+
+		decoded := &store.AttestationHistory{}
+		require.NoError(t, decoded.UnmarshalBinary(data))
+		require.Equal(t, history, decoded)
+
+		require.EqualError(t, decoded.UnmarshalBinary(data[:20]), "invalid attestation history length 20")
+	})
+
+	t.Run("Save and retrieve", func(t *testing.T) {
+		storage := getSlashingStorage().(*store.HashicorpVaultStore)
+		pubKey := []byte{1, 2, 3}
+
+		_, found, err := storage.RetrieveAttestationHistory(pubKey)
+		require.NoError(t, err)
+		require.False(t, found)
+
+		history := &store.AttestationHistory{MinSourceEpoch: 1, MinTargetEpoch: 2}
+		history.Add(record(2, 3))
+		require.NoError(t, storage.SaveAttestationHistory(pubKey, history))
+
+		retrieved, found, err := storage.RetrieveAttestationHistory(pubKey)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, history, retrieved)
+	})
+
+	t.Run("Raise attestation watermark", func(t *testing.T) {
+		storage := getSlashingStorage().(*store.HashicorpVaultStore)
+		pubKey := []byte{1, 2, 3}
+
+		require.NoError(t, storage.RaiseAttestationWatermark(pubKey, 5, 6))
+		highest, found, err := storage.RetrieveHighestAttestation(pubKey)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.EqualValues(t, 5, highest.Source.Epoch)
+		require.EqualValues(t, 6, highest.Target.Epoch)
+
+		require.NoError(t, storage.SaveAttestationHistory(pubKey, &store.AttestationHistory{MinSourceEpoch: 5, MinTargetEpoch: 6}))
+		require.NoError(t, storage.RaiseAttestationWatermark(pubKey, 4, 10))
+		highest, _, err = storage.RetrieveHighestAttestation(pubKey)
+		require.NoError(t, err)
+		require.EqualValues(t, 5, highest.Source.Epoch)
+		require.EqualValues(t, 10, highest.Target.Epoch)
+		history, _, err := storage.RetrieveAttestationHistory(pubKey)
+		require.NoError(t, err)
+		require.EqualValues(t, 5, history.MinSourceEpoch)
+		require.EqualValues(t, 10, history.MinTargetEpoch)
+	})
+}
+
+func TestSetAttestationWatermark(t *testing.T) {
+	storage := getSlashingStorage().(*store.HashicorpVaultStore)
+	pubKey := []byte{1, 2, 3}
+	require.NoError(t, storage.SaveAttestationHistory(pubKey, &store.AttestationHistory{MinSourceEpoch: 5, MinTargetEpoch: 6}))
+
+	require.NoError(t, storage.SetAttestationWatermark(pubKey, 1, 2))
+	highest, found, err := storage.RetrieveHighestAttestation(pubKey)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.EqualValues(t, 1, highest.Source.Epoch)
+	require.EqualValues(t, 2, highest.Target.Epoch)
+	history, _, err := storage.RetrieveAttestationHistory(pubKey)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, history.MinSourceEpoch)
+	require.EqualValues(t, 2, history.MinTargetEpoch)
+}
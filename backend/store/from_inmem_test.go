@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"testing"
 
+	"github.com/attestantio/go-eth2-client/spec/phase0"
 	ethkeymanager "github.com/bloxapp/eth2-key-manager"
 	"github.com/bloxapp/eth2-key-manager/core"
 	"github.com/bloxapp/eth2-key-manager/encryptor/keystorev4"
@@ -124,3 +125,58 @@ func TestImportFromInMem(t *testing.T) {
 	require.Equal(t, inMemAccounts[0].ID().String(), hashiAcc2.ID().String())
 	require.Equal(t, inMemAccounts[0].ValidatorPublicKey(), hashiAcc2.ValidatorPublicKey())
 }
+
+func TestImportFromInMemV2KeepsSlashingRecords(t *testing.T) {
+	inMemStore, _, inMemAccounts := baseKeyVault(
+		_byteArray("0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1fff"),
+		t,
+	)
+	lowerPubKey := inMemAccounts[0].ValidatorPublicKey()
+	higherPubKey := inMemAccounts[1].ValidatorPublicKey()
+	for _, pubKey := range [][]byte{lowerPubKey, higherPubKey} {
+		require.NoError(t, inMemStore.SaveHighestAttestation(pubKey, &phase0.AttestationData{
+			Source: &phase0.Checkpoint{Epoch: 10},
+			Target: &phase0.Checkpoint{Epoch: 11},
+		}))
+		require.NoError(t, inMemStore.SaveHighestProposal(pubKey, 100))
+	}
+
+	// slashing records kept from deleted accounts
+	hashiStorage := &logical.InmemStorage{}
+	existing := store.NewHashicorpVaultStore(context.Background(), hashiStorage, core.PraterNetwork)
+	require.NoError(t, existing.SaveHighestAttestation(lowerPubKey, &phase0.AttestationData{
+		Source: &phase0.Checkpoint{Epoch: 20},
+		Target: &phase0.Checkpoint{Epoch: 21},
+	}))
+	require.NoError(t, existing.SaveHighestProposal(lowerPubKey, 200))
+	require.NoError(t, existing.SaveHighestAttestation(higherPubKey, &phase0.AttestationData{
+		Source: &phase0.Checkpoint{Epoch: 5},
+		Target: &phase0.Checkpoint{Epoch: 6},
+	}))
+	require.NoError(t, existing.SaveHighestProposal(higherPubKey, 50))
+
+	hashi, _, err := store.FromInMemoryStoreV2(context.Background(), inMemStore, hashiStorage, false)
+	require.NoError(t, err)
+
+	// the stored records are never lowered
+	att, found, err := hashi.RetrieveHighestAttestation(lowerPubKey)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.EqualValues(t, 20, att.Source.Epoch)
+	require.EqualValues(t, 21, att.Target.Epoch)
+	slot, found, err := hashi.RetrieveHighestProposal(lowerPubKey)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.EqualValues(t, 200, slot)
+
+	// but raised by higher imported ones
+	att, found, err = hashi.RetrieveHighestAttestation(higherPubKey)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.EqualValues(t, 10, att.Source.Epoch)
+	require.EqualValues(t, 11, att.Target.Epoch)
+	slot, found, err = hashi.RetrieveHighestProposal(higherPubKey)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.EqualValues(t, 100, slot)
+}
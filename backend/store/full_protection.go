@@ -0,0 +1,133 @@
+package store
+
+import (
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/bloxapp/eth2-key-manager/core"
+	slashingprotection "github.com/bloxapp/eth2-key-manager/slashing_protection"
+	"github.com/pkg/errors"
+)
+
+// FullProtection is a slashing protector which records every signed attestation in the attestation history
+// and checks double and surround votes against it, instead of refusing anything below the highest attestation.
+// Re-signing an attestation with the recorded signing root is allowed.
+// It protects a single sign request, since the signing root isn't passed to the protector.
+// Proposals are protected by the highest proposal, like NormalProtection.
+type FullProtection struct {
+	*slashingprotection.NormalProtection
+	store       *HashicorpVaultStore
+	signingRoot phase0.Root
+	pruneEpochs phase0.Epoch
+}
+
+// NewFullProtection is the constructor of FullProtection for a sign request of the given signing root.
+// Records older than pruneEpochs epochs before the highest recorded target are pruned, 0 keeps all records.
+func NewFullProtection(store *HashicorpVaultStore, signingRoot phase0.Root, pruneEpochs phase0.Epoch) *FullProtection {
+	return &FullProtection{
+		NormalProtection: slashingprotection.NewNormalProtection(store),
+		store:            store,
+		signingRoot:      signingRoot,
+		pruneEpochs:      pruneEpochs,
+	}
+}
+
+// IsSlashableAttestation detects double, surround and surrounded votes against the attestation history.
+func (protector *FullProtection) IsSlashableAttestation(pubKey []byte, attestation *phase0.AttestationData) (*core.AttestationSlashStatus, error) {
+	if attestation == nil {
+		return nil, errors.New("attestation data could not be nil")
+	}
+
+	history, err := protector.history(pubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	status := func(s core.VoteDetectionType) *core.AttestationSlashStatus {
+		return &core.AttestationSlashStatus{
+			Attestation: attestation,
+			Status:      s,
+		}
+	}
+
+	source, target := attestation.Source.Epoch, attestation.Target.Epoch
+	if record := history.Find(target); record != nil {
+		if record.SourceEpoch == source && record.SigningRoot == protector.signingRoot {
+			return nil, nil
+		}
+		return status(core.DoubleVote), nil
+	}
+	if source < history.MinSourceEpoch || target <= history.MinTargetEpoch {
+		return status(core.HighestAttestationVote), nil
+	}
+	for _, record := range history.Records {
+		if source < record.SourceEpoch && record.TargetEpoch < target {
+			return status(core.SurroundingVote), nil
+		}
+		if record.SourceEpoch < source && target < record.TargetEpoch {
+			return status(core.SurroundedVote), nil
+		}
+	}
+	return nil, nil
+}
+
+// UpdateHighestAttestation records the given attestation in the attestation history, prunes it and raises the highest attestation.
+func (protector *FullProtection) UpdateHighestAttestation(pubKey []byte, attestation *phase0.AttestationData) error {
+	if attestation == nil {
+		return errors.New("attestation data could not be nil")
+	}
+
+	history, err := protector.history(pubKey)
+	if err != nil {
+		return err
+	}
+
+	if history.Find(attestation.Target.Epoch) == nil {
+		history.Add(&AttestationRecord{
+			SourceEpoch: attestation.Source.Epoch,
+			TargetEpoch: attestation.Target.Epoch,
+			SigningRoot: protector.signingRoot,
+		})
+	}
+	if highest := history.Records[len(history.Records)-1].TargetEpoch; protector.pruneEpochs > 0 && highest > protector.pruneEpochs {
+		history.Prune(highest - protector.pruneEpochs)
+	}
+	if err := protector.store.SaveAttestationHistory(pubKey, history); err != nil {
+		return errors.Wrap(err, "could not save attestation history")
+	}
+
+	return protector.NormalProtection.UpdateHighestAttestation(pubKey, attestation)
+}
+
+// history returns the attestation history of the given public key.
+// A missing history is started with the highest attestation as its watermark. Attestations signed while the history
+// was disabled only raise the highest attestation: the watermark is raised to it when it is above the recorded ones.
+func (protector *FullProtection) history(pubKey []byte) (*AttestationHistory, error) {
+	history, found, err := protector.store.RetrieveAttestationHistory(pubKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not retrieve attestation history")
+	}
+
+	highest, highestFound, err := protector.store.RetrieveHighestAttestation(pubKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not retrieve highest attestation")
+	}
+	if !found {
+		if !highestFound || highest == nil {
+			return nil, errors.New("highest attestation data is not found, can't determine if attestation is slashable")
+		}
+		return &AttestationHistory{
+			MinSourceEpoch: highest.Source.Epoch,
+			MinTargetEpoch: highest.Target.Epoch,
+		}, nil
+	}
+
+	if highestFound && highest != nil {
+		lastTarget := history.MinTargetEpoch
+		if len(history.Records) > 0 && history.Records[len(history.Records)-1].TargetEpoch > lastTarget {
+			lastTarget = history.Records[len(history.Records)-1].TargetEpoch
+		}
+		if highest.Target.Epoch > lastTarget {
+			history.RaiseWatermark(highest.Source.Epoch, highest.Target.Epoch)
+		}
+	}
+	return history, nil
+}
@@ -0,0 +1,107 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/bloxapp/eth2-key-manager/core"
+	slashingprotection "github.com/bloxapp/eth2-key-manager/slashing_protection"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bloxapp/key-vault/backend/store"
+)
+
+func TestFullProtection(t *testing.T) {
+	pubKey := []byte{1, 2, 3}
+	att := func(source, target phase0.Epoch) *phase0.AttestationData {
+		return &phase0.AttestationData{
+			Source: &phase0.Checkpoint{Epoch: source},
+			Target: &phase0.Checkpoint{Epoch: target},
+		}
+	}
+	setup := func(t *testing.T) *store.HashicorpVaultStore {
+		storage := getSlashingStorage().(*store.HashicorpVaultStore)
+		require.NoError(t, storage.SaveHighestAttestation(pubKey, att(10, 11)))
+		return storage
+	}
+	sign := func(t *testing.T, storage *store.HashicorpVaultStore, root phase0.Root, pruneEpochs phase0.Epoch, data *phase0.AttestationData) core.VoteDetectionType {
+		protector := store.NewFullProtection(storage, root, pruneEpochs)
+		status, err := protector.IsSlashableAttestation(pubKey, data)
+		require.NoError(t, err)
+		if status != nil {
+			return status.Status
+		}
+		require.NoError(t, protector.UpdateHighestAttestation(pubKey, data))
+		return ""
+	}
+
+	t.Run("Detect slashable votes", func(t *testing.T) {
+		storage := setup(t)
+		require.Empty(t, sign(t, storage, phase0.Root{1}, 0, att(12, 15)))
+		require.Empty(t, sign(t, storage, phase0.Root{2}, 0, att(15, 20)))
+
+		require.Equal(t, core.DoubleVote, sign(t, storage, phase0.Root{3}, 0, att(15, 20)))
+		require.Equal(t, core.DoubleVote, sign(t, storage, phase0.Root{2}, 0, att(14, 20)))
+		require.Equal(t, core.SurroundingVote, sign(t, storage, phase0.Root{3}, 0, att(11, 16)))
+		require.Equal(t, core.SurroundedVote, sign(t, storage, phase0.Root{3}, 0, att(16, 18)))
+		require.Equal(t, core.HighestAttestationVote, sign(t, storage, phase0.Root{3}, 0, att(9, 13)))
+		require.Equal(t, core.HighestAttestationVote, sign(t, storage, phase0.Root{3}, 0, att(10, 11)))
+
+		// Attestations below the highest one that are not slashable are signed
+		require.Empty(t, sign(t, storage, phase0.Root{3}, 0, att(12, 13)))
+
+		highest, _, err := storage.RetrieveHighestAttestation(pubKey)
+		require.NoError(t, err)
+		require.EqualValues(t, 15, highest.Source.Epoch)
+		require.EqualValues(t, 20, highest.Target.Epoch)
+	})
+
+	t.Run("Re-sign the same attestation", func(t *testing.T) {
+		storage := setup(t)
+		require.Empty(t, sign(t, storage, phase0.Root{1}, 0, att(12, 15)))
+		require.Empty(t, sign(t, storage, phase0.Root{1}, 0, att(12, 15)))
+
+		history, _, err := storage.RetrieveAttestationHistory(pubKey)
+		require.NoError(t, err)
+		require.Len(t, history.Records, 1)
+	})
+
+	t.Run("Prune old records", func(t *testing.T) {
+		storage := setup(t)
+		require.Empty(t, sign(t, storage, phase0.Root{1}, 5, att(12, 13)))
+		require.Empty(t, sign(t, storage, phase0.Root{2}, 5, att(13, 16)))
+		require.Empty(t, sign(t, storage, phase0.Root{3}, 5, att(16, 20)))
+
+		history, _, err := storage.RetrieveAttestationHistory(pubKey)
+		require.NoError(t, err)
+		require.Len(t, history.Records, 2)
+		require.EqualValues(t, 12, history.MinSourceEpoch)
+		require.EqualValues(t, 13, history.MinTargetEpoch)
+
+		// The pruned attestation can't be re-signed anymore
+		require.Equal(t, core.HighestAttestationVote, sign(t, storage, phase0.Root{1}, 5, att(12, 13)))
+	})
+
+	t.Run("Protect attestations signed while the history was disabled", func(t *testing.T) {
+		storage := setup(t)
+		require.Empty(t, sign(t, storage, phase0.Root{1}, 0, att(12, 15)))
+
+		// Signed by NormalProtection, which doesn't record it in the history
+		normal := slashingprotection.NewNormalProtection(storage)
+		require.NoError(t, normal.UpdateHighestAttestation(pubKey, att(20, 25)))
+
+		require.Equal(t, core.HighestAttestationVote, sign(t, storage, phase0.Root{2}, 0, att(21, 24)))
+		require.Equal(t, core.HighestAttestationVote, sign(t, storage, phase0.Root{2}, 0, att(19, 24)))
+		require.Equal(t, core.HighestAttestationVote, sign(t, storage, phase0.Root{2}, 0, att(21, 25)))
+		require.Empty(t, sign(t, storage, phase0.Root{2}, 0, att(25, 26)))
+
+		// The recorded attestation can still be re-signed
+		require.Empty(t, sign(t, storage, phase0.Root{1}, 0, att(12, 15)))
+	})
+
+	t.Run("Require highest attestation", func(t *testing.T) {
+		storage := getSlashingStorage().(*store.HashicorpVaultStore)
+		_, err := store.NewFullProtection(storage, phase0.Root{}, 0).IsSlashableAttestation(pubKey, att(1, 2))
+		require.EqualError(t, err, "highest attestation data is not found, can't determine if attestation is slashable")
+	})
+}
@@ -0,0 +1,207 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+)
+
+// Paths
+const (
+	WALBase          = "wallet/wal/"
+	WALEntryBase     = WALBase + "entries/"
+	WALEntryPath     = WALEntryBase + "%08d"
+	WALCommittedPath = WALBase + "committed"
+)
+
+// WALEntry is the value of a storage key before it was first changed by a storage update.
+type WALEntry struct {
+	Key    string `json:"key"`
+	Exists bool   `json:"exists"`
+	Value  []byte `json:"value,omitempty"`
+}
+
+// journalStorage is a logical.Storage which writes the previous value of each key to the write-ahead log
+// before changing it, so the changes can be rolled back.
+type journalStorage struct {
+	logical.Storage
+
+	recorded map[string]bool
+}
+
+// Put implements logical.Storage.
+func (s *journalStorage) Put(ctx context.Context, entry *logical.StorageEntry) error {
+	if err := s.record(ctx, entry.Key); err != nil {
+		return err
+	}
+	return s.Storage.Put(ctx, entry)
+}
+
+// Delete implements logical.Storage.
+func (s *journalStorage) Delete(ctx context.Context, key string) error {
+	if err := s.record(ctx, key); err != nil {
+		return err
+	}
+	return s.Storage.Delete(ctx, key)
+}
+
+// record writes the current value of the given key to the write-ahead log, unless it is already there.
+func (s *journalStorage) record(ctx context.Context, key string) error {
+	if s.recorded[key] {
+		return nil
+	}
+
+	walEntry := &WALEntry{
+		Key: key,
+	}
+	prev, err := s.Storage.Get(ctx, key)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get %s", key)
+	}
+	if prev != nil {
+		walEntry.Exists = true
+		walEntry.Value = prev.Value
+	}
+
+	entry, err := logical.StorageEntryJSON(fmt.Sprintf(WALEntryPath, len(s.recorded)), walEntry)
+	if err != nil {
+		return errors.Wrap(err, "failed to build write-ahead log entry")
+	}
+	if err := s.Storage.Put(ctx, entry); err != nil {
+		return errors.Wrap(err, "failed to save write-ahead log entry")
+	}
+	s.recorded[key] = true
+	return nil
+}
+
+// UpdateTransactionally runs the given update on a storage writing its changes to a write-ahead log first.
+// The changes are rolled back if the update fails, or by RecoverStorageUpdate if it is interrupted by a restart.
+// Storage updates must not run concurrently.
+func UpdateTransactionally(ctx context.Context, s logical.Storage, update func(logical.Storage) error) error {
+	if _, err := RecoverStorageUpdate(ctx, s); err != nil {
+		return err
+	}
+
+	if err := update(&journalStorage{Storage: s, recorded: make(map[string]bool)}); err != nil {
+		if rollbackErr := rollbackStorageUpdate(ctx, s); rollbackErr != nil {
+			return errors.Wrapf(rollbackErr, "failed to roll back storage update (%s)", err)
+		}
+		return err
+	}
+
+	// Once committed, the update is rolled forward: its write-ahead log is discarded.
+	if err := s.Put(ctx, &logical.StorageEntry{Key: WALCommittedPath, Value: []byte("true")}); err != nil {
+		if rollbackErr := rollbackStorageUpdate(ctx, s); rollbackErr != nil {
+			return errors.Wrapf(rollbackErr, "failed to roll back storage update (%s)", err)
+		}
+		return errors.Wrap(err, "failed to commit storage update")
+	}
+	// The update is complete even if the write-ahead log isn't discarded, RecoverStorageUpdate discards it later.
+	_ = discardStorageUpdate(ctx, s)
+	return nil
+}
+
+// RecoverStorageUpdate completes a storage update interrupted by a restart: it is rolled forward if it was committed,
+// and rolled back otherwise. It returns whether there was an interrupted update.
+func RecoverStorageUpdate(ctx context.Context, s logical.Storage) (bool, error) {
+	keys, err := s.List(ctx, WALBase)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to list write-ahead log")
+	}
+	if len(keys) == 0 {
+		return false, nil
+	}
+
+	committed, err := s.Get(ctx, WALCommittedPath)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get storage update commit")
+	}
+	if committed != nil {
+		// Wallets loaded while it ran may be half-applied, they are not served from the cache anymore.
+		if err := touchWallet(ctx, s); err != nil {
+			return true, errors.Wrap(err, "failed to set wallet generation")
+		}
+		return true, discardStorageUpdate(ctx, s)
+	}
+	return true, rollbackStorageUpdate(ctx, s)
+}
+
+// rollbackStorageUpdate restores the values recorded in the write-ahead log and discards it,
+// except highest attestations and proposals, which are only restored when it raises them.
+// The wallet generation isn't restored: a sign request may have cached the wallet of the update under it,
+// a new generation is set instead.
+func rollbackStorageUpdate(ctx context.Context, s logical.Storage) error {
+	keys, err := s.List(ctx, WALEntryBase)
+	if err != nil {
+		return errors.Wrap(err, "failed to list write-ahead log")
+	}
+	for _, key := range keys {
+		entry, err := s.Get(ctx, WALEntryBase+key)
+		if err != nil {
+			return errors.Wrap(err, "failed to get write-ahead log entry")
+		}
+		if entry == nil {
+			continue
+		}
+		var walEntry WALEntry
+		if err := json.Unmarshal(entry.Value, &walEntry); err != nil {
+			return errors.Wrap(err, "failed to unmarshal write-ahead log entry")
+		}
+		if walEntry.Key == WalletGenerationPath {
+			continue
+		}
+
+		// Sign requests don't take the storage lock, they may have raised a highest attestation or proposal
+		// during the update: these are never lowered.
+		current, err := s.Get(ctx, walEntry.Key)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get %s", walEntry.Key)
+		}
+		if current != nil {
+			var value []byte
+			if walEntry.Exists {
+				value = walEntry.Value
+			}
+			lowered, err := slashingRecordLowered(walEntry.Key, current.Value, value)
+			if err != nil {
+				return errors.Wrapf(err, "failed to restore %s", walEntry.Key)
+			}
+			if lowered {
+				continue
+			}
+		}
+
+		if walEntry.Exists {
+			err = s.Put(ctx, &logical.StorageEntry{Key: walEntry.Key, Value: walEntry.Value})
+		} else {
+			err = s.Delete(ctx, walEntry.Key)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to restore %s", walEntry.Key)
+		}
+	}
+	if err := touchWallet(ctx, s); err != nil {
+		return errors.Wrap(err, "failed to set wallet generation")
+	}
+	return discardStorageUpdate(ctx, s)
+}
+
+// discardStorageUpdate deletes the write-ahead log, the commit last.
+func discardStorageUpdate(ctx context.Context, s logical.Storage) error {
+	keys, err := s.List(ctx, WALEntryBase)
+	if err != nil {
+		return errors.Wrap(err, "failed to list write-ahead log")
+	}
+	for _, key := range keys {
+		if err := s.Delete(ctx, WALEntryBase+key); err != nil {
+			return errors.Wrap(err, "failed to delete write-ahead log entry")
+		}
+	}
+	if err := s.Delete(ctx, WALCommittedPath); err != nil {
+		return errors.Wrap(err, "failed to delete storage update commit")
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package store_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/bloxapp/eth2-key-manager/core"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bloxapp/key-vault/backend/store"
+)
+
+// failingStorage fails the put of the failAt-th storage key, other than the write-ahead log ones.
+// When crashed, every operation fails from then on.
+type failingStorage struct {
+	logical.Storage
+	puts           int
+	failAt         int
+	crashed        bool
+	failWALDeletes bool
+}
+
+func (s *failingStorage) down() bool {
+	return s.crashed && s.failAt > 0 && s.puts >= s.failAt
+}
+
+func (s *failingStorage) Get(ctx context.Context, key string) (*logical.StorageEntry, error) {
+	if s.down() {
+		return nil, errors.New("crashed")
+	}
+	return s.Storage.Get(ctx, key)
+}
+
+func (s *failingStorage) Put(ctx context.Context, entry *logical.StorageEntry) error {
+	if s.down() {
+		return errors.New("crashed")
+	}
+	if !strings.HasPrefix(entry.Key, store.WALBase) {
+		s.puts++
+		if s.puts == s.failAt {
+			return errors.New("put failed")
+		}
+	}
+	return s.Storage.Put(ctx, entry)
+}
+
+func (s *failingStorage) Delete(ctx context.Context, key string) error {
+	if s.down() || (s.failWALDeletes && strings.HasPrefix(key, store.WALBase)) {
+		return errors.New("delete failed")
+	}
+	return s.Storage.Delete(ctx, key)
+}
+
+func storageSnapshot(t *testing.T, s logical.Storage) map[string]string {
+	keys, err := logical.CollectKeys(context.Background(), s)
+	require.NoError(t, err)
+	ret := make(map[string]string)
+	for _, key := range keys {
+		entry, err := s.Get(context.Background(), key)
+		require.NoError(t, err)
+		ret[key] = string(entry.Value)
+	}
+	return ret
+}
+
+// requireRolledBack checks that the storage is back to the given snapshot, with a new wallet generation.
+func requireRolledBack(t *testing.T, before map[string]string, s logical.Storage) {
+	after := storageSnapshot(t, s)
+	require.NotEmpty(t, after[store.WalletGenerationPath])
+	require.NotEqual(t, before[store.WalletGenerationPath], after[store.WalletGenerationPath])
+	delete(after, store.WalletGenerationPath)
+	expected := make(map[string]string)
+	for key, value := range before {
+		if key != store.WalletGenerationPath {
+			expected[key] = value
+		}
+	}
+	require.Equal(t, expected, after)
+}
+
+func TestUpdateTransactionally(t *testing.T) {
+	setup := func(t *testing.T) (*logical.InmemStorage, map[string]string) {
+		oldInMemStore, _, _ := baseKeyVault(
+			_byteArray("0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1fff"),
+			t,
+		)
+		hashiStorage := &logical.InmemStorage{}
+		_, err := store.FromInMemoryStore(context.Background(), oldInMemStore, hashiStorage)
+		require.NoError(t, err)
+		return hashiStorage, storageSnapshot(t, hashiStorage)
+	}
+	update := func(s logical.Storage) error {
+		newInMemStore, _, _ := baseKeyVault(
+			_byteArray("0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1fdf"),
+			t,
+		)
+		_, _, err := store.FromInMemoryStoreV2(context.Background(), newInMemStore, s, true)
+		return err
+	}
+
+	t.Run("update is applied", func(t *testing.T) {
+		hashiStorage, _ := setup(t)
+		require.NoError(t, store.UpdateTransactionally(context.Background(), hashiStorage, update))
+
+		accounts, err := store.NewHashicorpVaultStore(context.Background(), hashiStorage, core.PraterNetwork).ListAccounts()
+		require.NoError(t, err)
+		require.Len(t, accounts, 2)
+		keys, err := hashiStorage.List(context.Background(), store.WALBase)
+		require.NoError(t, err)
+		require.Empty(t, keys)
+	})
+
+	t.Run("failed update is rolled back", func(t *testing.T) {
+		hashiStorage, before := setup(t)
+		s := &failingStorage{Storage: hashiStorage, failAt: 5}
+
+		err := store.UpdateTransactionally(context.Background(), s, update)
+		require.EqualError(t, err, "failed to save account: put failed")
+		requireRolledBack(t, before, hashiStorage)
+	})
+
+	t.Run("interrupted update is rolled back", func(t *testing.T) {
+		hashiStorage, before := setup(t)
+		s := &failingStorage{Storage: hashiStorage, failAt: 5, crashed: true}
+
+		require.Error(t, store.UpdateTransactionally(context.Background(), s, update))
+		require.NotEqual(t, before, storageSnapshot(t, hashiStorage))
+
+		recovered, err := store.RecoverStorageUpdate(context.Background(), hashiStorage)
+		require.NoError(t, err)
+		require.True(t, recovered)
+		requireRolledBack(t, before, hashiStorage)
+
+		recovered, err = store.RecoverStorageUpdate(context.Background(), hashiStorage)
+		require.NoError(t, err)
+		require.False(t, recovered)
+	})
+
+	t.Run("interrupted committed update is rolled forward", func(t *testing.T) {
+		hashiStorage, _ := setup(t)
+		s := &failingStorage{Storage: hashiStorage, failWALDeletes: true}
+
+		require.NoError(t, store.UpdateTransactionally(context.Background(), s, update))
+		keys, err := hashiStorage.List(context.Background(), store.WALBase)
+		require.NoError(t, err)
+		require.NotEmpty(t, keys)
+
+		recovered, err := store.RecoverStorageUpdate(context.Background(), hashiStorage)
+		require.NoError(t, err)
+		require.True(t, recovered)
+		keys, err = hashiStorage.List(context.Background(), store.WALBase)
+		require.NoError(t, err)
+		require.Empty(t, keys)
+		accounts, err := store.NewHashicorpVaultStore(context.Background(), hashiStorage, core.PraterNetwork).ListAccounts()
+		require.NoError(t, err)
+		require.Len(t, accounts, 2)
+	})
+
+	t.Run("slashing protection raised by a sign is not lowered", func(t *testing.T) {
+		hashiStorage, _ := setup(t)
+		pubKey := _byteArray("95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcd")
+		otherPubKey := _byteArray("a3862121db5914d7272b0b705e6e3c5336b79e316735661873566245207329c30f9a33d4fb5f5857fc6fd0a368186972")
+		stored := store.NewHashicorpVaultStore(context.Background(), hashiStorage, core.PraterNetwork)
+		require.NoError(t, stored.SaveHighestAttestation(pubKey, &phase0.AttestationData{
+			Source: &phase0.Checkpoint{Epoch: 1},
+			Target: &phase0.Checkpoint{Epoch: 2},
+		}))
+		require.NoError(t, stored.SaveHighestProposal(pubKey, 10))
+
+		err := store.UpdateTransactionally(context.Background(), hashiStorage, func(s logical.Storage) error {
+			updating := store.NewHashicorpVaultStore(context.Background(), s, core.PraterNetwork)
+			require.NoError(t, updating.SaveHighestAttestation(pubKey, &phase0.AttestationData{
+				Source: &phase0.Checkpoint{Epoch: 3},
+				Target: &phase0.Checkpoint{Epoch: 4},
+			}))
+			require.NoError(t, updating.SaveHighestProposal(pubKey, 20))
+			require.NoError(t, updating.SaveHighestProposal(otherPubKey, 20))
+
+			// Signs meanwhile, without the journal
+			require.NoError(t, stored.SaveHighestAttestation(pubKey, &phase0.AttestationData{
+				Source: &phase0.Checkpoint{Epoch: 4},
+				Target: &phase0.Checkpoint{Epoch: 5},
+			}))
+			require.NoError(t, stored.SaveHighestProposal(otherPubKey, 30))
+			return errors.New("update failed")
+		})
+		require.EqualError(t, err, "update failed")
+
+		// Highest attestations and proposals are never lowered, the ones raised by the update are kept as well
+		highestAtt, found, err := stored.RetrieveHighestAttestation(pubKey)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.EqualValues(t, 4, highestAtt.Source.Epoch)
+		require.EqualValues(t, 5, highestAtt.Target.Epoch)
+		highestProposal, found, err := stored.RetrieveHighestProposal(pubKey)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.EqualValues(t, 20, highestProposal)
+		highestProposal, found, err = stored.RetrieveHighestProposal(otherPubKey)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.EqualValues(t, 30, highestProposal)
+	})
+}
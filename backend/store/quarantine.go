@@ -0,0 +1,75 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+)
+
+// Paths
+const (
+	QuarantinePath = "quarantine/%s"
+)
+
+// Quarantine is the signing quarantine of an imported account.
+// Attestations and blocks aren't signed before ActivationEpoch, unless the quarantine was lifted.
+type Quarantine struct {
+	ActivationEpoch phase0.Epoch `json:"activation_epoch"`
+	QuarantinedAt   time.Time    `json:"quarantined_at"`
+	LiftedBy        string       `json:"lifted_by,omitempty"`
+	LiftedAt        *time.Time   `json:"lifted_at,omitempty"`
+}
+
+// Active returns whether the quarantine is active at the given epoch.
+func (q *Quarantine) Active(epoch phase0.Epoch) bool {
+	return q.LiftedAt == nil && epoch < q.ActivationEpoch
+}
+
+// SaveQuarantine saves the quarantine of the given public key.
+func (store *HashicorpVaultStore) SaveQuarantine(pubKey []byte, quarantine *Quarantine) error {
+	if pubKey == nil {
+		return errors.New("pubKey must not be nil")
+	}
+
+	if quarantine == nil {
+		return errors.New("quarantine could not be nil")
+	}
+
+	data, err := json.Marshal(quarantine)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal quarantine")
+	}
+
+	return store.storage.Put(store.ctx, &logical.StorageEntry{
+		Key:      fmt.Sprintf(QuarantinePath, store.identifierFromKey(pubKey)),
+		Value:    data,
+		SealWrap: false,
+	})
+}
+
+// RetrieveQuarantine retrieves the quarantine of the given public key.
+func (store *HashicorpVaultStore) RetrieveQuarantine(pubKey []byte) (*Quarantine, bool, error) {
+	if pubKey == nil {
+		return nil, false, errors.New("public key could not be nil")
+	}
+
+	entry, err := store.storage.Get(store.ctx, fmt.Sprintf(QuarantinePath, store.identifierFromKey(pubKey)))
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Return nothing if there is no record
+	if entry == nil {
+		return nil, false, nil
+	}
+
+	quarantine := &Quarantine{}
+	if err := json.Unmarshal(entry.Value, quarantine); err != nil {
+		return nil, false, errors.Wrap(err, "failed to unmarshal quarantine")
+	}
+	return quarantine, true, nil
+}
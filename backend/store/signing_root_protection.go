@@ -0,0 +1,110 @@
+package store
+
+import (
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/bloxapp/eth2-key-manager/core"
+	"github.com/pkg/errors"
+)
+
+// SigningRootProtection wraps the slashing protector of a single sign request.
+// It saves the signing root of the request along with the highest proposal and attestation,
+// and allows re-signing the highest proposal or attestation when the signing root matches the saved one,
+// e.g. when a validator client retries a request after a timeout.
+type SigningRootProtection struct {
+	core.SlashingProtector
+	store       *HashicorpVaultStore
+	signingRoot phase0.Root
+	refused     bool
+}
+
+// NewSigningRootProtection is the constructor of SigningRootProtection for a sign request of the given signing root.
+func NewSigningRootProtection(protector core.SlashingProtector, store *HashicorpVaultStore, signingRoot phase0.Root) *SigningRootProtection {
+	return &SigningRootProtection{
+		SlashingProtector: protector,
+		store:             store,
+		signingRoot:       signingRoot,
+	}
+}
+
+// IsSlashableAttestation allows re-signing the highest attestation with the same signing root.
+func (protector *SigningRootProtection) IsSlashableAttestation(pubKey []byte, attestation *phase0.AttestationData) (*core.AttestationSlashStatus, error) {
+	status, err := protector.SlashingProtector.IsSlashableAttestation(pubKey, attestation)
+	if err != nil || status == nil {
+		return status, err
+	}
+
+	highest, signingRoot, found, err := protector.store.RetrieveHighestAttestationWithSigningRoot(pubKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not retrieve highest attestation")
+	}
+	if found && signingRoot != nil && *signingRoot == protector.signingRoot &&
+		highest.Source.Epoch == attestation.Source.Epoch && highest.Target.Epoch == attestation.Target.Epoch {
+		return nil, nil
+	}
+	protector.refused = true
+	return status, nil
+}
+
+// UpdateHighestAttestation saves the signing root along with the highest attestation, when it is the given one.
+func (protector *SigningRootProtection) UpdateHighestAttestation(pubKey []byte, attestation *phase0.AttestationData) error {
+	if err := protector.SlashingProtector.UpdateHighestAttestation(pubKey, attestation); err != nil {
+		return err
+	}
+
+	highest, found, err := protector.store.RetrieveHighestAttestation(pubKey)
+	if err != nil {
+		return errors.Wrap(err, "could not retrieve highest attestation")
+	}
+	if !found || highest.Source.Epoch != attestation.Source.Epoch || highest.Target.Epoch != attestation.Target.Epoch {
+		return nil
+	}
+	if err := protector.store.SaveHighestAttestationWithSigningRoot(pubKey, highest, protector.signingRoot); err != nil {
+		return errors.Wrap(err, "could not save highest attestation")
+	}
+	return nil
+}
+
+// IsSlashableProposal allows re-signing the highest proposal with the same signing root.
+func (protector *SigningRootProtection) IsSlashableProposal(pubKey []byte, slot phase0.Slot) (*core.ProposalSlashStatus, error) {
+	status, err := protector.SlashingProtector.IsSlashableProposal(pubKey, slot)
+	if err != nil || status.Status == core.ValidProposal {
+		return status, err
+	}
+
+	highest, signingRoot, found, err := protector.store.RetrieveHighestProposalWithSigningRoot(pubKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not retrieve highest proposal")
+	}
+	if found && signingRoot != nil && *signingRoot == protector.signingRoot && highest == slot {
+		return &core.ProposalSlashStatus{
+			Slot:   slot,
+			Status: core.ValidProposal,
+		}, nil
+	}
+	protector.refused = true
+	return status, nil
+}
+
+// Refused returns whether a proposal or an attestation was found slashable.
+func (protector *SigningRootProtection) Refused() bool {
+	return protector.refused
+}
+
+// UpdateHighestProposal saves the signing root along with the highest proposal, when it is the given one.
+func (protector *SigningRootProtection) UpdateHighestProposal(pubKey []byte, slot phase0.Slot) error {
+	if err := protector.SlashingProtector.UpdateHighestProposal(pubKey, slot); err != nil {
+		return err
+	}
+
+	highest, found, err := protector.store.RetrieveHighestProposal(pubKey)
+	if err != nil {
+		return errors.Wrap(err, "could not retrieve highest proposal")
+	}
+	if !found || highest != slot {
+		return nil
+	}
+	if err := protector.store.SaveHighestProposalWithSigningRoot(pubKey, slot, protector.signingRoot); err != nil {
+		return errors.Wrap(err, "could not save highest proposal")
+	}
+	return nil
+}
@@ -1,23 +1,40 @@
 package store
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
+	"strings"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	ssz "github.com/ferranbt/fastssz"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/pkg/errors"
+
+	"github.com/bloxapp/key-vault/utils/encoder"
 )
 
 // Paths
 const (
 	WalletHighestAttestationPath = "highestAttestations/"
 	WalletHighestProposalsBase   = "proposals/%s" // account/proposal
+
+	// The signing roots are saved apart from the records, which keep the format of previous versions.
+	WalletHighestAttestationSigningRootPath = "highestAttestationSigningRoots/%s"
+	WalletHighestProposalSigningRootPath    = "proposalSigningRoots/%s"
 )
 
 // SaveHighestAttestation saves highest attestation
 func (store *HashicorpVaultStore) SaveHighestAttestation(pubKey []byte, attestation *phase0.AttestationData) error {
+	return store.saveHighestAttestation(pubKey, attestation, nil)
+}
+
+// SaveHighestAttestationWithSigningRoot saves highest attestation along with its signing root
+func (store *HashicorpVaultStore) SaveHighestAttestationWithSigningRoot(pubKey []byte, attestation *phase0.AttestationData, signingRoot phase0.Root) error {
+	return store.saveHighestAttestation(pubKey, attestation, &signingRoot)
+}
+
+func (store *HashicorpVaultStore) saveHighestAttestation(pubKey []byte, attestation *phase0.AttestationData, signingRoot *phase0.Root) error {
 	if pubKey == nil {
 		return errors.New("pubKey must not be nil")
 	}
@@ -32,40 +49,62 @@ func (store *HashicorpVaultStore) SaveHighestAttestation(pubKey []byte, attestat
 		return errors.Wrap(err, "failed to marshal attestation request")
 	}
 
-	return store.storage.Put(store.ctx, &logical.StorageEntry{
+	if err := store.storage.Put(store.ctx, &logical.StorageEntry{
 		Key:      path,
 		Value:    data,
 		SealWrap: false,
-	})
+	}); err != nil {
+		return err
+	}
+	return store.saveSigningRoot(fmt.Sprintf(WalletHighestAttestationSigningRootPath, store.identifierFromKey(pubKey)), data, signingRoot)
 }
 
 // RetrieveHighestAttestation retrieves highest attestation
 func (store *HashicorpVaultStore) RetrieveHighestAttestation(pubKey []byte) (*phase0.AttestationData, bool, error) {
+	attestation, _, found, err := store.RetrieveHighestAttestationWithSigningRoot(pubKey)
+	return attestation, found, err
+}
+
+// RetrieveHighestAttestationWithSigningRoot retrieves highest attestation and its signing root,
+// the signing root is nil when the attestation was saved without it.
+func (store *HashicorpVaultStore) RetrieveHighestAttestationWithSigningRoot(pubKey []byte) (*phase0.AttestationData, *phase0.Root, bool, error) {
 	if pubKey == nil {
-		return nil, false, errors.New("public key could not be nil")
+		return nil, nil, false, errors.New("public key could not be nil")
 	}
 
 	path := fmt.Sprintf(WalletHighestAttestationPath+"%s", store.identifierFromKey(pubKey))
 	entry, err := store.storage.Get(store.ctx, path)
 	if err != nil {
-		return nil, false, err
+		return nil, nil, false, err
 	}
 
 	// Return nothing if there is no record
 	if entry == nil {
-		return nil, false, nil
+		return nil, nil, false, nil
 	}
 
-	ret := &phase0.AttestationData{}
-	if err := store.encoder.Decode(entry.Value, ret); err != nil {
-		return nil, false, errors.Wrapf(err, "failed to unmarshal attestation (size %d) - (hex: %s)", len(entry.Value), hex.EncodeToString(entry.Value))
+	ret, err := decodeHighestAttestation(entry.Value)
+	if err != nil {
+		return nil, nil, false, err
 	}
-
-	return ret, true, nil
+	signingRoot, err := store.retrieveSigningRoot(fmt.Sprintf(WalletHighestAttestationSigningRootPath, store.identifierFromKey(pubKey)), entry.Value)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return ret, signingRoot, true, nil
 }
 
 // SaveHighestProposal implements Storage interface.
 func (store *HashicorpVaultStore) SaveHighestProposal(pubKey []byte, slot phase0.Slot) error {
+	return store.saveHighestProposal(pubKey, slot, nil)
+}
+
+// SaveHighestProposalWithSigningRoot saves highest proposal along with its signing root
+func (store *HashicorpVaultStore) SaveHighestProposalWithSigningRoot(pubKey []byte, slot phase0.Slot, signingRoot phase0.Root) error {
+	return store.saveHighestProposal(pubKey, slot, &signingRoot)
+}
+
+func (store *HashicorpVaultStore) saveHighestProposal(pubKey []byte, slot phase0.Slot, signingRoot *phase0.Root) error {
 	if pubKey == nil {
 		return errors.New("pubKey must not be nil")
 	}
@@ -80,31 +119,128 @@ func (store *HashicorpVaultStore) SaveHighestProposal(pubKey []byte, slot phase0
 		return errors.Wrap(err, "failed to marshal proposal request")
 	}
 
-	return store.storage.Put(store.ctx, &logical.StorageEntry{
+	if err := store.storage.Put(store.ctx, &logical.StorageEntry{
 		Key:      path,
 		Value:    data,
 		SealWrap: false,
-	})
+	}); err != nil {
+		return err
+	}
+	return store.saveSigningRoot(fmt.Sprintf(WalletHighestProposalSigningRootPath, store.identifierFromKey(pubKey)), data, signingRoot)
 }
 
 // RetrieveHighestProposal implements Storage interface.
 func (store *HashicorpVaultStore) RetrieveHighestProposal(pubKey []byte) (phase0.Slot, bool, error) {
+	slot, _, found, err := store.RetrieveHighestProposalWithSigningRoot(pubKey)
+	return slot, found, err
+}
+
+// RetrieveHighestProposalWithSigningRoot retrieves highest proposal and its signing root,
+// the signing root is nil when the proposal was saved without it.
+func (store *HashicorpVaultStore) RetrieveHighestProposalWithSigningRoot(pubKey []byte) (phase0.Slot, *phase0.Root, bool, error) {
 	if pubKey == nil {
-		return 0, false, errors.New("public key could not be nil")
+		return 0, nil, false, errors.New("public key could not be nil")
 	}
 
 	path := fmt.Sprintf(WalletHighestProposalsBase, store.identifierFromKey(pubKey))
 	entry, err := store.storage.Get(store.ctx, path)
 	if err != nil {
-		return 0, false, err
+		return 0, nil, false, err
 	}
 
 	// Return nothing if there is no record
 	if entry == nil {
-		return 0, false, nil
+		return 0, nil, false, nil
+	}
+
+	slot, err := decodeHighestProposal(entry.Value)
+	if err != nil {
+		return 0, nil, false, err
+	}
+	signingRoot, err := store.retrieveSigningRoot(fmt.Sprintf(WalletHighestProposalSigningRootPath, store.identifierFromKey(pubKey)), entry.Value)
+	if err != nil {
+		return 0, nil, false, err
+	}
+	return slot, signingRoot, true, nil
+}
+
+// saveSigningRoot saves the signing root of the given record, along with the record so it only applies to it:
+// the record may be saved again by a previous version, which doesn't know signing roots.
+// The signing root is deleted when there is none.
+func (store *HashicorpVaultStore) saveSigningRoot(path string, record []byte, signingRoot *phase0.Root) error {
+	if signingRoot == nil {
+		return store.storage.Delete(store.ctx, path)
+	}
+	return store.storage.Put(store.ctx, &logical.StorageEntry{
+		Key:      path,
+		Value:    append(append([]byte{}, record...), signingRoot[:]...),
+		SealWrap: false,
+	})
+}
+
+// retrieveSigningRoot retrieves the signing root of the given record, it is nil when it was saved for another record.
+func (store *HashicorpVaultStore) retrieveSigningRoot(path string, record []byte) (*phase0.Root, error) {
+	entry, err := store.storage.Get(store.ctx, path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get signing root")
+	}
+	if entry == nil || len(entry.Value) != len(record)+phase0.RootLength || !bytes.Equal(entry.Value[:len(record)], record) {
+		return nil, nil
 	}
+	var signingRoot phase0.Root
+	copy(signingRoot[:], entry.Value[len(record):])
+	return &signingRoot, nil
+}
 
-	return phase0.Slot(ssz.UnmarshallUint64(entry.Value)), true, nil
+// decodeHighestAttestation decodes a highest attestation record.
+func decodeHighestAttestation(record []byte) (*phase0.AttestationData, error) {
+	ret := &phase0.AttestationData{}
+	if err := encoder.New().Decode(record, ret); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal attestation (size %d) - (hex: %s)", len(record), hex.EncodeToString(record))
+	}
+	return ret, nil
+}
+
+// decodeHighestProposal decodes a highest proposal record.
+func decodeHighestProposal(record []byte) (phase0.Slot, error) {
+	if len(record) != 8 {
+		return 0, errors.Errorf("failed to unmarshal proposal (size %d)", len(record))
+	}
+	return phase0.Slot(ssz.UnmarshallUint64(record)), nil
+}
+
+// slashingRecordLowered returns whether replacing the given current value of a storage key by the given value,
+// or deleting it when the value is nil, would lower a highest attestation or proposal.
+func slashingRecordLowered(key string, current []byte, value []byte) (bool, error) {
+	switch {
+	case strings.HasPrefix(key, WalletHighestAttestationPath):
+		if value == nil {
+			return true, nil
+		}
+		currentAtt, err := decodeHighestAttestation(current)
+		if err != nil {
+			return false, err
+		}
+		att, err := decodeHighestAttestation(value)
+		if err != nil {
+			return false, err
+		}
+		return att.Source.Epoch < currentAtt.Source.Epoch || att.Target.Epoch < currentAtt.Target.Epoch, nil
+	case strings.HasPrefix(key, fmt.Sprintf(WalletHighestProposalsBase, "")):
+		if value == nil {
+			return true, nil
+		}
+		currentSlot, err := decodeHighestProposal(current)
+		if err != nil {
+			return false, err
+		}
+		slot, err := decodeHighestProposal(value)
+		if err != nil {
+			return false, err
+		}
+		return slot < currentSlot, nil
+	}
+	return false, nil
 }
 
 func (store *HashicorpVaultStore) identifierFromKey(key []byte) string {
@@ -2,11 +2,13 @@ package store_test
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"testing"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/bloxapp/eth2-key-manager/core"
+	ssz "github.com/ferranbt/fastssz"
 	"github.com/google/uuid"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/herumi/bls-eth-go-binary/bls"
@@ -218,3 +220,112 @@ func TestSavingHighestAttestation(t *testing.T) {
 		})
 	}
 }
+
+func TestSavingSigningRoots(t *testing.T) {
+	storage := getSlashingStorage().(*store.HashicorpVaultStore)
+	pubKey := []byte{1, 2, 3}
+	att := &phase0.AttestationData{
+		Source: &phase0.Checkpoint{Epoch: 1},
+		Target: &phase0.Checkpoint{Epoch: 2},
+	}
+	signingRoot := phase0.Root{1, 2, 3}
+
+	t.Run("attestation with signing root", func(t *testing.T) {
+		require.NoError(t, storage.SaveHighestAttestationWithSigningRoot(pubKey, att, signingRoot))
+
+		highest, root, found, err := storage.RetrieveHighestAttestationWithSigningRoot(pubKey)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, att, highest)
+		require.Equal(t, &signingRoot, root)
+
+		highest, found, err = storage.RetrieveHighestAttestation(pubKey)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, att, highest)
+	})
+
+	t.Run("attestation without signing root", func(t *testing.T) {
+		require.NoError(t, storage.SaveHighestAttestation(pubKey, att))
+
+		highest, root, found, err := storage.RetrieveHighestAttestationWithSigningRoot(pubKey)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, att, highest)
+		require.Nil(t, root)
+	})
+
+	t.Run("proposal with signing root", func(t *testing.T) {
+		require.NoError(t, storage.SaveHighestProposalWithSigningRoot(pubKey, 100, signingRoot))
+
+		slot, root, found, err := storage.RetrieveHighestProposalWithSigningRoot(pubKey)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.EqualValues(t, 100, slot)
+		require.Equal(t, &signingRoot, root)
+
+		slot, found, err = storage.RetrieveHighestProposal(pubKey)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.EqualValues(t, 100, slot)
+	})
+
+	t.Run("proposal without signing root", func(t *testing.T) {
+		require.NoError(t, storage.SaveHighestProposal(pubKey, 101))
+
+		slot, root, found, err := storage.RetrieveHighestProposalWithSigningRoot(pubKey)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.EqualValues(t, 101, slot)
+		require.Nil(t, root)
+	})
+}
+
+func TestSigningRootsCompatibility(t *testing.T) {
+	hashiStorage := &logical.InmemStorage{}
+	storage := store.NewHashicorpVaultStore(context.Background(), hashiStorage, core.PraterNetwork)
+	pubKey := []byte{1, 2, 3}
+	att := &phase0.AttestationData{
+		Source: &phase0.Checkpoint{Epoch: 1},
+		Target: &phase0.Checkpoint{Epoch: 2},
+	}
+	signingRoot := phase0.Root{1, 2, 3}
+	attPath := store.WalletHighestAttestationPath + "010203"
+	proposalPath := fmt.Sprintf(store.WalletHighestProposalsBase, "010203")
+
+	t.Run("records keep the format of previous versions", func(t *testing.T) {
+		require.NoError(t, storage.SaveHighestAttestationWithSigningRoot(pubKey, att, signingRoot))
+		require.NoError(t, storage.SaveHighestProposalWithSigningRoot(pubKey, 100, signingRoot))
+
+		entry, err := hashiStorage.Get(context.Background(), attPath)
+		require.NoError(t, err)
+		require.Len(t, entry.Value, att.SizeSSZ())
+		entry, err = hashiStorage.Get(context.Background(), proposalPath)
+		require.NoError(t, err)
+		require.Len(t, entry.Value, 8)
+	})
+
+	t.Run("signing roots don't apply to records saved by previous versions", func(t *testing.T) {
+		require.NoError(t, storage.SaveHighestAttestationWithSigningRoot(pubKey, att, signingRoot))
+		require.NoError(t, storage.SaveHighestProposalWithSigningRoot(pubKey, 100, signingRoot))
+
+		byts, err := (&phase0.AttestationData{
+			Source: &phase0.Checkpoint{Epoch: 2},
+			Target: &phase0.Checkpoint{Epoch: 3},
+		}).MarshalSSZ()
+		require.NoError(t, err)
+		require.NoError(t, hashiStorage.Put(context.Background(), &logical.StorageEntry{Key: attPath, Value: byts}))
+		require.NoError(t, hashiStorage.Put(context.Background(), &logical.StorageEntry{Key: proposalPath, Value: ssz.MarshalUint64(nil, 101)}))
+
+		highest, root, found, err := storage.RetrieveHighestAttestationWithSigningRoot(pubKey)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.EqualValues(t, 3, highest.Target.Epoch)
+		require.Nil(t, root)
+		slot, root, found, err := storage.RetrieveHighestProposalWithSigningRoot(pubKey)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.EqualValues(t, 101, slot)
+		require.Nil(t, root)
+	})
+}
@@ -0,0 +1,131 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bloxapp/eth2-key-manager/core"
+	"github.com/bloxapp/eth2-key-manager/stores/inmemory"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+)
+
+// Results of updating the storage with an account
+const (
+	// AccountAdded is the result of an account which isn't stored yet.
+	AccountAdded = "added"
+	// AccountSkipped is the result of an account which is already stored, with the same name and index.
+	AccountSkipped = "skipped"
+	// AccountConflict is the result of an account which conflicts with a stored one, it isn't written.
+	AccountConflict = "conflict"
+	// AccountOverridden is the result of a conflicting account which is written anyway.
+	AccountOverridden = "overridden"
+)
+
+// ErrConflictingAccounts is returned when updating the storage with conflicting accounts without overriding them.
+var ErrConflictingAccounts = errors.New("conflicting accounts")
+
+// AccountUpdate is the result of updating the storage with an account.
+type AccountUpdate struct {
+	PublicKey []byte
+	Name      string
+	Index     int
+	Result    string
+	Conflict  string
+
+	account core.ValidatorAccount
+	// replaced are the stored accounts the account conflicts with, deleted when it is overridden.
+	replaced []core.ValidatorAccount
+}
+
+// PlanStorageUpdate returns the result of updating the existing storage with each account of the new storage,
+// without writing anything.
+// Accounts conflict with a stored one when they have the same name or index but a different public key,
+// or the same public key but a different name or index.
+func PlanStorageUpdate(ctx context.Context, newStorage *inmemory.InMemStore, existingStorage logical.Storage) ([]*AccountUpdate, error) {
+	newStorageWallet, err := newStorage.OpenWallet()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open newStorage wallet")
+	}
+
+	byPubKey := make(map[string]core.ValidatorAccount)
+	byName := make(map[string]core.ValidatorAccount)
+	byIndex := make(map[int]core.ValidatorAccount)
+	entry, err := existingStorage.Get(ctx, WalletDataPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get wallet data")
+	}
+	if entry != nil {
+		existingAccounts, err := NewHashicorpVaultStore(ctx, existingStorage, newStorage.Network()).ListAccounts()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list existing accounts")
+		}
+		for _, a := range existingAccounts {
+			byPubKey[hex.EncodeToString(a.ValidatorPublicKey())] = a
+			byName[a.Name()] = a
+			byIndex[accountIndex(a)] = a
+		}
+	}
+
+	var updates []*AccountUpdate
+	for _, newAccount := range newStorageWallet.Accounts() {
+		update := &AccountUpdate{
+			PublicKey: newAccount.ValidatorPublicKey(),
+			Name:      newAccount.Name(),
+			Index:     accountIndex(newAccount),
+			Result:    AccountAdded,
+			account:   newAccount,
+		}
+		if existing, ok := byPubKey[hex.EncodeToString(update.PublicKey)]; ok {
+			update.Result = AccountSkipped
+			if existing.Name() != update.Name || accountIndex(existing) != update.Index {
+				update.Result = AccountConflict
+				update.Conflict = fmt.Sprintf("public key is already stored as %s at index %d", existing.Name(), accountIndex(existing))
+				update.replaced = append(update.replaced, existing)
+			}
+		}
+		if existing, ok := byName[update.Name]; ok && !bytes.Equal(existing.ValidatorPublicKey(), update.PublicKey) {
+			if update.Result != AccountConflict {
+				update.Result = AccountConflict
+				update.Conflict = fmt.Sprintf("name is already stored with public key %#x", existing.ValidatorPublicKey())
+			}
+			update.replaced = append(update.replaced, existing)
+		}
+		if existing, ok := byIndex[update.Index]; ok && !bytes.Equal(existing.ValidatorPublicKey(), update.PublicKey) {
+			if update.Result != AccountConflict {
+				update.Result = AccountConflict
+				update.Conflict = fmt.Sprintf("index is already stored with public key %#x", existing.ValidatorPublicKey())
+			}
+			update.replaced = append(update.replaced, existing)
+		}
+		updates = append(updates, update)
+	}
+	return updates, nil
+}
+
+// conflictsError returns an error describing the conflicting updates, or nil if there is none.
+func conflictsError(updates []*AccountUpdate) error {
+	var conflicts []string
+	for _, update := range updates {
+		if update.Result == AccountConflict {
+			conflicts = append(conflicts, fmt.Sprintf("%#x: %s", update.PublicKey, update.Conflict))
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	return errors.Wrapf(ErrConflictingAccounts, "refused to update storage (%s)", strings.Join(conflicts, "; "))
+}
+
+// accountIndex returns the derivation index of the given account, from its base path.
+func accountIndex(account core.ValidatorAccount) int {
+	index, err := strconv.Atoi(strings.TrimPrefix(account.BasePath(), "/"))
+	if err != nil {
+		return -1
+	}
+	return index
+}
@@ -0,0 +1,110 @@
+package store_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bloxapp/eth2-key-manager/stores/inmemory"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bloxapp/key-vault/backend/store"
+)
+
+func TestStorageUpdateSameKeyDifferentName(t *testing.T) {
+	inMemStore, _, inMemAccounts := baseKeyVault(
+		_byteArray("0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1fff"),
+		t,
+	)
+	hashiStorage := &logical.InmemStorage{}
+	_, err := store.FromInMemoryStore(context.Background(), inMemStore, hashiStorage)
+	require.NoError(t, err)
+
+	// The same accounts under other names
+	byts, err := json.Marshal(inMemStore)
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(byts, &fields))
+	accountsByts, err := hex.DecodeString(fields["accounts"].(string))
+	require.NoError(t, err)
+	fields["accounts"] = hex.EncodeToString([]byte(strings.ReplaceAll(string(accountsByts), `"account-`, `"renamed-`)))
+	byts, err = json.Marshal(fields)
+	require.NoError(t, err)
+	var renamedStore *inmemory.InMemStore
+	require.NoError(t, json.Unmarshal(byts, &renamedStore))
+
+	updates, err := store.PlanStorageUpdate(context.Background(), renamedStore, hashiStorage)
+	require.NoError(t, err)
+	require.Len(t, updates, 2)
+	for _, update := range updates {
+		require.Equal(t, store.AccountConflict, update.Result)
+		require.Contains(t, update.Conflict, "public key is already stored as account-")
+	}
+
+	_, _, err = store.FromInMemoryStoreV2(context.Background(), renamedStore, hashiStorage, false)
+	require.ErrorIs(t, err, store.ErrConflictingAccounts)
+
+	// The stored accounts are replaced when overridden
+	hashi, updates, err := store.FromInMemoryStoreV2(context.Background(), renamedStore, hashiStorage, true)
+	require.NoError(t, err)
+	for _, update := range updates {
+		require.Equal(t, store.AccountOverridden, update.Result)
+	}
+	accounts, err := hashi.ListAccounts()
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	for _, a := range inMemAccounts {
+		wallet, err := hashi.OpenWallet()
+		require.NoError(t, err)
+		account, err := wallet.AccountByPublicKey(hex.EncodeToString(a.ValidatorPublicKey()))
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(account.Name(), "renamed-"))
+	}
+}
+
+func TestStorageUpdateSameIndexDifferentKey(t *testing.T) {
+	oldInMemStore, _, oldAccounts := baseKeyVault(
+		_byteArray("0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1fff"),
+		t,
+	)
+	hashiStorage := &logical.InmemStorage{}
+	_, err := store.FromInMemoryStore(context.Background(), oldInMemStore, hashiStorage)
+	require.NoError(t, err)
+
+	// Other accounts with the same names and indexes
+	newInMemStore, _, newAccounts := baseKeyVault(
+		_byteArray("0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1fdf"),
+		t,
+	)
+	updates, err := store.PlanStorageUpdate(context.Background(), newInMemStore, hashiStorage)
+	require.NoError(t, err)
+	require.Len(t, updates, 2)
+	for _, update := range updates {
+		require.Equal(t, store.AccountConflict, update.Result)
+		require.Contains(t, update.Conflict, "name is already stored with public key")
+	}
+
+	// The stored accounts are replaced when overridden
+	hashi, updates, err := store.FromInMemoryStoreV2(context.Background(), newInMemStore, hashiStorage, true)
+	require.NoError(t, err)
+	for _, update := range updates {
+		require.Equal(t, store.AccountOverridden, update.Result)
+	}
+	accounts, err := hashi.ListAccounts()
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	wallet, err := hashi.OpenWallet()
+	require.NoError(t, err)
+	require.Len(t, wallet.Accounts(), 2)
+	for i := range newAccounts {
+		account, err := wallet.AccountByPublicKey(hex.EncodeToString(newAccounts[i].ValidatorPublicKey()))
+		require.NoError(t, err)
+		require.Equal(t, newAccounts[i].BasePath(), account.BasePath())
+
+		_, err = wallet.AccountByPublicKey(hex.EncodeToString(oldAccounts[i].ValidatorPublicKey()))
+		require.Error(t, err)
+	}
+}
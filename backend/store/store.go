@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 
@@ -20,9 +21,10 @@ import (
 
 // Paths
 const (
-	WalletDataPath = "wallet/data"
-	AccountBase    = "wallet/accounts/"
-	AccountPath    = AccountBase + "%s"
+	WalletDataPath       = "wallet/data"
+	WalletGenerationPath = "wallet/generation"
+	AccountBase          = "wallet/accounts/"
+	AccountPath          = AccountBase + "%s"
 )
 
 // HashicorpVaultStore implements store.Store interface using Vault.
@@ -46,13 +48,25 @@ func NewHashicorpVaultStore(ctx context.Context, storage logical.Storage, networ
 	}
 }
 
-// FromInMemoryStoreV2 updates HashicorpVaultStore with new accounts.
-func FromInMemoryStoreV2(ctx context.Context, newStorage *inmemory.InMemStore, existingStorage logical.Storage) (*HashicorpVaultStore, error) {
+// FromInMemoryStoreV2 updates HashicorpVaultStore with new accounts, and returns the result of the update of each account
+// (see PlanStorageUpdate).
+// Nothing is written if any account conflicts with a stored one, unless override is set: conflicting accounts are then
+// written, replacing the stored accounts of the same public key, name or index.
+func FromInMemoryStoreV2(ctx context.Context, newStorage *inmemory.InMemStore, existingStorage logical.Storage, override bool) (*HashicorpVaultStore, []*AccountUpdate, error) {
+	updates, err := PlanStorageUpdate(ctx, newStorage, existingStorage)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !override {
+		if err := conflictsError(updates); err != nil {
+			return nil, updates, err
+		}
+	}
 
 	// Open newStorage wallet
 	newStorageWallet, err := newStorage.OpenWallet()
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to open newStorage wallet")
+		return nil, nil, errors.Wrap(err, "failed to open newStorage wallet")
 	}
 
 	// Get existing hashicorp storage
@@ -66,60 +80,93 @@ func FromInMemoryStoreV2(ctx context.Context, newStorage *inmemory.InMemStore, e
 		// Save wallet in hashicorp store
 		err = hashicorpStore.SaveWallet(newStorageWallet)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to save wallet to hashicorp store")
+			return nil, nil, errors.Wrap(err, "failed to save wallet to hashicorp store")
 		}
 	}
 
 	// Open existing wallet
 	existingWallet, err := hashicorpStore.OpenWallet()
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to open existing wallet")
+		return nil, nil, errors.Wrap(err, "failed to open existing wallet")
 	}
 
 	// Save new accounts
-	for _, newAccount := range newStorageWallet.Accounts() {
-		// Check if account already exists and don't change it
-		// TODO: how to handle the same account name with index but different public keys?
-		existingAccount, _ := existingWallet.AccountByPublicKey(string(newAccount.ValidatorPublicKey()))
-		if existingAccount != nil {
+	for _, update := range updates {
+		switch update.Result {
+		case AccountSkipped:
 			continue
+		case AccountConflict:
+			update.Result = AccountOverridden
+			// The stored accounts of the same public key, name or index are replaced, their slashing protection is kept.
+			for _, replaced := range update.replaced {
+				pubKey := hex.EncodeToString(replaced.ValidatorPublicKey())
+				if _, err := existingWallet.AccountByPublicKey(pubKey); err != nil {
+					continue
+				}
+				if err := existingWallet.DeleteAccountByPublicKey(pubKey); err != nil {
+					return nil, nil, errors.Wrap(err, "failed to replace account")
+				}
+			}
 		}
 
 		// Add validator account in wallet
-		err := existingWallet.AddValidatorAccount(newAccount)
+		err := existingWallet.AddValidatorAccount(update.account)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to save account")
+			return nil, nil, errors.Wrap(err, "failed to save account")
 		}
 
 		// Save account in vault
-		if err := hashicorpStore.SaveAccount(newAccount); err != nil {
-			return nil, errors.Wrap(err, "failed to save account")
+		if err := hashicorpStore.SaveAccount(update.account); err != nil {
+			return nil, nil, errors.Wrap(err, "failed to save account")
+		}
+
+		// Save highest attestation and proposal, the ones kept from a deleted account are never lowered.
+		if err := importSlashingRecords(hashicorpStore, newStorage, update.PublicKey); err != nil {
+			return nil, nil, err
 		}
+	}
+
+	return hashicorpStore, updates, nil
+}
 
-		// Save highest attestation
-		highestAtt, found, err := newStorage.RetrieveHighestAttestation(newAccount.ValidatorPublicKey())
+// importSlashingRecords saves the highest attestation and proposal of the given public key from the given in-memory store,
+// unless they are lower than the stored ones.
+func importSlashingRecords(store *HashicorpVaultStore, newStorage *inmemory.InMemStore, pubKey []byte) error {
+	highestAtt, found, err := newStorage.RetrieveHighestAttestation(pubKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve highest attestation")
+	}
+	if found && highestAtt != nil {
+		existingAtt, found, err := store.RetrieveHighestAttestation(pubKey)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to retrieve highest attestation")
+			return errors.Wrap(err, "failed to retrieve existing highest attestation")
 		}
-		if found && highestAtt != nil {
-			if err := hashicorpStore.SaveHighestAttestation(newAccount.ValidatorPublicKey(), highestAtt); err != nil {
-				return nil, errors.Wrap(err, "failed to save highest attestation")
-			}
+		if found && existingAtt != nil {
+			err = store.RaiseAttestationWatermark(pubKey, highestAtt.Source.Epoch, highestAtt.Target.Epoch)
+		} else {
+			err = store.SaveHighestAttestation(pubKey, highestAtt)
 		}
+		if err != nil {
+			return errors.Wrap(err, "failed to save highest attestation")
+		}
+	}
 
-		// Save highest proposal
-		highestProposal, found, err := newStorage.RetrieveHighestProposal(newAccount.ValidatorPublicKey())
+	highestProposal, found, err := newStorage.RetrieveHighestProposal(pubKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve highest proposal")
+	}
+	if found && highestProposal != 0 {
+		existingProposal, found, err := store.RetrieveHighestProposal(pubKey)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to retrieve highest attestation")
+			return errors.Wrap(err, "failed to retrieve existing highest proposal")
 		}
-		if found && highestProposal != 0 {
-			if err := hashicorpStore.SaveHighestProposal(newAccount.ValidatorPublicKey(), highestProposal); err != nil {
-				return nil, errors.Wrap(err, "failed to save highest proposal")
+		if !found || highestProposal > existingProposal {
+			if err := store.SaveHighestProposal(pubKey, highestProposal); err != nil {
+				return errors.Wrap(err, "failed to save highest proposal")
 			}
 		}
 	}
-
-	return hashicorpStore, nil
+	return nil
 }
 
 // FromInMemoryStore creates the HashicorpVaultStore based on the given in-memory store.
@@ -221,11 +268,14 @@ func (store *HashicorpVaultStore) SaveWallet(wallet core.Wallet) error {
 		return errors.Wrap(err, "failed to marshal wallet")
 	}
 
-	return store.storage.Put(store.ctx, &logical.StorageEntry{
+	if err := store.storage.Put(store.ctx, &logical.StorageEntry{
 		Key:      WalletDataPath,
 		Value:    data,
 		SealWrap: false,
-	})
+	}); err != nil {
+		return err
+	}
+	return store.touchWallet()
 }
 
 // OpenWallet returns nil,nil if no wallet was found
@@ -266,11 +316,14 @@ func (store *HashicorpVaultStore) SaveAccount(account core.ValidatorAccount) err
 		return errors.Wrap(err, "failed to marshal account object")
 	}
 
-	return store.storage.Put(store.ctx, &logical.StorageEntry{
+	if err := store.storage.Put(store.ctx, &logical.StorageEntry{
 		Key:      fmt.Sprintf(AccountPath, account.ID().String()),
 		Value:    data,
 		SealWrap: false,
-	})
+	}); err != nil {
+		return err
+	}
+	return store.touchWallet()
 }
 
 // OpenAccount opens an account by the given ID. Returns nil,nil if no account was found.
@@ -301,7 +354,34 @@ func (store *HashicorpVaultStore) DeleteAccount(accountID uuid.UUID) error {
 	if err := store.storage.Delete(store.ctx, path); err != nil {
 		return errors.Wrapf(err, "failed to delete record with path '%s'", path)
 	}
-	return nil
+	return store.touchWallet()
+}
+
+// WalletGeneration returns the generation of the wallet and its accounts, it changes on every change of them.
+// Returns an empty generation if the wallet was not changed since the generation was introduced.
+func (store *HashicorpVaultStore) WalletGeneration() (string, error) {
+	entry, err := store.storage.Get(store.ctx, WalletGenerationPath)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get wallet generation")
+	}
+	if entry == nil {
+		return "", nil
+	}
+	return string(entry.Value), nil
+}
+
+// touchWallet sets a new wallet generation.
+func (store *HashicorpVaultStore) touchWallet() error {
+	return touchWallet(store.ctx, store.storage)
+}
+
+// touchWallet sets a new wallet generation of the given storage.
+func touchWallet(ctx context.Context, s logical.Storage) error {
+	return s.Put(ctx, &logical.StorageEntry{
+		Key:      WalletGenerationPath,
+		Value:    []byte(uuid.New().String()),
+		SealWrap: false,
+	})
 }
 
 // SetEncryptor sets the given encryptor. Could be nil value.
@@ -147,3 +147,33 @@ func TestWalletStorage(t *testing.T) {
 	// reset
 	storage.SetEncryptor(nil, nil)
 }
+
+func TestWalletGeneration(t *testing.T) {
+	storage := store.NewHashicorpVaultStore(context.Background(), getStorage(), core.PraterNetwork)
+
+	generation, err := storage.WalletGeneration()
+	require.NoError(t, err)
+	require.Empty(t, generation)
+
+	kv, err := keyVault(storage)
+	require.NoError(t, err)
+	wallet, err := kv.Wallet()
+	require.NoError(t, err)
+
+	walletGeneration, err := storage.WalletGeneration()
+	require.NoError(t, err)
+	require.NotEmpty(t, walletGeneration)
+
+	// Adding an account changes the generation
+	account, err := wallet.CreateValidatorAccount(_byteArray("0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1fff"), nil)
+	require.NoError(t, err)
+	accountGeneration, err := storage.WalletGeneration()
+	require.NoError(t, err)
+	require.NotEqual(t, walletGeneration, accountGeneration)
+
+	// Deleting an account changes the generation
+	require.NoError(t, wallet.DeleteAccountByPublicKey(hex.EncodeToString(account.ValidatorPublicKey())))
+	deleteGeneration, err := storage.WalletGeneration()
+	require.NoError(t, err)
+	require.NotEqual(t, accountGeneration, deleteGeneration)
+}
@@ -0,0 +1,62 @@
+package backend
+
+import (
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
+
+	"github.com/bloxapp/key-vault/keymanager/models"
+)
+
+// ErrWallClockDistance is returned when an attestation or a block is too far from the current wall-clock epoch or slot.
+var ErrWallClockDistance = errors.New("too far from the current wall-clock time")
+
+// validateWallClock refuses attestations whose target epoch and blocks whose slot are further than the configured
+// tolerance from the current epoch and slot, so a single request can't raise the slashing watermarks out of reach.
+// It is a no-op for other requests and when no tolerance is configured.
+func validateWallClock(config *Config, signReq *models.SignRequest, now time.Time) error {
+	var slot phase0.Slot
+	switch t := signReq.GetObject().(type) {
+	case *models.SignRequestAttestationData:
+		if config.AttestationEpochTolerance == 0 {
+			return nil
+		}
+		epoch, current := t.AttestationData.Target.Epoch, config.epochAt(now)
+		if distance(uint64(epoch), uint64(current)) > config.AttestationEpochTolerance {
+			return errors.Wrapf(ErrWallClockDistance, "refused to sign attestation of target epoch %d at epoch %d", epoch, current)
+		}
+		return nil
+	case *models.SignRequestBlock:
+		var err error
+		if slot, err = t.VersionedBeaconBlock.Slot(); err != nil {
+			return errors.Wrap(err, "failed to get block slot")
+		}
+	case *models.SignRequestBlindedBlock:
+		var err error
+		if slot, err = t.VersionedBlindedBeaconBlock.Slot(); err != nil {
+			return errors.Wrap(err, "failed to get blinded block slot")
+		}
+	case *models.SignRequestBlockHeader:
+		slot = t.BeaconBlockHeader.Slot
+	default:
+		return nil
+	}
+
+	if config.ProposalSlotTolerance == 0 {
+		return nil
+	}
+	current := config.slotAt(now)
+	if distance(uint64(slot), uint64(current)) > config.ProposalSlotTolerance {
+		return errors.Wrapf(ErrWallClockDistance, "refused to sign block of slot %d at slot %d", slot, current)
+	}
+	return nil
+}
+
+// distance returns the absolute difference of a and b.
+func distance(a, b uint64) uint64 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
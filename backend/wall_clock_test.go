@@ -0,0 +1,144 @@
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/bloxapp/eth2-key-manager/core"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bloxapp/key-vault/backend/store"
+	"github.com/bloxapp/key-vault/keymanager/models"
+)
+
+// testNow is the wall-clock time of the tests, the genesis time is set relative to it.
+var testNow = time.Unix(1700000000, 0)
+
+// withGenesisAtEpoch sets the genesis time so that testNow is at the start of the given epoch.
+func withGenesisAtEpoch(epoch phase0.Epoch) func(*Config) {
+	return func(c *Config) {
+		c.GenesisTime = uint64(testNow.Unix()) - uint64(epoch)*32*12
+	}
+}
+
+func TestConfigSlotAt(t *testing.T) {
+	config := Config{Network: core.PraterNetwork, GenesisTime: uint64(testNow.Unix())}
+
+	require.EqualValues(t, 0, config.slotAt(testNow.Add(-time.Minute)))
+	require.EqualValues(t, 0, config.slotAt(testNow.Add(11*time.Second)))
+	require.EqualValues(t, 1, config.slotAt(testNow.Add(12*time.Second)))
+
+	config.SlotDuration = 5
+	require.EqualValues(t, 2, config.slotAt(testNow.Add(12*time.Second)))
+	require.EqualValues(t, 1, config.epochAt(testNow.Add(32*5*time.Second)))
+}
+
+func TestValidateWallClock(t *testing.T) {
+	config := &Config{Network: core.PraterNetwork}
+	withGenesisAtEpoch(100)(config)
+
+	attestation := func(target phase0.Epoch) *models.SignRequest {
+		return &models.SignRequest{Object: &models.SignRequestAttestationData{AttestationData: &phase0.AttestationData{
+			Source: &phase0.Checkpoint{Epoch: target - 1},
+			Target: &phase0.Checkpoint{Epoch: target},
+		}}}
+	}
+	block := func(slot phase0.Slot) *models.SignRequest {
+		return &models.SignRequest{Object: &models.SignRequestBlockHeader{BeaconBlockHeader: &phase0.BeaconBlockHeader{Slot: slot}}}
+	}
+
+	t.Run("no tolerance", func(t *testing.T) {
+		require.NoError(t, validateWallClock(config, attestation(1000000), testNow))
+		require.NoError(t, validateWallClock(config, block(1000000), testNow))
+	})
+
+	config.AttestationEpochTolerance = 2
+	config.ProposalSlotTolerance = 4
+
+	t.Run("attestation within tolerance", func(t *testing.T) {
+		require.NoError(t, validateWallClock(config, attestation(98), testNow))
+		require.NoError(t, validateWallClock(config, attestation(100), testNow))
+		require.NoError(t, validateWallClock(config, attestation(102), testNow))
+	})
+
+	t.Run("attestation out of tolerance", func(t *testing.T) {
+		err := validateWallClock(config, attestation(103), testNow)
+		require.ErrorIs(t, err, ErrWallClockDistance)
+		require.EqualError(t, err, "refused to sign attestation of target epoch 103 at epoch 100: too far from the current wall-clock time")
+		require.ErrorIs(t, validateWallClock(config, attestation(97), testNow), ErrWallClockDistance)
+	})
+
+	t.Run("block within tolerance", func(t *testing.T) {
+		require.NoError(t, validateWallClock(config, block(3196), testNow))
+		require.NoError(t, validateWallClock(config, block(3204), testNow))
+	})
+
+	t.Run("block out of tolerance", func(t *testing.T) {
+		err := validateWallClock(config, block(3205), testNow)
+		require.ErrorIs(t, err, ErrWallClockDistance)
+		require.EqualError(t, err, "refused to sign block of slot 3205 at slot 3200: too far from the current wall-clock time")
+		require.ErrorIs(t, validateWallClock(config, block(3195), testNow), ErrWallClockDistance)
+	})
+
+	t.Run("other requests", func(t *testing.T) {
+		require.NoError(t, validateWallClock(config, &models.SignRequest{Object: &models.SignRequestSlot{Slot: 1000000}}, testNow))
+	})
+}
+
+func TestWallClockSigning(t *testing.T) {
+	b, _ := getBackend(t)
+	b.(*backend).now = func() time.Time {
+		return testNow
+	}
+	withTolerance := func(c *Config) {
+		c.AttestationEpochTolerance = 2
+		c.ProposalSlotTolerance = 4
+	}
+
+	t.Run("sign attestation within tolerance", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req, withTolerance, withGenesisAtEpoch(78))
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data = basicAttestationData()
+		res, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.NotEmpty(t, res.Data["signature"])
+	})
+
+	t.Run("refuse far future attestation without raising the watermark", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req, withTolerance, withGenesisAtEpoch(70))
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data = basicAttestationData()
+		_, err := b.HandleRequest(context.Background(), req)
+		require.ErrorIs(t, err, ErrWallClockDistance)
+
+		highest, found, err := store.NewHashicorpVaultStore(context.Background(), req.Storage, core.PraterNetwork).
+			RetrieveHighestAttestation(_byteArray(basePubKey))
+		require.NoError(t, err)
+		require.True(t, found)
+		require.EqualValues(t, 0, highest.Target.Epoch)
+	})
+
+	withEachBlockVersion(t, "refuse far future block without raising the watermark", func(t *testing.T, blockVersion spec.DataVersion, isBlinded bool) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req, withTolerance, withGenesisAtEpoch(1))
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		req.Data = basicProposalData(blockVersion, isBlinded)
+		_, err := b.HandleRequest(context.Background(), req)
+		require.ErrorIs(t, err, ErrWallClockDistance)
+
+		highest, found, err := store.NewHashicorpVaultStore(context.Background(), req.Storage, core.PraterNetwork).
+			RetrieveHighestProposal(_byteArray(basePubKey))
+		require.NoError(t, err)
+		require.True(t, found)
+		require.EqualValues(t, 1, highest)
+	})
+}
@@ -0,0 +1,143 @@
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+	"sync"
+
+	"github.com/bloxapp/eth2-key-manager/core"
+	"github.com/bloxapp/eth2-key-manager/wallets/hd"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// errReadOnlyWallet is returned when a cached wallet is modified.
+var errReadOnlyWallet = errors.New("cached wallet is read-only")
+
+// walletCache caches the decoded wallet and accounts of the mount, so signing does not decode them on every request.
+// The cached wallet is valid as long as the wallet generation, which changes on every wallet or account change, is the same.
+type walletCache struct {
+	lock       sync.RWMutex
+	network    core.Network
+	generation string
+	wallet     *cachedWallet
+}
+
+// get returns the cached wallet of the given network and wallet generation, or nil.
+func (c *walletCache) get(network core.Network, generation string) *cachedWallet {
+	if generation == "" {
+		return nil
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if c.wallet == nil || c.network != network || c.generation != generation {
+		return nil
+	}
+	return c.wallet
+}
+
+// put caches the given wallet of the given network and wallet generation.
+func (c *walletCache) put(network core.Network, generation string, wallet *cachedWallet) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.network = network
+	c.generation = generation
+	c.wallet = wallet
+}
+
+// reset drops the cached wallet.
+func (c *walletCache) reset() {
+	c.put("", "", nil)
+}
+
+// invalidate drops the cached wallet when the wallet or its accounts are changed,
+// it is called by Vault when a key is modified on another node of a replicated cluster.
+func (b *backend) invalidate(_ context.Context, key string) {
+	if strings.HasPrefix(key, "wallet/") {
+		b.walletCache.reset()
+	}
+}
+
+// cachedWallet is a read-only wallet of decoded accounts.
+type cachedWallet struct {
+	id         uuid.UUID
+	walletType core.WalletType
+	accounts   []core.ValidatorAccount
+	byID       map[uuid.UUID]core.ValidatorAccount
+	byPubKey   map[string]core.ValidatorAccount
+}
+
+// newCachedWallet decodes all accounts of the given wallet.
+func newCachedWallet(wallet core.Wallet) *cachedWallet {
+	accounts := wallet.Accounts()
+	w := &cachedWallet{
+		id:         wallet.ID(),
+		walletType: wallet.Type(),
+		accounts:   accounts,
+		byID:       make(map[uuid.UUID]core.ValidatorAccount, len(accounts)),
+		byPubKey:   make(map[string]core.ValidatorAccount, len(accounts)),
+	}
+	for _, account := range accounts {
+		w.byID[account.ID()] = account
+		w.byPubKey[hex.EncodeToString(account.ValidatorPublicKey())] = account
+	}
+	return w
+}
+
+// ID implements core.Wallet.
+func (w *cachedWallet) ID() uuid.UUID {
+	return w.id
+}
+
+// Type implements core.Wallet.
+func (w *cachedWallet) Type() core.WalletType {
+	return w.walletType
+}
+
+// CreateValidatorAccount implements core.Wallet, cached wallets are read-only.
+func (w *cachedWallet) CreateValidatorAccount(seed []byte, indexPointer *int) (core.ValidatorAccount, error) {
+	return nil, errReadOnlyWallet
+}
+
+// CreateValidatorAccountFromPrivateKey implements core.Wallet, cached wallets are read-only.
+func (w *cachedWallet) CreateValidatorAccountFromPrivateKey(privateKey []byte, indexPointer *int) (core.ValidatorAccount, error) {
+	return nil, errReadOnlyWallet
+}
+
+// AddValidatorAccount implements core.Wallet, cached wallets are read-only.
+func (w *cachedWallet) AddValidatorAccount(account core.ValidatorAccount) error {
+	return errReadOnlyWallet
+}
+
+// Accounts implements core.Wallet.
+func (w *cachedWallet) Accounts() []core.ValidatorAccount {
+	return append([]core.ValidatorAccount(nil), w.accounts...)
+}
+
+// AccountByID implements core.Wallet.
+func (w *cachedWallet) AccountByID(id uuid.UUID) (core.ValidatorAccount, error) {
+	account, ok := w.byID[id]
+	if !ok {
+		return nil, hd.ErrAccountNotFound
+	}
+	return account, nil
+}
+
+// AccountByPublicKey implements core.Wallet.
+func (w *cachedWallet) AccountByPublicKey(pubKey string) (core.ValidatorAccount, error) {
+	account, ok := w.byPubKey[pubKey]
+	if !ok {
+		return nil, hd.ErrAccountNotFound
+	}
+	return account, nil
+}
+
+// DeleteAccountByPublicKey implements core.Wallet, cached wallets are read-only.
+func (w *cachedWallet) DeleteAccountByPublicKey(pubKey string) error {
+	return errReadOnlyWallet
+}
+
+// SetContext implements core.Wallet, the accounts keep the context they were decoded with.
+func (w *cachedWallet) SetContext(ctx *core.WalletContext) {}
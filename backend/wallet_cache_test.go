@@ -0,0 +1,117 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bloxapp/eth2-key-manager/core"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bloxapp/key-vault/backend/store"
+)
+
+func TestWalletCache(t *testing.T) {
+	lb, _ := getBackend(t)
+	b := lb.(*backend)
+
+	openWallet := func(t *testing.T, s logical.Storage) *cachedWallet {
+		config, err := b.readConfig(context.Background(), s)
+		require.NoError(t, err)
+		_, wallet, err := b.openWallet(context.Background(), s, config)
+		require.NoError(t, err)
+		cached, ok := wallet.(*cachedWallet)
+		require.True(t, ok)
+		return cached
+	}
+
+	t.Run("Serve wallet from cache", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+
+		wallet := openWallet(t, req.Storage)
+		require.Len(t, wallet.Accounts(), 1)
+		require.Same(t, wallet, openWallet(t, req.Storage))
+
+		_, err := wallet.AccountByPublicKey("95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcf")
+		require.NoError(t, err)
+		_, err = wallet.AccountByPublicKey("95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcd")
+		require.EqualError(t, err, "account not found")
+		require.ErrorIs(t, wallet.DeleteAccountByPublicKey("95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcf"), errReadOnlyWallet)
+	})
+
+	t.Run("Refresh cache on wallet change", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+		wallet := openWallet(t, req.Storage)
+
+		// Replacing the wallet changes the wallet generation
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+		refreshed := openWallet(t, req.Storage)
+		require.NotSame(t, wallet, refreshed)
+		require.NotEqual(t, wallet.ID(), refreshed.ID())
+	})
+
+	t.Run("Invalidate cache", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+		wallet := openWallet(t, req.Storage)
+
+		b.Backend.InvalidateKey(context.Background(), "highestAttestations/95087182937f6982ae99f9b06bd116f463f414513032e33a3d175d9662eddf162101fcf6ca2a9fedaded74b8047c5dcf")
+		require.Same(t, wallet, openWallet(t, req.Storage))
+
+		b.Backend.InvalidateKey(context.Background(), store.WalletGenerationPath)
+		require.NotSame(t, wallet, openWallet(t, req.Storage))
+	})
+
+	t.Run("Miss cache after rolled back update", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+		wallet := openWallet(t, req.Storage)
+		config, err := b.readConfig(context.Background(), req.Storage)
+		require.NoError(t, err)
+
+		// A sign request reads the wallet generation before the update, and loads the wallet of the update while it runs
+		storage := store.NewHashicorpVaultStore(context.Background(), req.Storage, config.Network)
+		generation, err := storage.WalletGeneration()
+		require.NoError(t, err)
+		var updating core.Wallet
+		err = store.UpdateTransactionally(context.Background(), req.Storage, func(s logical.Storage) error {
+			if _, err := baseHashicorpStorage(context.Background(), s); err != nil {
+				return err
+			}
+			updating, err = openStoredWallet(storage)
+			require.NoError(t, err)
+			return errors.New("update failed")
+		})
+		require.EqualError(t, err, "update failed")
+		require.NotEqual(t, wallet.ID(), updating.ID())
+
+		// and caches it under that generation after the cache reset of the update
+		b.walletCache.reset()
+		b.walletCache.put(config.Network, generation, newCachedWallet(updating))
+
+		// The rolled back wallet is loaded again
+		rolledBack := openWallet(t, req.Storage)
+		require.Equal(t, wallet.ID(), rolledBack.ID())
+	})
+
+	t.Run("Do not cache wallet without generation", func(t *testing.T) {
+		req := logical.TestRequest(t, logical.CreateOperation, "accounts/sign")
+		setupBaseStorage(t, req)
+		require.NoError(t, setupStorageWithWalletAndAccounts(req.Storage))
+		require.NoError(t, req.Storage.Delete(context.Background(), store.WalletGenerationPath))
+
+		config, err := b.readConfig(context.Background(), req.Storage)
+		require.NoError(t, err)
+		_, wallet, err := b.openWallet(context.Background(), req.Storage, config)
+		require.NoError(t, err)
+		_, ok := wallet.(*cachedWallet)
+		require.False(t, ok)
+	})
+}
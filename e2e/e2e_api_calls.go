@@ -303,3 +303,69 @@ func (setup *BaseSetup) UpdateStorage(t *testing.T, network core.Network, minima
 	fmt.Printf("e2e: setup hashicorp vault db\n")
 	return store
 }
+
+// ApproveVoluntaryExit proposes a voluntary exit and approves it with the tokens of two identity entities.
+func (setup *BaseSetup) ApproveVoluntaryExit(t *testing.T, network core.Network, pubKey []byte, epoch uint64) {
+	// propose
+	proposal := setup.postJSON(t, fmt.Sprintf("%s/v1/ethereum/%s/accounts/exits/propose", setup.baseURL, network), setup.entityToken(t, "exit-proposer"), map[string]interface{}{
+		"public_key": hex.EncodeToString(pubKey),
+		"epoch":      epoch,
+	})
+	id := proposal["data"].(map[string]interface{})["id"].(string)
+
+	// approve
+	setup.postJSON(t, fmt.Sprintf("%s/v1/ethereum/%s/accounts/exits/approve", setup.baseURL, network), setup.entityToken(t, "exit-approver"), map[string]interface{}{
+		"id": id,
+	})
+}
+
+// entityToken creates a token of the identity entity of the given token entity alias, with access to the plugin.
+// Exits are only proposed and approved by identity entities, the root token has none.
+func (setup *BaseSetup) entityToken(t *testing.T, alias string) string {
+	setup.postJSON(t, fmt.Sprintf("%s/v1/sys/policies/acl/e2e-exits", setup.baseURL), setup.RootKey, map[string]interface{}{
+		"policy": `path "ethereum/*" { capabilities = ["create", "read", "update", "list"] }`,
+	})
+	setup.postJSON(t, fmt.Sprintf("%s/v1/auth/token/roles/e2e-exits", setup.baseURL), setup.RootKey, map[string]interface{}{
+		"allowed_policies":       []string{"e2e-exits"},
+		"allowed_entity_aliases": []string{"exit-proposer", "exit-approver"},
+	})
+	token := setup.postJSON(t, fmt.Sprintf("%s/v1/auth/token/create/e2e-exits", setup.baseURL), setup.RootKey, map[string]interface{}{
+		"policies":     []string{"e2e-exits"},
+		"entity_alias": alias,
+	})
+	return token["auth"].(map[string]interface{})["client_token"].(string)
+}
+
+// postJSON sends the given body to the given URL and returns the decoded response body.
+func (setup *BaseSetup) postJSON(t *testing.T, targetURL string, token string, data map[string]interface{}) map[string]interface{} {
+	// body
+	body, err := json.Marshal(data)
+	require.NoError(t, err)
+
+	// build req
+	req, err := http.NewRequest(http.MethodPost, targetURL, bytes.NewBuffer(body))
+	require.NoError(t, err)
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	// Do request
+	httpClient := http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+	resp, err := httpClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// parse to json
+	retObj := make(map[string]interface{})
+	if resp.StatusCode == http.StatusNoContent {
+		return retObj
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&retObj))
+	require.Equal(t, http.StatusOK, resp.StatusCode, retObj)
+	return retObj
+}
@@ -51,6 +51,9 @@ func (test *VoluntaryExitSigning) Run(t *testing.T) {
 	res, _, err := signer.SignVoluntaryExit(voluntaryExit, domain, pubKeyBytes)
 	require.NoError(t, err)
 
+	// Voluntary exits are only signed once approved by a second person
+	setup.ApproveVoluntaryExit(t, core.PraterNetwork, pubKeyBytes, uint64(voluntaryExit.Epoch))
+
 	// Send sign voluntary exit request
 	req, err := test.serializedReq(pubKeyBytes, nil, domain, voluntaryExit)
 	require.NoError(t, err)
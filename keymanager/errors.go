@@ -99,3 +99,41 @@ func (e *GenericError) String() string {
 	}
 	return string(data)
 }
+
+// SignError represents the error of a single request of a sign batch.
+type SignError struct {
+	Type     string `json:"type"`
+	ErrorMsg string `json:"error"`
+}
+
+// NewSignError is the constructor of SignError.
+func NewSignError(errType, msg string) *SignError {
+	return &SignError{
+		Type:     errType,
+		ErrorMsg: msg,
+	}
+}
+
+// IsSignError returns true if the given error is SignError
+func IsSignError(err error) bool {
+	_, ok := errors.Cause(err).(*SignError)
+	return ok
+}
+
+// Error implements error interface.
+func (e *SignError) Error() string {
+	return e.String()
+}
+
+// String implements fmt.Stringer interface.
+func (e *SignError) String() string {
+	if e == nil {
+		return ""
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	return string(data)
+}
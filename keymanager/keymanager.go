@@ -34,9 +34,16 @@ type IkeyManager interface {
 	FetchValidatingPublicKeys(_ context.Context) ([][48]byte, error)
 	FetchAllValidatingPublicKeys(_ context.Context) ([][48]byte, error)
 	Sign(_ context.Context, req *models.SignRequest) (phase0.BLSSignature, error)
+	SignBatch(_ context.Context, reqs []*models.SignRequest) ([]SignResult, error)
 	sendRequest(_ context.Context, method, path string, reqBody interface{}, respBody interface{}) error
 }
 
+// SignResult is the result of a single request of a sign batch.
+type SignResult struct {
+	Signature phase0.BLSSignature
+	Err       error
+}
+
 // KeyManager is a key manager that accesses a remote vault wallet daemon through HTTP connection.
 type KeyManager struct {
 	remoteAddress string
@@ -45,6 +52,7 @@ type KeyManager struct {
 	pubKey        [48]byte
 	network       string
 	httpClient    *http.Client
+	encoding      string
 	encoder       encoder.IEncoder
 
 	log *logrus.Entry
@@ -70,6 +78,19 @@ func NewKeyManager(log *logrus.Entry, opts *Config) (*KeyManager, error) {
 		return nil, NewGenericError(err, "failed to hex decode public key '%s'", opts.PubKey)
 	}
 
+	// Select sign request encoding
+	encoding := opts.Encoding
+	var enc encoder.IEncoder
+	switch encoding {
+	case "", encoder.EncodingV2:
+		encoding = encoder.EncodingV2
+		enc = encoder.New()
+	case encoder.EncodingJSON:
+		enc = encoder.NewJSON()
+	default:
+		return nil, NewGenericErrorMessage("unsupported sign request encoding '%s'", opts.Encoding)
+	}
+
 	log.Logf(logrus.InfoLevel, "KeyManager initialing for %s network", opts.Network)
 
 	return &KeyManager{
@@ -78,7 +99,8 @@ func NewKeyManager(log *logrus.Entry, opts *Config) (*KeyManager, error) {
 		originPubKey:  opts.PubKey,
 		pubKey:        bytex.ToBytes48(decodedPubKey),
 		network:       opts.Network,
-		encoder:       encoder.New(),
+		encoding:      encoding,
+		encoder:       enc,
 		httpClient: httpex.CreateClient(log, func(resp *http.Response, err error, numTries int) (*http.Response, error) {
 			if err == nil {
 				return resp, nil
@@ -121,12 +143,13 @@ func (km *KeyManager) Sign(ctx context.Context, req *models.SignRequest) (phase0
 		return phase0.BLSSignature{}, ErrNoSuchKey
 	}
 
-	byts, err := km.encoder.Encode(req)
+	encodedReq, err := km.encodeSignRequest(req)
 	if err != nil {
 		return phase0.BLSSignature{}, errors.Wrap(err, "failed to encode request")
 	}
 	reqMap := map[string]interface{}{
-		"sign_req": hex.EncodeToString(byts),
+		"sign_req": encodedReq,
+		"encoding": km.encoding,
 	}
 
 	var resp models.SignResponse
@@ -145,6 +168,61 @@ func (km *KeyManager) Sign(ctx context.Context, req *models.SignRequest) (phase0
 	return signature, nil
 }
 
+// SignBatch signs the given requests in a single round trip.
+// Unlike Sign, the requests are not limited to the key manager's public key.
+// The results are returned in the requests order, a refused request does not fail the whole batch.
+func (km *KeyManager) SignBatch(ctx context.Context, reqs []*models.SignRequest) ([]SignResult, error) {
+	encodedReqs := make([]string, len(reqs))
+	for i, req := range reqs {
+		encodedReq, err := km.encodeSignRequest(req)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to encode request %d", i)
+		}
+		encodedReqs[i] = encodedReq
+	}
+	reqMap := map[string]interface{}{
+		"sign_reqs": encodedReqs,
+		"encoding":  km.encoding,
+	}
+
+	var resp models.SignBatchResponse
+	if err := km.sendRequest(ctx, http.MethodPost, backend.SignBatchPattern, reqMap, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Data.Results) != len(reqs) {
+		return nil, NewGenericErrorMessage("expected %d results, got %d", len(reqs), len(resp.Data.Results))
+	}
+
+	results := make([]SignResult, len(reqs))
+	for i, res := range resp.Data.Results {
+		if res.ErrorType != "" {
+			results[i].Err = NewSignError(res.ErrorType, res.Error)
+			continue
+		}
+
+		decodedSignature, err := hex.DecodeString(res.Signature)
+		if err != nil {
+			results[i].Err = NewGenericError(err, "failed to hex decode")
+			continue
+		}
+		copy(results[i].Signature[:], decodedSignature)
+	}
+	return results, nil
+}
+
+// encodeSignRequest encodes the given request with the key manager encoding.
+// V2 requests are hex encoded, JSON requests are sent as is.
+func (km *KeyManager) encodeSignRequest(req *models.SignRequest) (string, error) {
+	byts, err := km.encoder.Encode(req)
+	if err != nil {
+		return "", err
+	}
+	if km.encoding == encoder.EncodingJSON {
+		return string(byts), nil
+	}
+	return hex.EncodeToString(byts), nil
+}
+
 // sendRequest implements the logic to work with HTTP requests.
 func (km *KeyManager) sendRequest(ctx context.Context, method, path string, reqBody interface{}, respBody interface{}) error {
 	networkPath, err := endpoint.Build(km.network, path)
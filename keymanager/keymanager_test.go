@@ -82,6 +82,20 @@ func TestNewKeyManager(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "unsupported encoding",
+			args: args{
+				log: entry,
+				opts: &keymanager.Config{
+					Location:    "Location",
+					AccessToken: "AccessToken",
+					PubKey:      DefaultAccountPublicKey,
+					Network:     "Network",
+					Encoding:    "invalid",
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
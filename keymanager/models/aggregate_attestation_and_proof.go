@@ -1,13 +1,35 @@
 package models
 
 import (
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/electra"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/pkg/errors"
 )
 
 // SignRequestAggregateAttestationAndProof struct
 type SignRequestAggregateAttestationAndProof struct {
+	// Version is the fork of the aggregate, the phase0 container is used up to deneb.
+	// An unset version is treated as phase0 for requests encoded before versioning.
+	Version                      spec.DataVersion
 	AggregateAttestationAndProof *phase0.AggregateAndProof
+	// ElectraAggregateAndProof is the aggregate from electra, with the committee bits of its attestation.
+	ElectraAggregateAndProof *electra.AggregateAndProof
 }
 
 // isSignRequestObject implement func
 func (m *SignRequestAggregateAttestationAndProof) isSignRequestObject() {}
+
+// AttestationData returns the data of the aggregated attestation.
+func (m *SignRequestAggregateAttestationAndProof) AttestationData() (*phase0.AttestationData, error) {
+	if m.Version >= spec.DataVersionElectra {
+		if m.ElectraAggregateAndProof == nil || m.ElectraAggregateAndProof.Aggregate == nil || m.ElectraAggregateAndProof.Aggregate.Data == nil {
+			return nil, errors.New("no electra aggregate and proof")
+		}
+		return m.ElectraAggregateAndProof.Aggregate.Data, nil
+	}
+	if m.AggregateAttestationAndProof == nil || m.AggregateAttestationAndProof.Aggregate == nil || m.AggregateAttestationAndProof.Aggregate.Data == nil {
+		return nil, errors.New("no aggregate and proof")
+	}
+	return m.AggregateAttestationAndProof.Aggregate.Data, nil
+}
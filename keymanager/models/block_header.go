@@ -0,0 +1,13 @@
+package models
+
+import (
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// SignRequestBlockHeader struct
+type SignRequestBlockHeader struct {
+	BeaconBlockHeader *phase0.BeaconBlockHeader
+}
+
+// isSignRequestObject implement func
+func (m *SignRequestBlockHeader) isSignRequestObject() {}
@@ -0,0 +1,13 @@
+package models
+
+import (
+	"github.com/attestantio/go-eth2-client/spec/capella"
+)
+
+// SignRequestBLSToExecutionChange struct
+type SignRequestBLSToExecutionChange struct {
+	BLSToExecutionChange *capella.BLSToExecutionChange
+}
+
+// isSignRequestObject implement func
+func (m *SignRequestBLSToExecutionChange) isSignRequestObject() {}
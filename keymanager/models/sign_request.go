@@ -4,6 +4,7 @@ import (
 	"github.com/attestantio/go-eth2-client/api"
 	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/capella"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 )
 
@@ -63,6 +64,14 @@ func (x *SignRequest) GetBlock() *spec.VersionedBeaconBlock {
 	return nil
 }
 
+// GetBlockHeader return BeaconBlockHeader
+func (x *SignRequest) GetBlockHeader() *phase0.BeaconBlockHeader {
+	if x, ok := x.GetObject().(*SignRequestBlockHeader); ok {
+		return x.BeaconBlockHeader
+	}
+	return nil
+}
+
 // GetAttestationData return AttestationData
 func (x *SignRequest) GetAttestationData() *phase0.AttestationData {
 	if x, ok := x.GetObject().(*SignRequestAttestationData); ok {
@@ -87,6 +96,14 @@ func (x *SignRequest) GetVoluntaryExit() *phase0.VoluntaryExit {
 	return nil
 }
 
+// GetBLSToExecutionChange return BLSToExecutionChange
+func (x *SignRequest) GetBLSToExecutionChange() *capella.BLSToExecutionChange {
+	if x, ok := x.GetObject().(*SignRequestBLSToExecutionChange); ok {
+		return x.BLSToExecutionChange
+	}
+	return nil
+}
+
 // GetSlot return types slot
 func (x *SignRequest) GetSlot() phase0.Slot {
 	if x, ok := x.GetObject().(*SignRequestSlot); ok {
@@ -7,5 +7,24 @@ type SignResponse struct {
 
 // SignatureModel represents vault signature model.
 type SignatureModel struct {
-	Signature string `json:"signature"`
+	Signature   string `json:"signature"`
+	SigningRoot string `json:"signing_root,omitempty"`
+}
+
+// SignBatchResponse is the vault sign batch response model.
+type SignBatchResponse struct {
+	Data SignBatchResultsModel `json:"data"`
+}
+
+// SignBatchResultsModel represents vault sign batch results model.
+type SignBatchResultsModel struct {
+	Results []SignBatchResultModel `json:"results"`
+}
+
+// SignBatchResultModel represents vault sign batch result model of a single request.
+type SignBatchResultModel struct {
+	Signature   string `json:"signature,omitempty"`
+	SigningRoot string `json:"signing_root,omitempty"`
+	Error       string `json:"error,omitempty"`
+	ErrorType   string `json:"error_type,omitempty"`
 }
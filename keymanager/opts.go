@@ -13,6 +13,8 @@ type Config struct {
 	AccessToken string `json:"access_token"`
 	PubKey      string `json:"public_key"`
 	Network     string `json:"network"`
+	// Encoding is the sign request encoding, encoder.EncodingV2 (default) or encoder.EncodingJSON
+	Encoding string `json:"encoding,omitempty"`
 }
 
 // UnmarshalConfigFile attempts to JSON unmarshal a keymanager
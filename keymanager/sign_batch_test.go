@@ -0,0 +1,108 @@
+package keymanager_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bloxapp/key-vault/keymanager"
+	"github.com/bloxapp/key-vault/keymanager/models"
+	"github.com/bloxapp/key-vault/utils/encoder"
+)
+
+type signBatchRequestBody struct {
+	SignReqs []string `json:"sign_reqs"`
+	Encoding string   `json:"encoding"`
+}
+
+func TestSignBatch(t *testing.T) {
+	expectedSig := _byteArray("b75a751c2c5c16175c4678e8fc8ed75e903153b221f3803bf55982934113468139d91049d4c8f9efae92889505b42dda045df95e233d7ae0140f5bf882d91373a98056b09410769a7bc9319c9a42bc90c626a2301ba8f084522def59840aec80")
+
+	s := newTestRemoteWallet(func(writer http.ResponseWriter, request *http.Request) {
+		require.Equal(t, http.MethodPost, request.Method)
+		require.Equal(t, "/v1/ethereum/prater/accounts/sign-batch", request.URL.Path)
+
+		var reqBody signBatchRequestBody
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&reqBody))
+		require.Len(t, reqBody.SignReqs, 2)
+		require.Equal(t, encoder.EncodingV2, reqBody.Encoding)
+
+		// test un-marshaling the requests
+		for _, val := range reqBody.SignReqs {
+			valByts, err := hex.DecodeString(val)
+			require.NoError(t, err)
+			require.NoError(t, encoder.New().Decode(valByts, &models.SignRequest{}))
+		}
+
+		respBody := &logical.Response{
+			Data: map[string]interface{}{
+				"results": []map[string]interface{}{
+					{"signature": hex.EncodeToString(expectedSig)},
+					{"error": "slashable attestation (HighestAttestationVote), not signing", "error_type": "slashable"},
+				},
+			},
+		}
+		require.NoError(t, json.NewEncoder(writer).Encode(respBody))
+	})
+	defer s.Close()
+
+	km, err := keymanager.NewKeyManager(logrus.NewEntry(logrus.New()), &keymanager.Config{
+		Location:    s.URL,
+		AccessToken: DefaultAccessToken,
+		PubKey:      "a3862121db5914d7272b0b705e6e3c5336b79e316735661873566245207329c30f9a33d4fb5f5857fc6fd0a368186972",
+		Network:     "prater",
+	})
+	require.NoError(t, err)
+
+	results, err := km.SignBatch(context.Background(), []*models.SignRequest{testRequest(t), testRequest(t)})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.NoError(t, results[0].Err)
+	require.EqualValues(t, expectedSig, results[0].Signature[:])
+	require.True(t, keymanager.IsSignError(results[1].Err))
+	require.EqualError(t, results[1].Err, "{\"type\":\"slashable\",\"error\":\"slashable attestation (HighestAttestationVote), not signing\"}")
+}
+
+func TestSignBatchJSONEncoding(t *testing.T) {
+	s := newTestRemoteWallet(func(writer http.ResponseWriter, request *http.Request) {
+		var reqBody signBatchRequestBody
+		require.NoError(t, json.NewDecoder(request.Body).Decode(&reqBody))
+		require.Len(t, reqBody.SignReqs, 1)
+		require.Equal(t, encoder.EncodingJSON, reqBody.Encoding)
+
+		// requests are sent as plain JSON
+		req := &models.SignRequest{}
+		require.NoError(t, encoder.NewJSON().Decode([]byte(reqBody.SignReqs[0]), req))
+		require.EqualValues(t, testRequest(t), req)
+
+		respBody := &logical.Response{
+			Data: map[string]interface{}{
+				"results": []map[string]interface{}{
+					{"error": "account not found", "error_type": "account_not_found"},
+				},
+			},
+		}
+		require.NoError(t, json.NewEncoder(writer).Encode(respBody))
+	})
+	defer s.Close()
+
+	km, err := keymanager.NewKeyManager(logrus.NewEntry(logrus.New()), &keymanager.Config{
+		Location:    s.URL,
+		AccessToken: DefaultAccessToken,
+		PubKey:      "a3862121db5914d7272b0b705e6e3c5336b79e316735661873566245207329c30f9a33d4fb5f5857fc6fd0a368186972",
+		Network:     "prater",
+		Encoding:    encoder.EncodingJSON,
+	})
+	require.NoError(t, err)
+
+	results, err := km.SignBatch(context.Background(), []*models.SignRequest{testRequest(t)})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.True(t, keymanager.IsSignError(results[0].Err))
+}
@@ -8,26 +8,71 @@ path "ethereum/mainnet/accounts" {
   capabilities = ["list"]
 }
 
+# Ability to read wallet accounts ("read")
+path "ethereum/+/accounts/+" {
+  capabilities = ["read"]
+}
+
 # Ability to sign data ("create")
 path "ethereum/+/accounts/sign" {
   capabilities = ["create"]
 }
 
+# Ability to sign a batch of data ("create")
+path "ethereum/+/accounts/sign-batch" {
+  capabilities = ["create"]
+}
+
 # Ability to get version ("read")
 path "ethereum/+/version" {
   capabilities = ["read"]
 }
 
+# Ability to read public key locks stats ("read")
+path "ethereum/+/locks" {
+  capabilities = ["read"]
+}
+
 # Ability to update storage ("create")
 path "ethereum/+/storage" {
   capabilities = ["create"]
 }
 
+# Ability to import keystores ("create")
+path "ethereum/+/storage/keystores" {
+  capabilities = ["create"]
+}
+
 # Ability to read slashing storage ("read")
 path "ethereum/+/storage/slashing" {
   capabilities = ["read"]
 }
 
+# Ability to import slashing protection interchange ("create")
+path "ethereum/+/storage/slashing/import" {
+  capabilities = ["create"]
+}
+
+# Ability to export slashing protection interchange ("read")
+path "ethereum/+/storage/slashing/export" {
+  capabilities = ["read"]
+}
+
+# Ability to set slashing watermarks ("create")
+path "ethereum/+/storage/slashing/+" {
+  capabilities = ["create"]
+}
+
+# Ability to list slashing watermark changes ("list")
+path "ethereum/+/storage/slashing/audit" {
+  capabilities = ["list"]
+}
+
+# Ability to read and lift account quarantines ("read", "delete")
+path "ethereum/+/accounts/+/quarantine" {
+  capabilities = ["read", "delete"]
+}
+
 # Ability to create/update/read config
 path "ethereum/+/config" {
   capabilities = ["create", "update", "read"]
@@ -36,4 +81,49 @@ path "ethereum/+/config" {
 # Ability to sign voluntary exit ("create")
 path "ethereum/+/accounts/sign-voluntary-exit" {
   capabilities = ["create"]
-}
\ No newline at end of file
+}
+
+# Ability to sign bls to execution change ("create")
+path "ethereum/+/accounts/sign-bls-to-execution-change" {
+  capabilities = ["create"]
+}
+
+# Ability to generate deposit data ("create")
+path "ethereum/+/accounts/+/deposit-data" {
+  capabilities = ["create"]
+}
+
+# Ability to sign data using the Web3Signer API ("create")
+path "ethereum/+/api/v1/eth2/sign/*" {
+  capabilities = ["create", "update"]
+}
+
+# Ability to list public keys using the Web3Signer API ("read")
+path "ethereum/+/api/v1/eth2/publicKeys" {
+  capabilities = ["read"]
+}
+
+# Ability to check the Web3Signer API status ("read")
+path "ethereum/+/upcheck" {
+  capabilities = ["read"]
+}
+
+# Ability to propose voluntary exits ("create")
+path "ethereum/+/accounts/exits/propose" {
+  capabilities = ["create"]
+}
+
+# Ability to list voluntary exit requests ("list")
+path "ethereum/+/accounts/exits" {
+  capabilities = ["list"]
+}
+
+# Ability to read and set account statuses ("read", "create", "update")
+path "ethereum/+/accounts/+/status" {
+  capabilities = ["read", "create", "update"]
+}
+
+# Ability to delete accounts ("create")
+path "ethereum/+/accounts/delete" {
+  capabilities = ["create"]
+}
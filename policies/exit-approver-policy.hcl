@@ -0,0 +1,9 @@
+# Ability to approve voluntary exits ("create")
+path "ethereum/+/accounts/exits/approve" {
+  capabilities = ["create"]
+}
+
+# Ability to list voluntary exit requests ("list")
+path "ethereum/+/accounts/exits" {
+  capabilities = ["list"]
+}
@@ -13,6 +13,11 @@ path "ethereum/+/accounts/sign" {
   capabilities = ["create"]
 }
 
+# Ability to sign a batch of data ("create")
+path "ethereum/+/accounts/sign-batch" {
+  capabilities = ["create"]
+}
+
 # Ability to get version ("read")
 path "ethereum/+/version" {
   capabilities = ["read"]
@@ -22,3 +27,18 @@ path "ethereum/+/version" {
 path "ethereum/+/config" {
   capabilities = ["read"]
 }
+
+# Ability to sign data using the Web3Signer API ("create")
+path "ethereum/+/api/v1/eth2/sign/*" {
+  capabilities = ["create", "update"]
+}
+
+# Ability to list public keys using the Web3Signer API ("read")
+path "ethereum/+/api/v1/eth2/publicKeys" {
+  capabilities = ["read"]
+}
+
+# Ability to check the Web3Signer API status ("read")
+path "ethereum/+/upcheck" {
+  capabilities = ["read"]
+}
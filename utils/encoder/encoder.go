@@ -7,3 +7,11 @@ type IEncoder interface {
 	// Decode takes an object and bytes to store decoded data in object, returns error if fails
 	Decode(data []byte, v interface{}) error
 }
+
+// Sign request encodings
+const (
+	// EncodingV2 is the hex encoded V2 encoding, SSZ objects wrapped in JSON
+	EncodingV2 = "v2"
+	// EncodingJSON is the plain JSON encoding, go-eth2-client JSON objects with an object type discriminator
+	EncodingJSON = "json"
+)
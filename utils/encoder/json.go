@@ -0,0 +1,395 @@
+package encoder
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/attestantio/go-eth2-client/api"
+	eth2apiv1 "github.com/attestantio/go-eth2-client/api/v1"
+	apiv1bellatrix "github.com/attestantio/go-eth2-client/api/v1/bellatrix"
+	apiv1capella "github.com/attestantio/go-eth2-client/api/v1/capella"
+	apiv1deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	apiv1electra "github.com/attestantio/go-eth2-client/api/v1/electra"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+
+	"github.com/bloxapp/key-vault/keymanager/models"
+)
+
+// Sign request object types of the JSON encoding
+const (
+	ObjectTypeAttestationData             = "attestation_data"
+	ObjectTypeBlock                       = "block"
+	ObjectTypeBlindedBlock                = "blinded_block"
+	ObjectTypeBlockHeader                 = "block_header"
+	ObjectTypeAggregateAndProof           = "aggregate_and_proof"
+	ObjectTypeSlot                        = "slot"
+	ObjectTypeEpoch                       = "epoch"
+	ObjectTypeSyncCommitteeMessage        = "sync_committee_message"
+	ObjectTypeSyncAggregatorSelectionData = "sync_aggregator_selection_data"
+	ObjectTypeContributionAndProof        = "contribution_and_proof"
+	ObjectTypeValidatorRegistration       = "validator_registration"
+	ObjectTypeVoluntaryExit               = "voluntary_exit"
+	ObjectTypeBLSToExecutionChange        = "bls_to_execution_change"
+)
+
+// signRequestJSON is the human-readable JSON form of a sign request,
+// the object is encoded with its go-eth2-client JSON form.
+type signRequestJSON struct {
+	PublicKey       hexutil.Bytes   `json:"public_key,omitempty"`
+	SigningRoot     hexutil.Bytes   `json:"signing_root,omitempty"`
+	SignatureDomain hexutil.Bytes   `json:"signature_domain,omitempty"`
+	Type            string          `json:"type,omitempty"`
+	Version         string          `json:"version,omitempty"`
+	Object          json.RawMessage `json:"object,omitempty"`
+}
+
+// JSON encoder
+type JSON struct {
+}
+
+// NewJSON returns JSON struct
+func NewJSON() *JSON {
+	return &JSON{}
+}
+
+// Encode to JSON format
+func (l *JSON) Encode(obj interface{}) ([]byte, error) {
+	switch t := obj.(type) {
+	case *models.SignRequest:
+		return encodeSignRequestJSON(t)
+	}
+	return nil, errors.New("type not supported")
+}
+
+// Decode from JSON format
+func (l *JSON) Decode(data []byte, v interface{}) error {
+	switch t := v.(type) {
+	case *models.SignRequest:
+		return decodeSignRequestJSON(data, t)
+	}
+	return errors.New("type not supported")
+}
+
+func encodeSignRequestJSON(sr *models.SignRequest) ([]byte, error) {
+	toEncode := signRequestJSON{
+		PublicKey:       sr.PublicKey,
+		SigningRoot:     sr.SigningRoot,
+		SignatureDomain: sr.SignatureDomain[:],
+	}
+
+	if sr.Object == nil {
+		return json.Marshal(toEncode)
+	}
+
+	var (
+		obj interface{}
+		err error
+	)
+	switch t := sr.Object.(type) {
+	case *models.SignRequestAttestationData:
+		toEncode.Type = ObjectTypeAttestationData
+		obj = t.AttestationData
+	case *models.SignRequestBlock:
+		toEncode.Type = ObjectTypeBlock
+		if t.VersionedBeaconBlock == nil {
+			return nil, errors.New("no block")
+		}
+		toEncode.Version = t.VersionedBeaconBlock.Version.String()
+		switch t.VersionedBeaconBlock.Version {
+		case spec.DataVersionPhase0:
+			obj = t.VersionedBeaconBlock.Phase0
+		case spec.DataVersionAltair:
+			obj = t.VersionedBeaconBlock.Altair
+		case spec.DataVersionBellatrix:
+			obj = t.VersionedBeaconBlock.Bellatrix
+		case spec.DataVersionCapella:
+			obj = t.VersionedBeaconBlock.Capella
+		case spec.DataVersionDeneb:
+			obj = t.VersionedBeaconBlock.Deneb
+		case spec.DataVersionElectra:
+			obj = t.VersionedBeaconBlock.Electra
+		default:
+			return nil, errors.Errorf("unsupported block version %d", t.VersionedBeaconBlock.Version)
+		}
+	case *models.SignRequestBlindedBlock:
+		toEncode.Type = ObjectTypeBlindedBlock
+		if t.VersionedBlindedBeaconBlock == nil {
+			return nil, errors.New("no blinded block")
+		}
+		toEncode.Version = t.VersionedBlindedBeaconBlock.Version.String()
+		switch t.VersionedBlindedBeaconBlock.Version {
+		case spec.DataVersionBellatrix:
+			obj = t.VersionedBlindedBeaconBlock.Bellatrix
+		case spec.DataVersionCapella:
+			obj = t.VersionedBlindedBeaconBlock.Capella
+		case spec.DataVersionDeneb:
+			obj = t.VersionedBlindedBeaconBlock.Deneb
+		case spec.DataVersionElectra:
+			obj = t.VersionedBlindedBeaconBlock.Electra
+		default:
+			return nil, errors.Errorf("unsupported blinded block version %d", t.VersionedBlindedBeaconBlock.Version)
+		}
+	case *models.SignRequestBlockHeader:
+		toEncode.Type = ObjectTypeBlockHeader
+		obj = t.BeaconBlockHeader
+	case *models.SignRequestAggregateAttestationAndProof:
+		toEncode.Type = ObjectTypeAggregateAndProof
+		if t.Version != spec.DataVersionUnknown {
+			toEncode.Version = t.Version.String()
+		}
+		switch {
+		case t.Version <= spec.DataVersionDeneb:
+			obj = t.AggregateAttestationAndProof
+		case t.Version == spec.DataVersionElectra:
+			obj = t.ElectraAggregateAndProof
+		default:
+			return nil, errors.Errorf("unsupported aggregate and proof version %d", t.Version)
+		}
+	case *models.SignRequestSlot:
+		toEncode.Type = ObjectTypeSlot
+		obj = strconv.FormatUint(uint64(t.Slot), 10)
+	case *models.SignRequestEpoch:
+		toEncode.Type = ObjectTypeEpoch
+		obj = strconv.FormatUint(uint64(t.Epoch), 10)
+	case *models.SignRequestSyncCommitteeMessage:
+		toEncode.Type = ObjectTypeSyncCommitteeMessage
+		obj = hexutil.Bytes(t.Root)
+	case *models.SignRequestSyncAggregatorSelectionData:
+		toEncode.Type = ObjectTypeSyncAggregatorSelectionData
+		obj = t.SyncAggregatorSelectionData
+	case *models.SignRequestContributionAndProof:
+		toEncode.Type = ObjectTypeContributionAndProof
+		obj = t.ContributionAndProof
+	case *models.SignRequestRegistration:
+		toEncode.Type = ObjectTypeValidatorRegistration
+		if t.VersionedValidatorRegistration == nil {
+			return nil, errors.New("no validator registration")
+		}
+		toEncode.Version = t.VersionedValidatorRegistration.Version.String()
+		switch t.VersionedValidatorRegistration.Version {
+		case spec.BuilderVersionV1:
+			obj = t.VersionedValidatorRegistration.V1
+		default:
+			return nil, errors.Errorf("unsupported registration version %d", t.VersionedValidatorRegistration.Version)
+		}
+	case *models.SignRequestVoluntaryExit:
+		toEncode.Type = ObjectTypeVoluntaryExit
+		obj = t.VoluntaryExit
+	case *models.SignRequestBLSToExecutionChange:
+		toEncode.Type = ObjectTypeBLSToExecutionChange
+		obj = t.BLSToExecutionChange
+	default:
+		return nil, errors.New("sign request unknown object type")
+	}
+
+	if toEncode.Object, err = json.Marshal(obj); err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal %s", toEncode.Type)
+	}
+	return json.Marshal(toEncode)
+}
+
+func decodeSignRequestJSON(data []byte, sr *models.SignRequest) error {
+	toDecode := &signRequestJSON{}
+	if err := json.Unmarshal(data, toDecode); err != nil {
+		return err
+	}
+
+	sr.PublicKey = toDecode.PublicKey
+	sr.SigningRoot = toDecode.SigningRoot
+	if len(toDecode.SignatureDomain) != 0 && len(toDecode.SignatureDomain) != len(sr.SignatureDomain) {
+		return errors.New("invalid signature domain length")
+	}
+	copy(sr.SignatureDomain[:], toDecode.SignatureDomain)
+
+	if toDecode.Type == "" {
+		return nil
+	}
+
+	unmarshal := func(v interface{}) error {
+		if err := json.Unmarshal(toDecode.Object, v); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal %s", toDecode.Type)
+		}
+		return nil
+	}
+
+	switch toDecode.Type {
+	case ObjectTypeAttestationData:
+		data := &phase0.AttestationData{}
+		if err := unmarshal(data); err != nil {
+			return err
+		}
+		sr.Object = &models.SignRequestAttestationData{AttestationData: data}
+	case ObjectTypeBlock:
+		version, err := parseDataVersion(toDecode.Version)
+		if err != nil {
+			return err
+		}
+		data := &spec.VersionedBeaconBlock{Version: version}
+		switch version {
+		case spec.DataVersionPhase0:
+			data.Phase0 = &phase0.BeaconBlock{}
+			err = unmarshal(data.Phase0)
+		case spec.DataVersionAltair:
+			data.Altair = &altair.BeaconBlock{}
+			err = unmarshal(data.Altair)
+		case spec.DataVersionBellatrix:
+			data.Bellatrix = &bellatrix.BeaconBlock{}
+			err = unmarshal(data.Bellatrix)
+		case spec.DataVersionCapella:
+			data.Capella = &capella.BeaconBlock{}
+			err = unmarshal(data.Capella)
+		case spec.DataVersionDeneb:
+			data.Deneb = &deneb.BeaconBlock{}
+			err = unmarshal(data.Deneb)
+		case spec.DataVersionElectra:
+			data.Electra = &electra.BeaconBlock{}
+			err = unmarshal(data.Electra)
+		default:
+			return errors.Errorf("unsupported block version %s", toDecode.Version)
+		}
+		if err != nil {
+			return err
+		}
+		sr.Object = &models.SignRequestBlock{VersionedBeaconBlock: data}
+	case ObjectTypeBlindedBlock:
+		version, err := parseDataVersion(toDecode.Version)
+		if err != nil {
+			return err
+		}
+		data := &api.VersionedBlindedBeaconBlock{Version: version}
+		switch version {
+		case spec.DataVersionBellatrix:
+			data.Bellatrix = &apiv1bellatrix.BlindedBeaconBlock{}
+			err = unmarshal(data.Bellatrix)
+		case spec.DataVersionCapella:
+			data.Capella = &apiv1capella.BlindedBeaconBlock{}
+			err = unmarshal(data.Capella)
+		case spec.DataVersionDeneb:
+			data.Deneb = &apiv1deneb.BlindedBeaconBlock{}
+			err = unmarshal(data.Deneb)
+		case spec.DataVersionElectra:
+			data.Electra = &apiv1electra.BlindedBeaconBlock{}
+			err = unmarshal(data.Electra)
+		default:
+			return errors.Errorf("unsupported blinded block version %s", toDecode.Version)
+		}
+		if err != nil {
+			return err
+		}
+		sr.Object = &models.SignRequestBlindedBlock{VersionedBlindedBeaconBlock: data}
+	case ObjectTypeBlockHeader:
+		data := &phase0.BeaconBlockHeader{}
+		if err := unmarshal(data); err != nil {
+			return err
+		}
+		sr.Object = &models.SignRequestBlockHeader{BeaconBlockHeader: data}
+	case ObjectTypeAggregateAndProof:
+		// An aggregate without a version is phase0, as in the V2 encoding.
+		version := spec.DataVersionUnknown
+		if toDecode.Version != "" {
+			var err error
+			if version, err = parseDataVersion(toDecode.Version); err != nil {
+				return err
+			}
+		}
+		switch {
+		case version <= spec.DataVersionDeneb:
+			data := &phase0.AggregateAndProof{}
+			if err := unmarshal(data); err != nil {
+				return err
+			}
+			sr.Object = &models.SignRequestAggregateAttestationAndProof{Version: version, AggregateAttestationAndProof: data}
+		case version == spec.DataVersionElectra:
+			data := &electra.AggregateAndProof{}
+			if err := unmarshal(data); err != nil {
+				return err
+			}
+			sr.Object = &models.SignRequestAggregateAttestationAndProof{Version: version, ElectraAggregateAndProof: data}
+		default:
+			return errors.Errorf("unsupported aggregate and proof version %s", toDecode.Version)
+		}
+	case ObjectTypeSlot:
+		slot, err := unmarshalUint64String(toDecode.Object)
+		if err != nil {
+			return errors.Wrap(err, "failed to unmarshal slot")
+		}
+		sr.Object = &models.SignRequestSlot{Slot: phase0.Slot(slot)}
+	case ObjectTypeEpoch:
+		epoch, err := unmarshalUint64String(toDecode.Object)
+		if err != nil {
+			return errors.Wrap(err, "failed to unmarshal epoch")
+		}
+		sr.Object = &models.SignRequestEpoch{Epoch: phase0.Epoch(epoch)}
+	case ObjectTypeSyncCommitteeMessage:
+		var root hexutil.Bytes
+		if err := unmarshal(&root); err != nil {
+			return err
+		}
+		sr.Object = &models.SignRequestSyncCommitteeMessage{Root: models.SSZBytes(root)}
+	case ObjectTypeSyncAggregatorSelectionData:
+		data := &altair.SyncAggregatorSelectionData{}
+		if err := unmarshal(data); err != nil {
+			return err
+		}
+		sr.Object = &models.SignRequestSyncAggregatorSelectionData{SyncAggregatorSelectionData: data}
+	case ObjectTypeContributionAndProof:
+		data := &altair.ContributionAndProof{}
+		if err := unmarshal(data); err != nil {
+			return err
+		}
+		sr.Object = &models.SignRequestContributionAndProof{ContributionAndProof: data}
+	case ObjectTypeValidatorRegistration:
+		var version spec.BuilderVersion
+		if err := version.UnmarshalJSON([]byte(strconv.Quote(toDecode.Version))); err != nil {
+			return errors.Errorf("unsupported registration version %s", toDecode.Version)
+		}
+		reg := &eth2apiv1.ValidatorRegistration{}
+		if err := unmarshal(reg); err != nil {
+			return err
+		}
+		sr.Object = &models.SignRequestRegistration{
+			VersionedValidatorRegistration: &api.VersionedValidatorRegistration{Version: version, V1: reg},
+		}
+	case ObjectTypeVoluntaryExit:
+		data := &phase0.VoluntaryExit{}
+		if err := unmarshal(data); err != nil {
+			return err
+		}
+		sr.Object = &models.SignRequestVoluntaryExit{VoluntaryExit: data}
+	case ObjectTypeBLSToExecutionChange:
+		data := &capella.BLSToExecutionChange{}
+		if err := unmarshal(data); err != nil {
+			return err
+		}
+		sr.Object = &models.SignRequestBLSToExecutionChange{BLSToExecutionChange: data}
+	default:
+		return errors.New("sign request unknown object type")
+	}
+	return nil
+}
+
+// parseDataVersion parses the given fork name, e.g. "deneb".
+func parseDataVersion(version string) (spec.DataVersion, error) {
+	var v spec.DataVersion
+	if err := v.UnmarshalJSON([]byte(strconv.Quote(version))); err != nil {
+		return spec.DataVersionUnknown, errors.Errorf("unsupported version %q", version)
+	}
+	return v, nil
+}
+
+// unmarshalUint64String unmarshals a decimal string, e.g. "123".
+func unmarshalUint64String(data []byte) (uint64, error) {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
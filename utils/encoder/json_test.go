@@ -0,0 +1,164 @@
+package encoder
+
+import (
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/electra"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/prysmaticlabs/go-bitfield"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bloxapp/key-vault/keymanager/models"
+)
+
+func TestJSON(t *testing.T) {
+	pubKey := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 1}
+
+	roundTrip := func(t *testing.T, obj models.ISignObject) *models.SignRequest {
+		req := &models.SignRequest{
+			PublicKey:       pubKey,
+			SigningRoot:     make([]byte, 32),
+			SignatureDomain: [32]byte{1, 2, 3},
+			Object:          obj,
+		}
+
+		enc := NewJSON()
+		byts, err := enc.Encode(req)
+		require.NoError(t, err)
+
+		decoded := &models.SignRequest{}
+		require.NoError(t, enc.Decode(byts, decoded))
+		require.EqualValues(t, req.PublicKey, decoded.PublicKey)
+		require.EqualValues(t, req.SigningRoot, decoded.SigningRoot)
+		require.EqualValues(t, req.SignatureDomain, decoded.SignatureDomain)
+		return decoded
+	}
+
+	t.Run("attestation data", func(t *testing.T) {
+		attestationDataByts := _byteArray("000000000000000000000000000000003a43a4bf26fb5947e809c1f24f7dc6857c8ac007e535d48e6e4eca2122fd776b0000000000000000000000000000000000000000000000000000000000000000000000000000000002000000000000003a43a4bf26fb5947e809c1f24f7dc6857c8ac007e535d48e6e4eca2122fd776b")
+		attData := &phase0.AttestationData{}
+		require.NoError(t, attData.UnmarshalSSZ(attestationDataByts))
+
+		decoded := roundTrip(t, &models.SignRequestAttestationData{AttestationData: attData})
+		require.EqualValues(t, attData, decoded.GetAttestationData())
+	})
+	t.Run("beacon block altair", func(t *testing.T) {
+		blkByts := _byteArray("01000000000000001c00000000000000df7140ad4f8e394cab798d89fa7612a284de78aa004f6db9387a4269a4a0669c83387dd0abb441a3c16886c8144098cb4cac5e363516f329c368550094fd7ff754000000b1e2f27dfac80e4f1bce84adf11acf6cdbb0d8e59a575c9795020e614eb3aa29634108c0559c04ce02b93fc9a5a8daf60485ebac039864c79d51bef54915aa8c45cbcde3215f14962be196a6b8648851c35b4a804ce8d5fb6c5ff49800ef7740685b310217aa8ed11d15f0ac1df629f3dc95b9e0e8fc550025cb18ae36f8fb732000000000000000685b310217aa8ed11d15f0ac1df629f3dc95b9e0e8fc550025cb18ae36f8fb7300000000000000000000000000000000000000000000000000000000000000007c0100007c0100007c0100006502000065020000ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffa2ec291dd5e91096ae48b3659a7ac59567a48c030bb6ac9435d6d44ef39f3f664742f35b38cd6e41ade9ed417183cc0c0b407dfea8627ccc2275fc82ab3d2182e58a037eb144811d741d18894698396efde2b7873c2db9b712e03dfcd03705ef04000000e400000000000000000000000000000000000000df7140ad4f8e394cab798d89fa7612a284de78aa004f6db9387a4269a4a0669c000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000df7140ad4f8e394cab798d89fa7612a284de78aa004f6db9387a4269a4a0669cb62ce3f28e8731dce73d5761fdc5e30383d42a022d6e939974d0586d82270f79b38b86d17237e4241a761e239c594e7a0d4ef731470001be3b125ba515f8f215f9309a9ba12653bf9d704a4125865b9775c8a65223e3ca027781175200a2d24403")
+		blk := &altair.BeaconBlock{}
+		require.NoError(t, blk.UnmarshalSSZ(blkByts))
+
+		decoded := roundTrip(t, &models.SignRequestBlock{VersionedBeaconBlock: &spec.VersionedBeaconBlock{
+			Version: spec.DataVersionAltair,
+			Altair:  blk,
+		}})
+		require.Equal(t, spec.DataVersionAltair, decoded.GetBlock().Version)
+		byts, err := decoded.GetBlock().Altair.MarshalSSZ()
+		require.NoError(t, err)
+		require.EqualValues(t, blkByts, byts)
+	})
+	t.Run("aggregate and proof", func(t *testing.T) {
+		agg := &phase0.AggregateAndProof{
+			AggregatorIndex: 12,
+			Aggregate: &phase0.Attestation{
+				AggregationBits: bitfield.NewBitlist(12),
+				Data: &phase0.AttestationData{
+					Slot:   phase0.Slot(7415520),
+					Source: &phase0.Checkpoint{},
+					Target: &phase0.Checkpoint{},
+				},
+			},
+		}
+
+		decoded := roundTrip(t, &models.SignRequestAggregateAttestationAndProof{AggregateAttestationAndProof: agg})
+		require.Equal(t, spec.DataVersionUnknown, decoded.GetObject().(*models.SignRequestAggregateAttestationAndProof).Version)
+		require.EqualValues(t, agg, decoded.GetAggregateAttestationAndProof())
+
+		decoded = roundTrip(t, &models.SignRequestAggregateAttestationAndProof{Version: spec.DataVersionDeneb, AggregateAttestationAndProof: agg})
+		require.Equal(t, spec.DataVersionDeneb, decoded.GetObject().(*models.SignRequestAggregateAttestationAndProof).Version)
+	})
+	t.Run("aggregate and proof electra", func(t *testing.T) {
+		committeeBits := bitfield.NewBitvector64()
+		committeeBits.SetBitAt(5, true)
+		agg := &electra.AggregateAndProof{
+			AggregatorIndex: 12,
+			Aggregate: &electra.Attestation{
+				AggregationBits: bitfield.NewBitlist(12),
+				Data: &phase0.AttestationData{
+					Slot:   phase0.Slot(7415520),
+					Source: &phase0.Checkpoint{},
+					Target: &phase0.Checkpoint{},
+				},
+				CommitteeBits: committeeBits,
+			},
+		}
+
+		decoded := roundTrip(t, &models.SignRequestAggregateAttestationAndProof{Version: spec.DataVersionElectra, ElectraAggregateAndProof: agg})
+		require.Equal(t, spec.DataVersionElectra, decoded.GetObject().(*models.SignRequestAggregateAttestationAndProof).Version)
+		require.EqualValues(t, agg, decoded.GetObject().(*models.SignRequestAggregateAttestationAndProof).ElectraAggregateAndProof)
+	})
+	t.Run("slot", func(t *testing.T) {
+		decoded := roundTrip(t, &models.SignRequestSlot{Slot: 123})
+		require.EqualValues(t, 123, decoded.GetSlot())
+	})
+	t.Run("epoch", func(t *testing.T) {
+		decoded := roundTrip(t, &models.SignRequestEpoch{Epoch: 123})
+		require.EqualValues(t, 123, decoded.GetEpoch())
+	})
+	t.Run("sync committee", func(t *testing.T) {
+		decoded := roundTrip(t, &models.SignRequestSyncCommitteeMessage{Root: pubKey})
+		require.EqualValues(t, pubKey, decoded.GetSyncCommitteeMessage())
+	})
+	t.Run("voluntary exit", func(t *testing.T) {
+		exit := &phase0.VoluntaryExit{Epoch: 1, ValidatorIndex: 2}
+		decoded := roundTrip(t, &models.SignRequestVoluntaryExit{VoluntaryExit: exit})
+		require.EqualValues(t, exit, decoded.GetVoluntaryExit())
+	})
+	t.Run("bls to execution change", func(t *testing.T) {
+		change := &capella.BLSToExecutionChange{ValidatorIndex: 1, FromBLSPubkey: phase0.BLSPubKey{1, 2, 3}}
+		decoded := roundTrip(t, &models.SignRequestBLSToExecutionChange{BLSToExecutionChange: change})
+		require.EqualValues(t, change, decoded.GetBLSToExecutionChange())
+	})
+	t.Run("human readable request", func(t *testing.T) {
+		data := []byte(`{
+			"public_key": "0x0102",
+			"signature_domain": "0x0100000000000000000000000000000000000000000000000000000000000000",
+			"type": "attestation_data",
+			"object": {
+				"slot": "1",
+				"index": "2",
+				"beacon_block_root": "0x0000000000000000000000000000000000000000000000000000000000000000",
+				"source": {"epoch": "0", "root": "0x0000000000000000000000000000000000000000000000000000000000000000"},
+				"target": {"epoch": "1", "root": "0x0000000000000000000000000000000000000000000000000000000000000000"}
+			}
+		}`)
+
+		decoded := &models.SignRequest{}
+		require.NoError(t, NewJSON().Decode(data, decoded))
+		require.EqualValues(t, []byte{1, 2}, decoded.PublicKey)
+		require.EqualValues(t, 1, decoded.GetAttestationData().Slot)
+		require.EqualValues(t, 1, decoded.GetAttestationData().Target.Epoch)
+	})
+	t.Run("block without versioned block", func(t *testing.T) {
+		_, err := NewJSON().Encode(&models.SignRequest{Object: &models.SignRequestBlock{}})
+		require.EqualError(t, err, "no block")
+	})
+	t.Run("blinded block without versioned blinded block", func(t *testing.T) {
+		_, err := NewJSON().Encode(&models.SignRequest{Object: &models.SignRequestBlindedBlock{}})
+		require.EqualError(t, err, "no blinded block")
+	})
+	t.Run("registration without versioned registration", func(t *testing.T) {
+		_, err := NewJSON().Encode(&models.SignRequest{Object: &models.SignRequestRegistration{}})
+		require.EqualError(t, err, "no validator registration")
+	})
+	t.Run("unknown object type", func(t *testing.T) {
+		decoded := &models.SignRequest{}
+		require.EqualError(t, NewJSON().Decode([]byte(`{"type":"unknown","object":{}}`), decoded), "sign request unknown object type")
+	})
+	t.Run("unsupported block version", func(t *testing.T) {
+		decoded := &models.SignRequest{}
+		require.EqualError(t, NewJSON().Decode([]byte(`{"type":"block","version":"fulu","object":{}}`), decoded), `unsupported version "fulu"`)
+	})
+}
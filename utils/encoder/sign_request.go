@@ -9,11 +9,13 @@ import (
 	apiv1bellatrix "github.com/attestantio/go-eth2-client/api/v1/bellatrix"
 	apiv1capella "github.com/attestantio/go-eth2-client/api/v1/capella"
 	apiv1deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	apiv1electra "github.com/attestantio/go-eth2-client/api/v1/electra"
 	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/altair"
 	"github.com/attestantio/go-eth2-client/spec/bellatrix"
 	"github.com/attestantio/go-eth2-client/spec/capella"
 	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/electra"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	ssz "github.com/ferranbt/fastssz"
 	"github.com/pkg/errors"
@@ -27,7 +29,7 @@ type signRequestEncoded struct {
 	SignatureDomain [32]byte `json:"signature_domain,omitempty"`
 	Data            []byte
 	ObjectType      string
-	// Used for block/registration versioning (altair, etc.)
+	// Used for block/aggregate/registration versioning (altair, etc.)
 	Version uint64
 }
 
@@ -79,6 +81,11 @@ func encodeSignRequest(sr *models.SignRequest) ([]byte, error) {
 				return nil, errors.New("no deneb block")
 			}
 			byts, err = t.VersionedBeaconBlock.Deneb.MarshalSSZ()
+		case spec.DataVersionElectra:
+			if t.VersionedBeaconBlock.Electra == nil {
+				return nil, errors.New("no electra block")
+			}
+			byts, err = t.VersionedBeaconBlock.Electra.MarshalSSZ()
 		default:
 			return nil, errors.Errorf("unsupported block version %d", t.VersionedBeaconBlock.Version)
 		}
@@ -108,6 +115,11 @@ func encodeSignRequest(sr *models.SignRequest) ([]byte, error) {
 				return nil, errors.New("no deneb blinded block")
 			}
 			byts, err = t.VersionedBlindedBeaconBlock.Deneb.MarshalSSZ()
+		case spec.DataVersionElectra:
+			if t.VersionedBlindedBeaconBlock.Electra == nil {
+				return nil, errors.New("no electra blinded block")
+			}
+			byts, err = t.VersionedBlindedBeaconBlock.Electra.MarshalSSZ()
 		default:
 			return nil, errors.Errorf("unsupported blinded block version %d", t.VersionedBlindedBeaconBlock.Version)
 		}
@@ -118,18 +130,37 @@ func encodeSignRequest(sr *models.SignRequest) ([]byte, error) {
 		toEncode.Data = byts
 		toEncode.ObjectType = reflect.TypeOf(t).String()
 		toEncode.Version = uint64(t.VersionedBlindedBeaconBlock.Version)
-	case *models.SignRequestAggregateAttestationAndProof:
-		byts, err := t.AggregateAttestationAndProof.MarshalSSZ()
+	case *models.SignRequestBlockHeader:
+		byts, err := t.BeaconBlockHeader.MarshalSSZ()
 		if err != nil {
 			return nil, err
 		}
 		toEncode.Data = byts
 		toEncode.ObjectType = reflect.TypeOf(t).String()
+	case *models.SignRequestAggregateAttestationAndProof:
+		var byts []byte
+		var err error
+		switch {
+		case t.Version <= spec.DataVersionDeneb:
+			if t.AggregateAttestationAndProof == nil {
+				return nil, errors.New("no aggregate and proof")
+			}
+			byts, err = t.AggregateAttestationAndProof.MarshalSSZ()
+		case t.Version == spec.DataVersionElectra:
+			if t.ElectraAggregateAndProof == nil {
+				return nil, errors.New("no electra aggregate and proof")
+			}
+			byts, err = t.ElectraAggregateAndProof.MarshalSSZ()
+		default:
+			return nil, errors.Errorf("unsupported aggregate and proof version %d", t.Version)
+		}
 
-		ddd := &phase0.AggregateAndProof{}
-		if err := ddd.UnmarshalSSZ(byts); err != nil {
-			return nil, err
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal aggregate and proof")
 		}
+		toEncode.Data = byts
+		toEncode.ObjectType = reflect.TypeOf(t).String()
+		toEncode.Version = uint64(t.Version)
 	case *models.SignRequestEpoch:
 		var byts []byte
 		toEncode.Data = ssz.MarshalUint64(byts, uint64(t.Epoch))
@@ -181,6 +212,13 @@ func encodeSignRequest(sr *models.SignRequest) ([]byte, error) {
 		}
 		toEncode.Data = byts
 		toEncode.ObjectType = reflect.TypeOf(t).String()
+	case *models.SignRequestBLSToExecutionChange:
+		byts, err := t.BLSToExecutionChange.MarshalSSZ()
+		if err != nil {
+			return nil, err
+		}
+		toEncode.Data = byts
+		toEncode.ObjectType = reflect.TypeOf(t).String()
 	default:
 		return nil, errors.New("sign request unknown object type")
 	}
@@ -248,6 +286,13 @@ func decodeSignRequest(data []byte, sr *models.SignRequest) error {
 			}
 			data.Version = spec.DataVersionDeneb
 			data.Deneb = &blk
+		case spec.DataVersionElectra:
+			var blk electra.BeaconBlock
+			if err := blk.UnmarshalSSZ(toDecode.Data); err != nil {
+				return err
+			}
+			data.Version = spec.DataVersionElectra
+			data.Electra = &blk
 		default:
 			return errors.Errorf("unsupported block version %d", toDecode.Version)
 		}
@@ -278,11 +323,24 @@ func decodeSignRequest(data []byte, sr *models.SignRequest) error {
 			}
 			data.Version = spec.DataVersionDeneb
 			data.Deneb = &blk
+		case spec.DataVersionElectra:
+			var blk apiv1electra.BlindedBeaconBlock
+			if err := blk.UnmarshalSSZ(toDecode.Data); err != nil {
+				return err
+			}
+			data.Version = spec.DataVersionElectra
+			data.Electra = &blk
 		default:
 			return errors.Errorf("unsupported blinded block version %d", toDecode.Version)
 		}
 
 		sr.Object = &models.SignRequestBlindedBlock{VersionedBlindedBeaconBlock: data}
+	case "*models.SignRequestBlockHeader":
+		data := &phase0.BeaconBlockHeader{}
+		if err := data.UnmarshalSSZ(toDecode.Data); err != nil {
+			return err
+		}
+		sr.Object = &models.SignRequestBlockHeader{BeaconBlockHeader: data}
 	case "*models.SignRequestSlot":
 		slot := ssz.UnmarshallUint64(toDecode.Data)
 		sr.Object = &models.SignRequestSlot{Slot: phase0.Slot(slot)}
@@ -290,11 +348,24 @@ func decodeSignRequest(data []byte, sr *models.SignRequest) error {
 		epoch := ssz.UnmarshallUint64(toDecode.Data)
 		sr.Object = &models.SignRequestEpoch{Epoch: phase0.Epoch(epoch)}
 	case "*models.SignRequestAggregateAttestationAndProof":
-		data := &phase0.AggregateAndProof{}
-		if err := data.UnmarshalSSZ(toDecode.Data); err != nil {
-			return err
+		// Requests encoded before versioning have an unknown version, which is phase0.
+		version := spec.DataVersion(toDecode.Version)
+		switch {
+		case version <= spec.DataVersionDeneb:
+			data := &phase0.AggregateAndProof{}
+			if err := data.UnmarshalSSZ(toDecode.Data); err != nil {
+				return err
+			}
+			sr.Object = &models.SignRequestAggregateAttestationAndProof{Version: version, AggregateAttestationAndProof: data}
+		case version == spec.DataVersionElectra:
+			data := &electra.AggregateAndProof{}
+			if err := data.UnmarshalSSZ(toDecode.Data); err != nil {
+				return err
+			}
+			sr.Object = &models.SignRequestAggregateAttestationAndProof{Version: version, ElectraAggregateAndProof: data}
+		default:
+			return errors.Errorf("unsupported aggregate and proof version %d", toDecode.Version)
 		}
-		sr.Object = &models.SignRequestAggregateAttestationAndProof{AggregateAttestationAndProof: data}
 	case "*models.SignRequestSyncCommitteeMessage":
 		sr.Object = &models.SignRequestSyncCommitteeMessage{Root: toDecode.Data}
 	case "*models.SignRequestSyncAggregatorSelectionData":
@@ -331,6 +402,12 @@ func decodeSignRequest(data []byte, sr *models.SignRequest) error {
 			return err
 		}
 		sr.Object = &models.SignRequestVoluntaryExit{VoluntaryExit: data}
+	case "*models.SignRequestBLSToExecutionChange":
+		data := &capella.BLSToExecutionChange{}
+		if err := data.UnmarshalSSZ(toDecode.Data); err != nil {
+			return err
+		}
+		sr.Object = &models.SignRequestBLSToExecutionChange{BLSToExecutionChange: data}
 	default:
 		return errors.New("sign request unknown object type")
 	}
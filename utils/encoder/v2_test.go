@@ -10,11 +10,13 @@ import (
 	apiv1bellatrix "github.com/attestantio/go-eth2-client/api/v1/bellatrix"
 	apiv1capella "github.com/attestantio/go-eth2-client/api/v1/capella"
 	apiv1deneb "github.com/attestantio/go-eth2-client/api/v1/deneb"
+	apiv1electra "github.com/attestantio/go-eth2-client/api/v1/electra"
 	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/altair"
 	"github.com/attestantio/go-eth2-client/spec/bellatrix"
 	"github.com/attestantio/go-eth2-client/spec/capella"
 	"github.com/attestantio/go-eth2-client/spec/deneb"
+	"github.com/attestantio/go-eth2-client/spec/electra"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/prysmaticlabs/go-bitfield"
 	"github.com/stretchr/testify/require"
@@ -273,6 +275,92 @@ func TestV2(t *testing.T) {
 		require.EqualValues(t, sszBytes, byts)
 		require.EqualValues(t, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 1}, decoded.PublicKey)
 	})
+	// The deneb blocks above as electra ones, with one attestation of committee bits and an execution request.
+	t.Run("beacon block electra", func(t *testing.T) {
+		blk := &electra.BeaconBlock{}
+		blkByts := []byte(`{"slot":"7415521","proposer_index":"436065","parent_root":"0x4552aa96870d18a017f3fe5bf4a1ff449a4e9e4d5f5300fc0a3624f827208a63","state_root":"0x885ab98a87391ee3cf7618a6632a318d219623c217dea639012bf2746751a463","body":{"randao_reveal":"0x8692a273fd4d42a9d98b3c38d879dd1ebaf1d420fb8258683d3b2411e161a1aaf0eecc08a71ccb6e2d1c14b5f6be9e3308c85e9273d9ef3aac790ce2cf8028353dcb7de5fd2bd596b11e5060a897c5fa3e5e8e290d6cbf5269fdc8087d9770ea","eth1_data":{"deposit_root":"0x6d0f7b2924eb06428d1a9302e05b553c65827a0b5858166f73a8c90d73986532","deposit_count":"476588","block_hash":"0x17fce7d46a95a1993045de1276c96089e830e2c02cf5c62f0b86fafa8f04623b"},"graffiti":"0x7373762e6e6574776f726b000000000000000000000000000000000000000000","proposer_slashings":[],"attester_slashings":[],"attestations":[{"aggregation_bits":"0x5fffeffed577fed7fbf7fdf7fbdeffdfbfffffdd7f71fcdf7f6fe9defbff1fee73fd0d","data":{"slot":"7415520","index":"0","beacon_block_root":"0x4552aa96870d18a017f3fe5bf4a1ff449a4e9e4d5f5300fc0a3624f827208a63","source":{"epoch":"231734","root":"0xbf0ea6199d279e3c0efa378a888cf08aa8f5b53eaaf76cae3d539dc538a8fa54"},"target":{"epoch":"231735","root":"0x4552aa96870d18a017f3fe5bf4a1ff449a4e9e4d5f5300fc0a3624f827208a63"}},"signature":"0x822fe3eb8be494503eb49c6bb2ae9da4fbdd141bf27209731b8a4bb07fd678020d40a53eca07170e092f0d727c1c964013e430463131b78abf712810fdde1b90720b98f15dafe5378fba047fa002226019f710a2501dc7f3840531b028ef390e","committee_bits":"0x0000000040000000"}],"deposits":[],"voluntary_exits":[],"sync_aggregate":{"sync_committee_bits":"0x335fff77bfffb9bff67dff67ffcee77cf3fff679fedfdcfbf2effbef972ebff9b5e1be9fbebaffdde3feef4fbcafecffbeaee3eee9eff8afff3b75bbf77e7df7","sync_committee_signature":"0x923ba7e9f92426f8d3b0206f27ab11bf4d0554286db22c421bd66b4d342404b5c99f6b3fae425c0b652909f131db2db905e5615a103c1c4cb74f1316950fb5a26b751022dce7e9db6f21df74dce008b3158d467ef4c6160f94c5b8818d3fe9fb"},"execution_payload":{"parent_hash":"0x1b6059447ea578dad10192b55b3856a25aff1abd942024822a54779784760429","fee_recipient":"0x6De04119406BAFbfE88dA89267AE7CbE6a4a4bc5","state_root":"0x343fced1f1273752f36555b1959522365d8bebf2de02b6f3aa4e1bf924a7a4f2","receipts_root":"0xe2e5df441ee342a5c3bc4ed0e3bca1114cfd5cf4640abde0bd9a49f58527bfce","logs_bloom":"0x00311810281518010004820481014904002410001810001041014104884659a0521c68020a003020501239c1042c201826d28310981872024090c71256a430120092006a040e004c5000040f802a812f520a414231048095841896049128006842860402df240880031800a02800282319981c006084644018005011400816140824420800000002254036258b001309a0f4d30b103500e80005904015020618460806b43430113850d8484a48080401061b4822510000a06103223a4418060bd201c302110020088117285400144a0024c10400358c401009b00410001c6a80093020080808004d0010000820495202200018002a880040a9081849d2040192","prev_randao":"0xc5647b9f7b3ca7b07b86365095fe47f84ca193c009d91182f9d254b2e9bd59ae","block_number":"10389257","gas_limit":"30000000","gas_used":"8753279","timestamp":"1705494252","extra_data":"0xd883010d0a846765746888676f312e32312e36856c696e7578","base_fee_per_gas":"9","block_hash":"0x202d5d304bf17fec211cd13186fb766dbe610c12574d4c9eadd7e6fdae9ee050","transactions":["0xf86f820881850ba7cefb00830493e094ddd81c0f3efc825f43aa6907a2b82d9cbf171f0e888ac7230489e80000801ca065283cba7cc15150bd1051cb14e7c5cc458dea602509b7e2c680fffae9eaf83fa03e5caa0bba3f6e3fc162b435c70bd3ecfcdd95e51cc2112201b3caad82fd64ec"],"withdrawals":[{"index":"27687781","validator_index":"429428","address":"0x59b0d71688da01057c08e4c1baa8faa629819c2a","amount":"3409848"},{"index":"27687782","validator_index":"429429","address":"0x59b0d71688da01057c08e4c1baa8faa629819c2a","amount":"3492896"},{"index":"27687783","validator_index":"429430","address":"0x59b0d71688da01057c08e4c1baa8faa629819c2a","amount":"3449838"},{"index":"27687784","validator_index":"429431","address":"0x59b0d71688da01057c08e4c1baa8faa629819c2a","amount":"3419633"},{"index":"27687785","validator_index":"429432","address":"0x59b0d71688da01057c08e4c1baa8faa629819c2a","amount":"3420553"},{"index":"27687786","validator_index":"429433","address":"0x59b0d71688da01057c08e4c1baa8faa629819c2a","amount":"3410740"},{"index":"27687787","validator_index":"429434","address":"0x59b0d71688da01057c08e4c1baa8faa629819c2a","amount":"3426871"},{"index":"27687788","validator_index":"429435","address":"0x59b0d71688da01057c08e4c1baa8faa629819c2a","amount":"3393446"},{"index":"27687789","validator_index":"429436","address":"0x59b0d71688da01057c08e4c1baa8faa629819c2a","amount":"3449655"},{"index":"27687790","validator_index":"429437","address":"0x59b0d71688da01057c08e4c1baa8faa629819c2a","amount":"3424244"},{"index":"27687791","validator_index":"429438","address":"0x59b0d71688da01057c08e4c1baa8faa629819c2a","amount":"3347382"},{"index":"27687792","validator_index":"429439","address":"0x59b0d71688da01057c08e4c1baa8faa629819c2a","amount":"3415891"},{"index":"27687793","validator_index":"429440","address":"0x59b0d71688da01057c08e4c1baa8faa629819c2a","amount":"3471404"},{"index":"27687794","validator_index":"429441","address":"0x59b0d71688da01057c08e4c1baa8faa629819c2a","amount":"3425587"},{"index":"27687795","validator_index":"429442","address":"0x59b0d71688da01057c08e4c1baa8faa629819c2a","amount":"3455195"},{"index":"27687796","validator_index":"429443","address":"0x59b0d71688da01057c08e4c1baa8faa629819c2a","amount":"3395728"}],"blob_gas_used":"655360","excess_blob_gas":"86507520"},"bls_to_execution_changes":[],"blob_kzg_commitments":["0xc00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","0xb24758fbdea9852ae96086be16e5634c18a51acc31c9b2b97f7e330bb41065987f73376073c3e1d28f11ccb2d2fc03c2","0xa45a58d23333471a49032949ac925593f2958977c000b0da114092e79910981550fbca5f57c9d9c9aa1e8a90ac0232cf","0x852b25e16fef449d320869f8984f6bda8a04f15a9e6d6cb0fc35c16c8c9e03c377bc387bbca75af799d739cd229672af","0x99943f758bb009192d7dce3d6172e9d466367ac717c6ecab30bd5cb98badf8c1290a8a0529d56f3812f97504def2d970"],"execution_requests":{"deposits":null,"withdrawals":[{"source_address":"0x0100000000000000000000000000000000000000","validator_pubkey":"0x020000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","amount":"3"}],"consolidations":null}}}`)
+		require.NoError(t, json.Unmarshal(blkByts, blk))
+
+		sszBytes, err := blk.MarshalSSZ()
+		require.NoError(t, err)
+
+		req := &models.SignRequest{
+			PublicKey:       []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 1},
+			SigningRoot:     make([]byte, 32),
+			SignatureDomain: [32]byte{},
+			Object: &models.SignRequestBlock{VersionedBeaconBlock: &spec.VersionedBeaconBlock{
+				Version: spec.DataVersionElectra,
+				Electra: blk,
+			}},
+		}
+
+		enc := New()
+		byts, err := enc.Encode(req)
+		require.NoError(t, err)
+
+		decoded := &models.SignRequest{}
+		require.NoError(t, enc.Decode(byts, decoded))
+		require.Equal(t, spec.DataVersionElectra, decoded.GetBlock().Version)
+		byts, err = decoded.GetBlock().Electra.MarshalSSZ()
+		require.NoError(t, err)
+		require.EqualValues(t, sszBytes, byts)
+		require.EqualValues(t, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 1}, decoded.PublicKey)
+	})
+	t.Run("blinded beacon block electra", func(t *testing.T) {
+		blk := &apiv1electra.BlindedBeaconBlock{}
+		blkByts := []byte(`{"slot":"7415521","proposer_index":"436065","parent_root":"0x4552aa96870d18a017f3fe5bf4a1ff449a4e9e4d5f5300fc0a3624f827208a63","state_root":"0x885ab98a87391ee3cf7618a6632a318d219623c217dea639012bf2746751a463","body":{"randao_reveal":"0x8692a273fd4d42a9d98b3c38d879dd1ebaf1d420fb8258683d3b2411e161a1aaf0eecc08a71ccb6e2d1c14b5f6be9e3308c85e9273d9ef3aac790ce2cf8028353dcb7de5fd2bd596b11e5060a897c5fa3e5e8e290d6cbf5269fdc8087d9770ea","eth1_data":{"deposit_root":"0x6d0f7b2924eb06428d1a9302e05b553c65827a0b5858166f73a8c90d73986532","deposit_count":"476588","block_hash":"0x17fce7d46a95a1993045de1276c96089e830e2c02cf5c62f0b86fafa8f04623b"},"graffiti":"0x7373762e6e6574776f726b000000000000000000000000000000000000000000","proposer_slashings":[],"attester_slashings":[],"attestations":[{"aggregation_bits":"0x5fffeffed577fed7fbf7fdf7fbdeffdfbfffffdd7f71fcdf7f6fe9defbff1fee73fd0d","data":{"slot":"7415520","index":"0","beacon_block_root":"0x4552aa96870d18a017f3fe5bf4a1ff449a4e9e4d5f5300fc0a3624f827208a63","source":{"epoch":"231734","root":"0xbf0ea6199d279e3c0efa378a888cf08aa8f5b53eaaf76cae3d539dc538a8fa54"},"target":{"epoch":"231735","root":"0x4552aa96870d18a017f3fe5bf4a1ff449a4e9e4d5f5300fc0a3624f827208a63"}},"signature":"0x822fe3eb8be494503eb49c6bb2ae9da4fbdd141bf27209731b8a4bb07fd678020d40a53eca07170e092f0d727c1c964013e430463131b78abf712810fdde1b90720b98f15dafe5378fba047fa002226019f710a2501dc7f3840531b028ef390e","committee_bits":"0x0000000040000000"}],"deposits":[],"voluntary_exits":[],"sync_aggregate":{"sync_committee_bits":"0x335fff77bfffb9bff67dff67ffcee77cf3fff679fedfdcfbf2effbef972ebff9b5e1be9fbebaffdde3feef4fbcafecffbeaee3eee9eff8afff3b75bbf77e7df7","sync_committee_signature":"0x923ba7e9f92426f8d3b0206f27ab11bf4d0554286db22c421bd66b4d342404b5c99f6b3fae425c0b652909f131db2db905e5615a103c1c4cb74f1316950fb5a26b751022dce7e9db6f21df74dce008b3158d467ef4c6160f94c5b8818d3fe9fb"},"execution_payload_header":{"parent_hash":"0x1b6059447ea578dad10192b55b3856a25aff1abd942024822a54779784760429","fee_recipient":"0x6De04119406BAFbfE88dA89267AE7CbE6a4a4bc5","state_root":"0x343fced1f1273752f36555b1959522365d8bebf2de02b6f3aa4e1bf924a7a4f2","receipts_root":"0xe2e5df441ee342a5c3bc4ed0e3bca1114cfd5cf4640abde0bd9a49f58527bfce","logs_bloom":"0x00311810281518010004820481014904002410001810001041014104884659a0521c68020a003020501239c1042c201826d28310981872024090c71256a430120092006a040e004c5000040f802a812f520a414231048095841896049128006842860402df240880031800a02800282319981c006084644018005011400816140824420800000002254036258b001309a0f4d30b103500e80005904015020618460806b43430113850d8484a48080401061b4822510000a06103223a4418060bd201c302110020088117285400144a0024c10400358c401009b00410001c6a80093020080808004d0010000820495202200018002a880040a9081849d2040192","prev_randao":"0xc5647b9f7b3ca7b07b86365095fe47f84ca193c009d91182f9d254b2e9bd59ae","block_number":"10389257","gas_limit":"30000000","gas_used":"8753279","timestamp":"1705494252","extra_data":"0xd883010d0a846765746888676f312e32312e36856c696e7578","base_fee_per_gas":"9","block_hash":"0x202d5d304bf17fec211cd13186fb766dbe610c12574d4c9eadd7e6fdae9ee050","transactions_root":"0x4e2efc021603f6106e875c8d74d5af83103fda595bd5b6cff0f0a4a8935a03d2","withdrawals_root":"0x2457c13873dcd199ffdc4a51f4ae9812d7409418c8e015a7557d8ebceb110fe7","blob_gas_used":"655360","excess_blob_gas":"86507520"},"bls_to_execution_changes":[],"blob_kzg_commitments":["0xc00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","0xb24758fbdea9852ae96086be16e5634c18a51acc31c9b2b97f7e330bb41065987f73376073c3e1d28f11ccb2d2fc03c2","0xa45a58d23333471a49032949ac925593f2958977c000b0da114092e79910981550fbca5f57c9d9c9aa1e8a90ac0232cf","0x852b25e16fef449d320869f8984f6bda8a04f15a9e6d6cb0fc35c16c8c9e03c377bc387bbca75af799d739cd229672af","0x99943f758bb009192d7dce3d6172e9d466367ac717c6ecab30bd5cb98badf8c1290a8a0529d56f3812f97504def2d970"],"execution_requests":{"deposits":null,"withdrawals":[{"source_address":"0x0100000000000000000000000000000000000000","validator_pubkey":"0x020000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","amount":"3"}],"consolidations":null}}}`)
+		require.NoError(t, json.Unmarshal(blkByts, blk))
+
+		sszBytes, err := blk.MarshalSSZ()
+		require.NoError(t, err)
+
+		req := &models.SignRequest{
+			PublicKey:       []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 1},
+			SigningRoot:     make([]byte, 32),
+			SignatureDomain: [32]byte{},
+			Object: &models.SignRequestBlindedBlock{VersionedBlindedBeaconBlock: &api.VersionedBlindedBeaconBlock{
+				Version: spec.DataVersionElectra,
+				Electra: blk,
+			}},
+		}
+
+		enc := New()
+		byts, err := enc.Encode(req)
+		require.NoError(t, err)
+
+		decoded := &models.SignRequest{}
+		require.NoError(t, enc.Decode(byts, decoded))
+		require.Equal(t, spec.DataVersionElectra, decoded.GetBlindedBlock().Version)
+		byts, err = decoded.GetBlindedBlock().Electra.MarshalSSZ()
+		require.NoError(t, err)
+		require.EqualValues(t, sszBytes, byts)
+		require.EqualValues(t, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 1}, decoded.PublicKey)
+	})
+	t.Run("beacon block header", func(t *testing.T) {
+		header := &phase0.BeaconBlockHeader{
+			Slot:          2,
+			ProposerIndex: 3,
+			ParentRoot:    phase0.Root{1, 2, 3},
+			StateRoot:     phase0.Root{4, 5, 6},
+			BodyRoot:      phase0.Root{7, 8, 9},
+		}
+
+		req := &models.SignRequest{
+			PublicKey:       []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 1},
+			SigningRoot:     make([]byte, 32),
+			SignatureDomain: [32]byte{},
+			Object:          &models.SignRequestBlockHeader{BeaconBlockHeader: header},
+		}
+
+		enc := New()
+		byts, err := enc.Encode(req)
+		require.NoError(t, err)
+
+		decoded := &models.SignRequest{}
+		require.NoError(t, enc.Decode(byts, decoded))
+		require.EqualValues(t, header, decoded.GetBlockHeader())
+		require.EqualValues(t, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 1}, decoded.PublicKey)
+	})
 	t.Run("attestation aggregation", func(t *testing.T) {
 		dataByts := _byteArray("01000000000000001c00000000000000df7140ad4f8e394cab798d89fa7612a284de78aa004f6db9387a4269a4a0669c83387dd0abb441a3c16886c8144098cb4cac5e363516f329c368550094fd7ff754000000b1e2f27dfac80e4f1bce84adf11acf6cdbb0d8e59a575c9795020e614eb3aa29634108c0559c04ce02b93fc9a5a8daf60485ebac039864c79d51bef54915aa8c45cbcde3215f14962be196a6b8648851c35b4a804ce8d5fb6c5ff49800ef7740685b310217aa8ed11d15f0ac1df629f3dc95b9e0e8fc550025cb18ae36f8fb732000000000000000685b310217aa8ed11d15f0ac1df629f3dc95b9e0e8fc550025cb18ae36f8fb7300000000000000000000000000000000000000000000000000000000000000007c0100007c0100007c0100006502000065020000ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffa2ec291dd5e91096ae48b3659a7ac59567a48c030bb6ac9435d6d44ef39f3f664742f35b38cd6e41ade9ed417183cc0c0b407dfea8627ccc2275fc82ab3d2182e58a037eb144811d741d18894698396efde2b7873c2db9b712e03dfcd03705ef04000000e400000000000000000000000000000000000000df7140ad4f8e394cab798d89fa7612a284de78aa004f6db9387a4269a4a0669c000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000df7140ad4f8e394cab798d89fa7612a284de78aa004f6db9387a4269a4a0669cb62ce3f28e8731dce73d5761fdc5e30383d42a022d6e939974d0586d82270f79b38b86d17237e4241a761e239c594e7a0d4ef731470001be3b125ba515f8f215f9309a9ba12653bf9d704a4125865b9775c8a65223e3ca027781175200a2d24403")
 		blk := &altair.BeaconBlock{}
@@ -299,6 +387,96 @@ func TestV2(t *testing.T) {
 		require.EqualValues(t, dataByts, byts)
 		require.EqualValues(t, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 1}, decoded.PublicKey)
 	})
+	t.Run("aggregate and proof deneb", func(t *testing.T) {
+		req := &models.SignRequest{
+			PublicKey:       []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 1},
+			SigningRoot:     make([]byte, 32),
+			SignatureDomain: [32]byte{},
+			Object: &models.SignRequestAggregateAttestationAndProof{
+				Version: spec.DataVersionDeneb,
+				AggregateAttestationAndProof: &phase0.AggregateAndProof{
+					AggregatorIndex: 12,
+					Aggregate: &phase0.Attestation{
+						AggregationBits: bitfield.NewBitlist(12),
+						Data: &phase0.AttestationData{
+							Slot:   phase0.Slot(7415520),
+							Source: &phase0.Checkpoint{},
+							Target: &phase0.Checkpoint{},
+						},
+					},
+				},
+			},
+		}
+
+		enc := New()
+		byts, err := enc.Encode(req)
+		require.NoError(t, err)
+
+		decoded := &models.SignRequest{}
+		require.NoError(t, enc.Decode(byts, decoded))
+		require.Equal(t, spec.DataVersionDeneb, decoded.GetObject().(*models.SignRequestAggregateAttestationAndProof).Version)
+		require.EqualValues(t, 12, decoded.GetAggregateAttestationAndProof().AggregatorIndex)
+		require.EqualValues(t, 7415520, decoded.GetAggregateAttestationAndProof().Aggregate.Data.Slot)
+	})
+	t.Run("aggregate and proof electra", func(t *testing.T) {
+		committeeBits := bitfield.NewBitvector64()
+		committeeBits.SetBitAt(5, true)
+		req := &models.SignRequest{
+			PublicKey:       []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 1},
+			SigningRoot:     make([]byte, 32),
+			SignatureDomain: [32]byte{},
+			Object: &models.SignRequestAggregateAttestationAndProof{
+				Version: spec.DataVersionElectra,
+				ElectraAggregateAndProof: &electra.AggregateAndProof{
+					AggregatorIndex: 12,
+					Aggregate: &electra.Attestation{
+						AggregationBits: bitfield.NewBitlist(12),
+						Data: &phase0.AttestationData{
+							Slot:   phase0.Slot(7415520),
+							Source: &phase0.Checkpoint{},
+							Target: &phase0.Checkpoint{},
+						},
+						CommitteeBits: committeeBits,
+					},
+				},
+			},
+		}
+
+		enc := New()
+		byts, err := enc.Encode(req)
+		require.NoError(t, err)
+
+		decoded := &models.SignRequest{}
+		require.NoError(t, enc.Decode(byts, decoded))
+		agg := decoded.GetObject().(*models.SignRequestAggregateAttestationAndProof)
+		require.Equal(t, spec.DataVersionElectra, agg.Version)
+		require.Nil(t, agg.AggregateAttestationAndProof)
+		require.EqualValues(t, 12, agg.ElectraAggregateAndProof.AggregatorIndex)
+		require.EqualValues(t, committeeBits, agg.ElectraAggregateAndProof.Aggregate.CommitteeBits)
+		data, err := agg.AttestationData()
+		require.NoError(t, err)
+		require.EqualValues(t, 7415520, data.Slot)
+	})
+	t.Run("aggregate and proof unsupported version", func(t *testing.T) {
+		req := &models.SignRequest{
+			PublicKey: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 1},
+			Object: &models.SignRequestAggregateAttestationAndProof{
+				Version:                  spec.DataVersionElectra + 1,
+				ElectraAggregateAndProof: &electra.AggregateAndProof{},
+			},
+		}
+
+		_, err := New().Encode(req)
+		require.EqualError(t, err, "unsupported aggregate and proof version 7")
+
+		// An electra aggregate needs the electra container
+		req.Object = &models.SignRequestAggregateAttestationAndProof{
+			Version:                      spec.DataVersionElectra,
+			AggregateAttestationAndProof: &phase0.AggregateAndProof{},
+		}
+		_, err = New().Encode(req)
+		require.EqualError(t, err, "no electra aggregate and proof")
+	})
 	t.Run("slot", func(t *testing.T) {
 		req := &models.SignRequest{
 			PublicKey:       []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 1},
@@ -403,6 +581,28 @@ func TestV2(t *testing.T) {
 		require.EqualValues(t, 12, decoded.GetContributionAndProof().Contribution.SubcommitteeIndex)
 		require.EqualValues(t, phase0.Root{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1, 1}, decoded.GetContributionAndProof().Contribution.BeaconBlockRoot)
 	})
+	t.Run("bls to execution change", func(t *testing.T) {
+		change := &capella.BLSToExecutionChange{
+			ValidatorIndex:     phase0.ValidatorIndex(1),
+			FromBLSPubkey:      phase0.BLSPubKey{1, 2, 3},
+			ToExecutionAddress: bellatrix.ExecutionAddress{4, 5, 6},
+		}
+		req := &models.SignRequest{
+			PublicKey:       change.FromBLSPubkey[:],
+			SigningRoot:     make([]byte, 32),
+			SignatureDomain: [32]byte{0x0a},
+			Object:          &models.SignRequestBLSToExecutionChange{BLSToExecutionChange: change},
+		}
+
+		enc := New()
+		byts, err := enc.Encode(req)
+		require.NoError(t, err)
+
+		decoded := &models.SignRequest{}
+		require.NoError(t, enc.Decode(byts, decoded))
+		require.EqualValues(t, change, decoded.GetBLSToExecutionChange())
+	})
+
 	t.Run("validator registration V1", func(t *testing.T) {
 		validatorRegistration := &eth2apiv1.ValidatorRegistration{}
 		jsonData := []byte(`{"fee_recipient":"0x000102030405060708090a0b0c0d0e0f10111213","gas_limit":"100","timestamp":"100","pubkey":"0x000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e2f"}`)